@@ -2,7 +2,10 @@
 package api
 
 import (
+	"context"
+
 	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
 )
 
 // AccountAPI provides account and balance management operations
@@ -24,26 +27,17 @@ func NewAccountAPI(config ConfigProvider) *AccountAPI {
 //
 // Returns: Account balance information
 func (a *AccountAPI) GetUserAccount(uid int64, symbol string) (*types.AccountResult, error) {
+	return a.GetUserAccountContext(context.Background(), uid, symbol)
+}
+
+// GetUserAccountContext is like GetUserAccount but carries ctx through to the HTTP request.
+func (a *AccountAPI) GetUserAccountContext(ctx context.Context, uid int64, symbol string) (*types.AccountResult, error) {
 	params := map[string]interface{}{
 		"uid":    uid,
 		"symbol": symbol,
 	}
 
-	response, err := a.Post("/account/getByUidAndSymbol", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = a.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.AccountResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.AccountResult](ctx, a.caller, epGetUserAccount, params)
 }
 
 // GetUserAddress gets user deposit address for a specific cryptocurrency
@@ -53,26 +47,17 @@ func (a *AccountAPI) GetUserAccount(uid int64, symbol string) (*types.AccountRes
 //
 // Returns: Deposit address information
 func (a *AccountAPI) GetUserAddress(uid int64, symbol string) (*types.UserAddressResult, error) {
+	return a.GetUserAddressContext(context.Background(), uid, symbol)
+}
+
+// GetUserAddressContext is like GetUserAddress but carries ctx through to the HTTP request.
+func (a *AccountAPI) GetUserAddressContext(ctx context.Context, uid int64, symbol string) (*types.UserAddressResult, error) {
 	params := map[string]interface{}{
 		"uid":    uid,
 		"symbol": symbol,
 	}
 
-	response, err := a.Post("/account/getDepositAddress", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = a.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.UserAddressResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.UserAddressResult](ctx, a.caller, epGetUserAddress, params)
 }
 
 // GetCompanyAccount gets company (merchant) account balance for a specific cryptocurrency
@@ -81,25 +66,16 @@ func (a *AccountAPI) GetUserAddress(uid int64, symbol string) (*types.UserAddres
 //
 // Returns: Company account information
 func (a *AccountAPI) GetCompanyAccount(symbol string) (*types.CompanyAccountResult, error) {
+	return a.GetCompanyAccountContext(context.Background(), symbol)
+}
+
+// GetCompanyAccountContext is like GetCompanyAccount but carries ctx through to the HTTP request.
+func (a *AccountAPI) GetCompanyAccountContext(ctx context.Context, symbol string) (*types.CompanyAccountResult, error) {
 	params := map[string]interface{}{
 		"symbol": symbol,
 	}
 
-	response, err := a.Post("/account/getCompanyBySymbol", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = a.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.CompanyAccountResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.CompanyAccountResult](ctx, a.caller, epGetCompanyAccount, params)
 }
 
 // GetUserAddressInfo gets user address information by address
@@ -108,25 +84,16 @@ func (a *AccountAPI) GetCompanyAccount(symbol string) (*types.CompanyAccountResu
 //
 // Returns: Address details
 func (a *AccountAPI) GetUserAddressInfo(address string) (*types.UserAddressResult, error) {
+	return a.GetUserAddressInfoContext(context.Background(), address)
+}
+
+// GetUserAddressInfoContext is like GetUserAddressInfo but carries ctx through to the HTTP request.
+func (a *AccountAPI) GetUserAddressInfoContext(ctx context.Context, address string) (*types.UserAddressResult, error) {
 	params := map[string]interface{}{
 		"address": address,
 	}
 
-	response, err := a.Post("/account/getDepositAddressInfo", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = a.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.UserAddressResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.UserAddressResult](ctx, a.caller, epGetUserAddressInfo, params)
 }
 
 // SyncUserAddressList syncs user address list by max ID (pagination)
@@ -135,23 +102,14 @@ func (a *AccountAPI) GetUserAddressInfo(address string) (*types.UserAddressResul
 //
 // Returns: Synced user address list with id, uid, address, symbol
 func (a *AccountAPI) SyncUserAddressList(maxID int64) (*types.UserAddressListResult, error) {
+	return a.SyncUserAddressListContext(context.Background(), maxID)
+}
+
+// SyncUserAddressListContext is like SyncUserAddressList but carries ctx through to the HTTP request.
+func (a *AccountAPI) SyncUserAddressListContext(ctx context.Context, maxID int64) (*types.UserAddressListResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := a.Post("/address/syncList", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = a.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.UserAddressListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.UserAddressListResult](ctx, a.caller, epSyncUserAddressList, params)
 }
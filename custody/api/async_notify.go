@@ -2,8 +2,10 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 
 	"chainup.com/go-sdk/custody/types"
 )
@@ -40,9 +42,7 @@ func (a *AsyncNotifyAPI) NotifyRequest(cipher string) (*types.AsyncNotifyArgs, e
 		return nil, err
 	}
 
-	if a.debug {
-		println("[AsyncNotify VerifyRequest Decrypted]:", decrypted)
-	}
+	a.logBody("waas notification decrypted", decrypted)
 
 	if decrypted == "" {
 		return nil, errors.New("VerifyRequest: decode cipher returned empty")
@@ -77,9 +77,7 @@ func (a *AsyncNotifyAPI) VerifyRequest(cipher string) (*WithdrawArgs, error) {
 		return nil, err
 	}
 
-	if a.debug {
-		println("[AsyncNotify VerifyRequest Decrypted]:", decrypted)
-	}
+	a.logBody("waas notification decrypted", decrypted)
 
 	if decrypted == "" {
 		return nil, errors.New("VerifyRequest: decode cipher returned empty")
@@ -113,9 +111,7 @@ func (a *AsyncNotifyAPI) VerifyResponse(args *WithdrawArgs) (string, error) {
 		return "", err
 	}
 
-	if a.debug {
-		println("[AsyncNotify VerifyResponse JSON]:", string(jsonData))
-	}
+	a.logBody("waas notification response", string(jsonData))
 
 	// Encrypt with private key
 	encrypted, err := a.cryptoProvider.EncryptWithPrivateKey(string(jsonData))
@@ -125,3 +121,12 @@ func (a *AsyncNotifyAPI) VerifyResponse(args *WithdrawArgs) (string, error) {
 
 	return encrypted, nil
 }
+
+// logBody logs a notification body with secrets masked, at debug level. The
+// body is only redacted when debug logging is enabled.
+func (a *AsyncNotifyAPI) logBody(msg, body string) {
+	ctx := context.Background()
+	if a.logger.Enabled(ctx, slog.LevelDebug) {
+		a.logger.DebugContext(ctx, msg, "body", a.redactor.RedactJSON(body))
+	}
+}
@@ -2,12 +2,16 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"chainup.com/go-sdk/utils"
+	"chainup.com/go-sdk/utils/sdkerrors"
 )
 
 // ConfigProvider defines the interface for accessing configuration
@@ -18,6 +22,20 @@ type ConfigProvider interface {
 	GetDebug() bool
 	GetTimeout() int
 	GetCryptoProvider() utils.CryptoProvider
+	GetRetryPolicy() *utils.RetryPolicy
+	GetHTTPClient() *http.Client
+	GetTransport() http.RoundTripper
+	GetMiddlewares() []utils.Middleware
+	GetLogger() *slog.Logger
+	GetRedactor() *utils.Redactor
+	GetCassette() *utils.Cassette
+	GetMetrics() utils.Collector
+	GetTracer() utils.Tracer
+	GetCircuitBreaker() *utils.CircuitBreaker
+	GetFailoverHosts() []string
+	GetRateLimiter() *utils.RateLimiter
+	GetAllowUnverifiedResponses() bool
+	GetLookupBatch() utils.BatchSettings
 }
 
 // BaseAPI provides common functionality for all WaaS API implementations
@@ -25,9 +43,17 @@ type BaseAPI struct {
 	host           string
 	appID          string
 	charset        string
-	debug          bool
 	httpClient     *utils.HTTPClient
 	cryptoProvider utils.CryptoProvider
+	logger         *slog.Logger
+	redactor       *utils.Redactor
+	tracer         utils.Tracer
+	metrics        utils.Collector
+	cassette       *utils.Cassette
+	// strict rejects success responses not decrypted with the public key.
+	strict bool
+	// caller executes the typed endpoint calls.
+	caller *utils.Caller
 }
 
 // WaaS API version prefix
@@ -36,14 +62,60 @@ const waasAPIPrefix = "/api/v2"
 // NewBaseAPI creates a new BaseAPI instance
 func NewBaseAPI(config ConfigProvider) *BaseAPI {
 	baseURL := config.GetHost() + waasAPIPrefix
-	return &BaseAPI{
+	logger := utils.ResolveLogger(config.GetLogger(), config.GetDebug())
+	redactor := config.GetRedactor()
+	if redactor == nil {
+		redactor = utils.NewRedactor()
+	}
+	httpClient := utils.NewHTTPClient(baseURL, config.GetTimeout(), config.GetDebug(),
+		utils.WithRetryPolicy(config.GetRetryPolicy()),
+		utils.WithHTTPClient(config.GetHTTPClient()),
+		utils.WithTransport(transportOf(config)),
+		utils.WithMiddleware(config.GetMiddlewares()...),
+		utils.WithLogger(logger),
+		utils.WithRedactor(redactor),
+		utils.WithRateLimiter(config.GetRateLimiter()),
+		utils.WithCircuitBreaker(config.GetCircuitBreaker()),
+		utils.WithFailoverHosts(failoverURLs(config.GetFailoverHosts())...),
+	)
+	b := &BaseAPI{
 		host:           baseURL,
 		appID:          config.GetAppID(),
 		charset:        config.GetCharset(),
-		debug:          config.GetDebug(),
-		httpClient:     utils.NewHTTPClient(baseURL, config.GetTimeout(), config.GetDebug()),
+		httpClient:     httpClient,
 		cryptoProvider: config.GetCryptoProvider(),
+		logger:         logger,
+		redactor:       redactor,
+		tracer:         config.GetTracer(),
+		metrics:        config.GetMetrics(),
+		cassette:       config.GetCassette(),
+		strict:         !config.GetAllowUnverifiedResponses(),
+	}
+	b.caller = &utils.Caller{Invoke: b.invoke, StartSpan: b.startSpan, Tracer: b.tracer, Batch: config.GetLookupBatch()}
+	return b
+}
+
+// transportOf returns the transport of config, or the shared default transport
+// when config sets neither a Transport nor an HTTPClient.
+func transportOf(config ConfigProvider) http.RoundTripper {
+	if transport := config.GetTransport(); transport != nil || config.GetHTTPClient() != nil {
+		return transport
 	}
+	return utils.SharedTransport()
+}
+
+// failoverURLs returns the API base URLs of the failover hosts.
+func failoverURLs(hosts []string) []string {
+	urls := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		urls = append(urls, host+waasAPIPrefix)
+	}
+	return urls
+}
+
+// HostStats returns the health of every host the API sends requests to.
+func (b *BaseAPI) HostStats() []utils.HostStats {
+	return b.httpClient.HostStats()
 }
 
 // buildRequestArgs builds the request args JSON with common parameters
@@ -65,34 +137,110 @@ func (b *BaseAPI) buildRequestArgs(data map[string]interface{}) (string, error)
 	return string(jsonBytes), nil
 }
 
-// executeRequest executes an API request with signing and encryption
-func (b *BaseAPI) executeRequest(method, path string, data map[string]interface{}) (map[string]interface{}, error) {
+// executeRequest executes an API request and decodes the decrypted response
+// into a map. Typed methods use utils.CallEndpoint instead, which skips the
+// map.
+func (b *BaseAPI) executeRequest(ctx context.Context, method, path string, data map[string]interface{}) (response map[string]interface{}, err error) {
+	// A raw POST may move money, so it is only retried when it carries a request_id.
+	if _, ok := utils.CallInfoFromContext(ctx); !ok && method == utils.HTTPMethodPost {
+		ctx = utils.WithIdempotencyKey(ctx, utils.StringField(data, "request_id"))
+	}
+
+	ctx, span := b.startSpan(ctx, "Request", path, data)
+	defer func() { utils.EndSpan(span, err) }()
+
+	body, err := b.invoke(ctx, method, path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	_, decodeSpan := utils.StartSpan(ctx, b.tracer, utils.SpanDecode)
+	err = utils.DecodeJSON(body, &response)
+	utils.EndSpan(decodeSpan, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted data: %w", err)
+	}
+	return response, nil
+}
+
+// startSpan starts the span of one logical API call named after the API method.
+func (b *BaseAPI) startSpan(ctx context.Context, name, path string, params map[string]interface{}) (context.Context, utils.Span) {
+	return utils.StartCallSpan(ctx, b.tracer, "waas."+name, path, b.appID, params)
+}
+
+// invoke executes an API request with signing and encryption and returns the
+// decrypted response body. The HTTP round trip is bound to ctx. Every call is
+// summarized in one log line. A non-zero response code is returned as
+// *sdkerrors.APIError carrying the endpoint and request_id of the call.
+func (b *BaseAPI) invoke(ctx context.Context, method, path string, data map[string]interface{}) ([]byte, error) {
+	start := time.Now()
+	finish := utils.TrackCall(ctx, b.metrics, "waas", path)
+	var (
+		body   []byte
+		status utils.ResponseStatus
+	)
+	err := b.httpClient.RetryRateLimited(ctx, b.appID, func() (err error) {
+		status = utils.ResponseStatus{}
+		body, err = b.cassette.Do(ctx, "waas", method, path, data, func() ([]byte, error) {
+			return b.send(ctx, method, path, data)
+		})
+		if err != nil {
+			return err
+		}
+		if decodeErr := utils.DecodeJSON(body, &status); decodeErr != nil {
+			return fmt.Errorf("invalid JSON response: %w", decodeErr)
+		}
+		if apiErr := sdkerrors.FromCode(status.Code, status.Msg); apiErr != nil {
+			apiErr.Endpoint = path
+			apiErr.RequestID = utils.StringField(data, "request_id")
+			return apiErr
+		}
+		return nil
+	})
+
+	call := utils.APICall{
+		Service:   "waas",
+		Path:      path,
+		AppID:     b.appID,
+		RequestID: utils.StringField(data, "request_id"),
+		Code:      status.CodeString(),
+		Latency:   time.Since(start),
+		Err:       err,
+	}
+	utils.LogAPICall(ctx, utils.CallLogger(ctx, b.logger), call)
+	if code := status.CodeString(); code != "" {
+		utils.SpanFromContext(ctx).SetAttribute("code", code)
+	}
+	finish(status.CodeString(), err)
+
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// send encrypts data, sends it and returns the decrypted response body.
+func (b *BaseAPI) send(ctx context.Context, method, path string, data map[string]interface{}) ([]byte, error) {
 	// Step 1: Build request args JSON
 	rawJSON, err := b.buildRequestArgs(data)
 	if err != nil {
 		return nil, err
 	}
 
-	if b.debug {
-		fmt.Printf("[WaaS Request Args]: %s\n", rawJSON)
+	if logger := utils.CallLogger(ctx, b.logger); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "waas request args", "path", path, "args", b.redactor.RedactJSON(rawJSON))
 	}
 
 	// Step 2: Encrypt with private key
 	encryptedData := ""
 	if b.cryptoProvider != nil {
+		_, span := utils.StartSpan(ctx, b.tracer, utils.SpanEncrypt)
 		encrypted, err := b.cryptoProvider.EncryptWithPrivateKey(rawJSON)
+		utils.EndSpan(span, err)
 		if err != nil {
 			return nil, fmt.Errorf("failed to encrypt request data: %w", err)
 		}
 		encryptedData = encrypted
-
-		if b.debug {
-			if len(encryptedData) > 100 {
-				fmt.Printf("[WaaS Encrypted Data]: %s...\n", encryptedData[:100])
-			} else {
-				fmt.Printf("[WaaS Encrypted Data]: %s\n", encryptedData)
-			}
-		}
 	}
 
 	// Step 3: Send request with only app_id and data
@@ -101,101 +249,93 @@ func (b *BaseAPI) executeRequest(method, path string, data map[string]interface{
 		"data":   encryptedData,
 	}
 
+	httpCtx, httpSpan := utils.StartSpan(ctx, b.tracer, utils.SpanHTTP)
 	var response string
 	if method == utils.HTTPMethodPost {
-		response, err = b.httpClient.Post(path, requestData)
+		response, err = b.httpClient.PostContext(httpCtx, path, requestData)
 	} else {
-		response, err = b.httpClient.Get(path, requestData)
+		response, err = b.httpClient.GetContext(httpCtx, path, requestData)
 	}
+	utils.EndSpan(httpSpan, err)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if b.debug {
-		fmt.Printf("[WaaS Response]: %s\n", response)
+	// Step 4: Decrypt the data field - the decrypted data IS the full
+	// response structure containing code, data, msg fields
+	_, decryptSpan := utils.StartSpan(ctx, b.tracer, utils.SpanDecrypt)
+	body, decrypted, err := utils.OpenEnvelope([]byte(response), b.cryptoProvider)
+	utils.EndSpan(decryptSpan, err)
+	if err != nil {
+		utils.CallLogger(ctx, b.logger).WarnContext(ctx, "waas response decryption failed", "path", path, "error", err)
 	}
-
-	// Step 4: Parse response and decrypt data if needed
-	var parsedResponse map[string]interface{}
-	if err := json.Unmarshal([]byte(response), &parsedResponse); err != nil {
-		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	if !decrypted {
+		// Plain error responses are returned as-is; unverified success
+		// responses are rejected in strict mode.
+		return body, utils.CheckAuthenticity(ctx, b.metrics, "waas", path, body, err, b.strict)
 	}
 
-	// Check if response has encrypted data field and decrypt
-	if dataField, ok := parsedResponse["data"].(string); ok && dataField != "" {
-		if b.cryptoProvider != nil {
-			decrypted, err := b.cryptoProvider.DecryptWithPublicKey(dataField)
-			if err != nil {
-				if b.debug {
-					fmt.Printf("[WaaS Decrypt Error]: %v\n", err)
-				}
-				// If decryption fails, might be an error response, return as-is
-				return parsedResponse, nil
-			}
-
-			if b.debug {
-				fmt.Printf("[WaaS Decrypted]: %s\n", decrypted)
-			}
-
-			// Parse decrypted JSON - the decrypted data IS the full response structure
-			// containing code, data, msg fields
-			var decryptedResponse map[string]interface{}
-			if err := json.Unmarshal([]byte(decrypted), &decryptedResponse); err != nil {
-				return nil, fmt.Errorf("failed to parse decrypted data: %w", err)
-			}
-
-			// Return the decrypted response directly (it contains code, data, msg)
-			return decryptedResponse, nil
-		}
+	if logger := utils.CallLogger(ctx, b.logger); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "waas response decrypted", "path", path, "body", b.redactor.RedactJSON(string(body)))
 	}
 
-	return parsedResponse, nil
+	return body, nil
 }
 
 // Post executes a POST request
 func (b *BaseAPI) Post(path string, data map[string]interface{}) (map[string]interface{}, error) {
-	return b.executeRequest(utils.HTTPMethodPost, path, data)
+	return b.executeRequest(context.Background(), utils.HTTPMethodPost, path, data)
 }
 
 // Get executes a GET request
 func (b *BaseAPI) Get(path string, data map[string]interface{}) (map[string]interface{}, error) {
-	return b.executeRequest(utils.HTTPMethodGet, path, data)
+	return b.executeRequest(context.Background(), utils.HTTPMethodGet, path, data)
 }
 
-// ValidateResponse validates response and handles errors
-func (b *BaseAPI) ValidateResponse(response map[string]interface{}) (interface{}, error) {
-	// Check for error code
-	var code interface{}
-	var ok bool
-
-	if code, ok = response["code"]; !ok {
-		return response, nil
-	}
-
-	// Convert code to int for comparison
-	var codeInt int
-	switch v := code.(type) {
-	case float64:
-		codeInt = int(v)
-	case int:
-		codeInt = v
-	case string:
-		if v == "0" {
-			codeInt = 0
-		} else {
-			return nil, fmt.Errorf("API Error [%s]: %v", v, response["msg"])
-		}
+// PostContext executes a POST request bound to ctx
+func (b *BaseAPI) PostContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return b.executeRequest(ctx, utils.HTTPMethodPost, path, data)
+}
+
+// GetContext executes a GET request bound to ctx
+func (b *BaseAPI) GetContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return b.executeRequest(ctx, utils.HTTPMethodGet, path, data)
+}
+
+// RawCall executes an endpoint the SDK does not wrap yet. path is relative to the /api/v2 prefix, e.g. "/billing/withdrawList".
+// params are encrypted and sent exactly like the built-in methods do, with the
+// same logging and error handling; a non-zero response code is returned as
+// *sdkerrors.APIError. POST calls are only retried when params carry a request_id.
+func (b *BaseAPI) RawCall(ctx context.Context, method, path string, params map[string]interface{}) (*utils.RawResponse, error) {
+	switch method {
+	case utils.HTTPMethodGet:
+	case utils.HTTPMethodPost:
+		ctx = utils.WithIdempotencyKey(ctx, utils.StringField(params, "request_id"))
 	default:
-		codeInt = -1
+		return nil, fmt.Errorf("unsupported HTTP method %q", method)
 	}
 
-	if codeInt != utils.ResponseCodeSuccess {
-		msg := "Unknown error"
-		if msgField, ok := response["msg"]; ok {
-			msg = fmt.Sprintf("%v", msgField)
-		}
-		return nil, fmt.Errorf("API Error [%d]: %s", codeInt, msg)
+	// The request args are extended with common parameters, so work on a copy.
+	data := make(map[string]interface{}, len(params)+2)
+	for key, value := range params {
+		data[key] = value
+	}
+
+	ctx, span := b.startSpan(ctx, "RawCall", path, data)
+	body, err := b.invoke(ctx, method, path, data)
+	utils.EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.RawResponse{Body: body}, nil
+}
+
+// ValidateResponse validates response and handles errors.
+// A non-zero response code is reported as *sdkerrors.APIError.
+func (b *BaseAPI) ValidateResponse(response map[string]interface{}) (interface{}, error) {
+	if apiErr := sdkerrors.FromResponse(response); apiErr != nil {
+		return nil, apiErr
 	}
 
 	// Return data field if exists, otherwise return whole response
@@ -206,23 +346,13 @@ func (b *BaseAPI) ValidateResponse(response map[string]interface{}) (interface{}
 	return response, nil
 }
 
-// ResponseError represents an API error response
-type ResponseError struct {
-	Code    int
-	Message string
-}
-
-// Error implements the error interface
-func (e *ResponseError) Error() string {
-	return fmt.Sprintf("API Error [%d]: %s", e.Code, e.Message)
-}
+// ResponseError represents an API error response.
+// It is an alias of sdkerrors.APIError shared by the WaaS and MPC clients.
+type ResponseError = sdkerrors.APIError
 
 // NewResponseError creates a new ResponseError
 func NewResponseError(code int, message string) *ResponseError {
-	return &ResponseError{
-		Code:    code,
-		Message: message,
-	}
+	return sdkerrors.NewAPIError(code, message)
 }
 
 // IsResponseError checks if an error is a ResponseError
@@ -2,10 +2,11 @@
 package api
 
 import (
-	"fmt"
-	"strings"
+	"context"
+	"errors"
 
 	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
 	"github.com/shopspring/decimal"
 )
 
@@ -37,6 +38,11 @@ type WithdrawArgs struct {
 //
 // Returns: Withdrawal result
 func (b *BillingAPI) Withdraw(args *WithdrawArgs) (*types.WithdrawResult, error) {
+	return b.WithdrawContext(context.Background(), args)
+}
+
+// WithdrawContext is like Withdraw but carries ctx through to the HTTP request.
+func (b *BillingAPI) WithdrawContext(ctx context.Context, args *WithdrawArgs) (*types.WithdrawResult, error) {
 	params := map[string]interface{}{
 		"request_id": args.RequestID,
 		"from_uid":   args.FromUID,
@@ -45,48 +51,32 @@ func (b *BillingAPI) Withdraw(args *WithdrawArgs) (*types.WithdrawResult, error)
 		"symbol":     args.Symbol,
 	}
 
-	response, err := b.Post("/billing/withdraw", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = b.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.WithdrawResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.WithdrawResult](ctx, b.caller, epWithdraw, params)
 }
 
 // WithdrawList gets withdrawal records by request IDs
 // Parameters:
 //   - requestIDs: List of request IDs
 //
-// Returns: Withdrawal records
+// Returns: Withdrawal records; request IDs without a record are listed in NotFound
+//
+// Long lists are split into parallel requests, see Config.LookupBatch.
 func (b *BillingAPI) WithdrawList(requestIDs []string) (*types.WithdrawListResult, error) {
-	params := map[string]interface{}{
-		"ids": strings.Join(requestIDs, ","),
-	}
-
-	response, err := b.Post("/billing/withdrawList", params)
-	if err != nil {
-		return nil, err
-	}
+	return b.WithdrawListContext(context.Background(), requestIDs)
+}
 
-	_, err = b.ValidateResponse(response)
-	if err != nil {
-		return nil, err
+// WithdrawListContext is like WithdrawList but carries ctx through to the HTTP request.
+func (b *BillingAPI) WithdrawListContext(ctx context.Context, requestIDs []string) (*types.WithdrawListResult, error) {
+	if len(requestIDs) == 0 {
+		return nil, errors.New("parameter \"request_ids\" is required and must be a non-empty array")
 	}
-
-	var result types.WithdrawListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
+	result, notFound, err := utils.LookupByIDs(ctx, b.caller, epWithdrawList, requestIDs,
+		func(r *types.WithdrawListResult) *[]*types.Withdraw { return &r.Data },
+		func(w *types.Withdraw) string { return w.RequestID })
+	if result != nil {
+		result.NotFound = notFound
 	}
-	return &result, nil
+	return result, err
 }
 
 // SyncWithdrawList syncs withdrawal records by max ID (pagination)
@@ -95,57 +85,40 @@ func (b *BillingAPI) WithdrawList(requestIDs []string) (*types.WithdrawListResul
 //
 // Returns: Synced withdrawal records
 func (b *BillingAPI) SyncWithdrawList(maxID int64) (*types.WithdrawListResult, error) {
+	return b.SyncWithdrawListContext(context.Background(), maxID)
+}
+
+// SyncWithdrawListContext is like SyncWithdrawList but carries ctx through to the HTTP request.
+func (b *BillingAPI) SyncWithdrawListContext(ctx context.Context, maxID int64) (*types.WithdrawListResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := b.Post("/billing/syncWithdrawList", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = b.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.WithdrawListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.WithdrawListResult](ctx, b.caller, epSyncWithdrawList, params)
 }
 
 // DepositList gets deposit records by WaaS IDs
 // Parameters:
 //   - ids: List of WaaS deposit IDs
 //
-// Returns: Deposit records
+// Returns: Deposit records; IDs without a record are listed in NotFound
+//
+// Long lists are split into parallel requests, see Config.LookupBatch.
 func (b *BillingAPI) DepositList(ids []int64) (*types.DepositListResult, error) {
-	var strIDs []string
-	for _, id := range ids {
-		strIDs = append(strIDs, fmt.Sprintf("%d", id))
-	}
-
-	params := map[string]interface{}{
-		"ids": strings.Join(strIDs, ","),
-	}
-
-	response, err := b.Post("/billing/depositList", params)
-	if err != nil {
-		return nil, err
-	}
+	return b.DepositListContext(context.Background(), ids)
+}
 
-	_, err = b.ValidateResponse(response)
-	if err != nil {
-		return nil, err
+// DepositListContext is like DepositList but carries ctx through to the HTTP request.
+func (b *BillingAPI) DepositListContext(ctx context.Context, ids []int64) (*types.DepositListResult, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("parameter \"ids\" is required and must be a non-empty array")
 	}
-
-	var result types.DepositListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
+	result, notFound, err := utils.LookupByIDs(ctx, b.caller, epDepositList, ids,
+		func(r *types.DepositListResult) *[]*types.Deposit { return &r.Data }, depositID)
+	if result != nil {
+		result.NotFound = notFound
 	}
-	return &result, nil
+	return result, err
 }
 
 // SyncDepositList syncs deposit records by max ID (pagination)
@@ -154,57 +127,40 @@ func (b *BillingAPI) DepositList(ids []int64) (*types.DepositListResult, error)
 //
 // Returns: Synced deposit records
 func (b *BillingAPI) SyncDepositList(maxID int64) (*types.DepositListResult, error) {
+	return b.SyncDepositListContext(context.Background(), maxID)
+}
+
+// SyncDepositListContext is like SyncDepositList but carries ctx through to the HTTP request.
+func (b *BillingAPI) SyncDepositListContext(ctx context.Context, maxID int64) (*types.DepositListResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := b.Post("/billing/syncDepositList", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = b.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.DepositListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.DepositListResult](ctx, b.caller, epSyncDepositList, params)
 }
 
 // MinerFeeList gets miner fee records by WaaS IDs
 // Parameters:
 //   - ids: List of WaaS transaction IDs
 //
-// Returns: Miner fee records
+// Returns: Miner fee records; IDs without a record are listed in NotFound
+//
+// Long lists are split into parallel requests, see Config.LookupBatch.
 func (b *BillingAPI) MinerFeeList(ids []int64) (*types.MinerFeeListResult, error) {
-	var strIDs []string
-	for _, id := range ids {
-		strIDs = append(strIDs, fmt.Sprintf("%d", id))
-	}
-
-	params := map[string]interface{}{
-		"ids": strings.Join(strIDs, ","),
-	}
-
-	response, err := b.Post("/billing/minerFeeList", params)
-	if err != nil {
-		return nil, err
-	}
+	return b.MinerFeeListContext(context.Background(), ids)
+}
 
-	_, err = b.ValidateResponse(response)
-	if err != nil {
-		return nil, err
+// MinerFeeListContext is like MinerFeeList but carries ctx through to the HTTP request.
+func (b *BillingAPI) MinerFeeListContext(ctx context.Context, ids []int64) (*types.MinerFeeListResult, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("parameter \"ids\" is required and must be a non-empty array")
 	}
-
-	var result types.MinerFeeListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
+	result, notFound, err := utils.LookupByIDs(ctx, b.caller, epMinerFeeList, ids,
+		func(r *types.MinerFeeListResult) *[]*types.MinerFee { return &r.Data }, minerFeeID)
+	if result != nil {
+		result.NotFound = notFound
 	}
-	return &result, nil
+	return result, err
 }
 
 // SyncMinerFeeList syncs miner fee records by max ID (pagination)
@@ -213,23 +169,14 @@ func (b *BillingAPI) MinerFeeList(ids []int64) (*types.MinerFeeListResult, error
 //
 // Returns: Synced miner fee records
 func (b *BillingAPI) SyncMinerFeeList(maxID int64) (*types.MinerFeeListResult, error) {
+	return b.SyncMinerFeeListContext(context.Background(), maxID)
+}
+
+// SyncMinerFeeListContext is like SyncMinerFeeList but carries ctx through to the HTTP request.
+func (b *BillingAPI) SyncMinerFeeListContext(ctx context.Context, maxID int64) (*types.MinerFeeListResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := b.Post("/billing/syncMinerFeeList", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = b.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.MinerFeeListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.MinerFeeListResult](ctx, b.caller, epSyncMinerFeeList, params)
 }
@@ -2,7 +2,10 @@
 package api
 
 import (
+	"context"
+
 	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
 )
 
 // CoinAPI provides coin and blockchain information operations
@@ -20,21 +23,12 @@ func NewCoinAPI(config ConfigProvider) *CoinAPI {
 // GetCoinList gets the list of supported cryptocurrencies
 // Returns: List of coin information
 func (c *CoinAPI) GetCoinList() (*types.CoinInfoListResult, error) {
-	params := make(map[string]interface{})
-
-	response, err := c.Post("/user/getCoinList", params)
-	if err != nil {
-		return nil, err
-	}
+	return c.GetCoinListContext(context.Background())
+}
 
-	_, err = c.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
+// GetCoinListContext is like GetCoinList but carries ctx through to the HTTP request.
+func (c *CoinAPI) GetCoinListContext(ctx context.Context) (*types.CoinInfoListResult, error) {
+	params := make(map[string]interface{})
 
-	var result types.CoinInfoListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.CoinInfoListResult](ctx, c.caller, epGetCoinList, params)
 }
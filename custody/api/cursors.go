@@ -0,0 +1,65 @@
+// Package api provides API implementations for WaaS operations
+package api
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// NewUserCursor iterates over all users with SyncUserList, in UID order.
+func NewUserCursor(ctx context.Context, users UserService, opts ...utils.CursorOption) *utils.Cursor[*types.UserInfo] {
+	fetch := utils.PageOf(users.SyncUserListContext, func(r *types.UserListResult) []*types.UserInfo { return r.Data })
+	return utils.NewCursor(ctx, fetch, func(u *types.UserInfo) int64 { return u.UID.Int64() }, opts...)
+}
+
+// NewUserAddressCursor iterates over all user addresses with SyncUserAddressList.
+func NewUserAddressCursor(ctx context.Context, accounts AccountService, opts ...utils.CursorOption) *utils.Cursor[*types.UserAddress] {
+	fetch := utils.PageOf(accounts.SyncUserAddressListContext, func(r *types.UserAddressListResult) []*types.UserAddress { return r.Data })
+	return utils.NewCursor(ctx, fetch, func(a *types.UserAddress) int64 { return a.Id.Int64() }, opts...)
+}
+
+// NewWithdrawCursor iterates over all withdrawals with SyncWithdrawList.
+func NewWithdrawCursor(ctx context.Context, billing BillingService, opts ...utils.CursorOption) *utils.Cursor[*types.Withdraw] {
+	return utils.NewCursor(ctx, withdrawPages(billing), withdrawID, opts...)
+}
+
+// NewDepositCursor iterates over all deposits with SyncDepositList.
+func NewDepositCursor(ctx context.Context, billing BillingService, opts ...utils.CursorOption) *utils.Cursor[*types.Deposit] {
+	return utils.NewCursor(ctx, depositPages(billing), depositID, opts...)
+}
+
+// NewMinerFeeCursor iterates over all miner fee records with SyncMinerFeeList.
+func NewMinerFeeCursor(ctx context.Context, billing BillingService, opts ...utils.CursorOption) *utils.Cursor[*types.MinerFee] {
+	return utils.NewCursor(ctx, minerFeePages(billing), minerFeeID, opts...)
+}
+
+// NewTransferCursor iterates over all account transfers with SyncAccountTransferList.
+func NewTransferCursor(ctx context.Context, transfers TransferService, opts ...utils.CursorOption) *utils.Cursor[*types.Transfer] {
+	return utils.NewCursor(ctx, transferPages(transfers), transferID, opts...)
+}
+
+func withdrawPages(billing BillingService) utils.PageFunc[*types.Withdraw] {
+	return utils.PageOf(billing.SyncWithdrawListContext, func(r *types.WithdrawListResult) []*types.Withdraw { return r.Data })
+}
+
+func withdrawID(w *types.Withdraw) int64 { return w.Id.Int64() }
+
+func depositPages(billing BillingService) utils.PageFunc[*types.Deposit] {
+	return utils.PageOf(billing.SyncDepositListContext, func(r *types.DepositListResult) []*types.Deposit { return r.Data })
+}
+
+func depositID(d *types.Deposit) int64 { return int64(d.ID) }
+
+func minerFeePages(billing BillingService) utils.PageFunc[*types.MinerFee] {
+	return utils.PageOf(billing.SyncMinerFeeListContext, func(r *types.MinerFeeListResult) []*types.MinerFee { return r.Data })
+}
+
+func minerFeeID(f *types.MinerFee) int64 { return int64(f.ID) }
+
+func transferPages(transfers TransferService) utils.PageFunc[*types.Transfer] {
+	return utils.PageOf(transfers.SyncAccountTransferListContext, func(r *types.TransferListResult) []*types.Transfer { return r.Data })
+}
+
+func transferID(t *types.Transfer) int64 { return t.ID }
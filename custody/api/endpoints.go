@@ -0,0 +1,134 @@
+// Package api provides API implementations for WaaS operations
+package api
+
+import (
+	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// WaaS endpoint descriptors. Every typed API method executes through one of
+// these entries; see Endpoints for the full table.
+var (
+	epRegisterMobileUser = &utils.Endpoint{
+		Name: "UserAPI.RegisterMobileUser", Method: utils.HTTPMethodPost, Path: "/user/createUser", Group: utils.GroupMetadata,
+		Required: []string{"country", "mobile"}, NonIdempotent: true,
+		Result: utils.ResultType[types.UserInfoResult](),
+	}
+	epRegisterEmailUser = &utils.Endpoint{
+		Name: "UserAPI.RegisterEmailUser", Method: utils.HTTPMethodPost, Path: "/user/registerEmail", Group: utils.GroupMetadata,
+		Required: []string{"email"}, NonIdempotent: true,
+		Result: utils.ResultType[types.UserInfoResult](),
+	}
+	epGetMobileUser = &utils.Endpoint{
+		Name: "UserAPI.GetMobileUser", Method: utils.HTTPMethodPost, Path: "/user/info", Group: utils.GroupMetadata,
+		Required: []string{"country", "mobile"},
+		Result:   utils.ResultType[types.UserInfoResult](),
+	}
+	epGetEmailUser = &utils.Endpoint{
+		Name: "UserAPI.GetEmailUser", Method: utils.HTTPMethodPost, Path: "/user/info", Group: utils.GroupMetadata,
+		Required: []string{"email"},
+		Result:   utils.ResultType[types.UserInfoResult](),
+	}
+	epSyncUserList = &utils.Endpoint{
+		Name: "UserAPI.SyncUserList", Method: utils.HTTPMethodPost, Path: "/user/syncList", Group: utils.GroupSync,
+		Result: utils.ResultType[types.UserListResult](),
+	}
+
+	epGetUserAccount = &utils.Endpoint{
+		Name: "AccountAPI.GetUserAccount", Method: utils.HTTPMethodPost, Path: "/account/getByUidAndSymbol", Group: utils.GroupMetadata,
+		Required: []string{"uid", "symbol"},
+		Result:   utils.ResultType[types.AccountResult](),
+	}
+	epGetUserAddress = &utils.Endpoint{
+		Name: "AccountAPI.GetUserAddress", Method: utils.HTTPMethodPost, Path: "/account/getDepositAddress", Group: utils.GroupMetadata,
+		Required: []string{"uid", "symbol"},
+		Result:   utils.ResultType[types.UserAddressResult](),
+	}
+	epGetCompanyAccount = &utils.Endpoint{
+		Name: "AccountAPI.GetCompanyAccount", Method: utils.HTTPMethodPost, Path: "/account/getCompanyBySymbol", Group: utils.GroupMetadata,
+		Required: []string{"symbol"},
+		Result:   utils.ResultType[types.CompanyAccountResult](),
+	}
+	epGetUserAddressInfo = &utils.Endpoint{
+		Name: "AccountAPI.GetUserAddressInfo", Method: utils.HTTPMethodPost, Path: "/account/getDepositAddressInfo", Group: utils.GroupMetadata,
+		Required: []string{"address"},
+		Result:   utils.ResultType[types.UserAddressResult](),
+	}
+	epSyncUserAddressList = &utils.Endpoint{
+		Name: "AccountAPI.SyncUserAddressList", Method: utils.HTTPMethodPost, Path: "/address/syncList", Group: utils.GroupSync,
+		Result: utils.ResultType[types.UserAddressListResult](),
+	}
+
+	epWithdraw = &utils.Endpoint{
+		Name: "BillingAPI.Withdraw", Method: utils.HTTPMethodPost, Path: "/billing/withdraw", Group: utils.GroupMoneyMoving,
+		Required:       []string{"from_uid", "to_address", "amount", "symbol"},
+		IdempotencyKey: "request_id",
+		Result:         utils.ResultType[types.WithdrawResult](),
+	}
+	epWithdrawList = &utils.Endpoint{
+		Name: "BillingAPI.WithdrawList", Method: utils.HTTPMethodPost, Path: "/billing/withdrawList", Group: utils.GroupMetadata,
+		Required: []string{"ids"},
+		Result:   utils.ResultType[types.WithdrawListResult](),
+	}
+	epSyncWithdrawList = &utils.Endpoint{
+		Name: "BillingAPI.SyncWithdrawList", Method: utils.HTTPMethodPost, Path: "/billing/syncWithdrawList", Group: utils.GroupSync,
+		Result: utils.ResultType[types.WithdrawListResult](),
+	}
+	epDepositList = &utils.Endpoint{
+		Name: "BillingAPI.DepositList", Method: utils.HTTPMethodPost, Path: "/billing/depositList", Group: utils.GroupMetadata,
+		Required: []string{"ids"},
+		Result:   utils.ResultType[types.DepositListResult](),
+	}
+	epSyncDepositList = &utils.Endpoint{
+		Name: "BillingAPI.SyncDepositList", Method: utils.HTTPMethodPost, Path: "/billing/syncDepositList", Group: utils.GroupSync,
+		Result: utils.ResultType[types.DepositListResult](),
+	}
+	epMinerFeeList = &utils.Endpoint{
+		Name: "BillingAPI.MinerFeeList", Method: utils.HTTPMethodPost, Path: "/billing/minerFeeList", Group: utils.GroupMetadata,
+		Required: []string{"ids"},
+		Result:   utils.ResultType[types.MinerFeeListResult](),
+	}
+	epSyncMinerFeeList = &utils.Endpoint{
+		Name: "BillingAPI.SyncMinerFeeList", Method: utils.HTTPMethodPost, Path: "/billing/syncMinerFeeList", Group: utils.GroupSync,
+		Result: utils.ResultType[types.MinerFeeListResult](),
+	}
+
+	epAccountTransfer = &utils.Endpoint{
+		Name: "TransferAPI.AccountTransfer", Method: utils.HTTPMethodPost, Path: "/account/transfer", Group: utils.GroupMoneyMoving,
+		Required:       []string{"from_uid", "to_uid", "symbol", "amount"},
+		IdempotencyKey: "request_id",
+		Result:         utils.ResultType[types.TransferResult](),
+	}
+	epGetAccountTransferList = &utils.Endpoint{
+		Name: "TransferAPI.GetAccountTransferList", Method: utils.HTTPMethodPost, Path: "/account/transferList", Group: utils.GroupMetadata,
+		Required: []string{"ids"},
+		Result:   utils.ResultType[types.TransferListResult](),
+	}
+	epSyncAccountTransferList = &utils.Endpoint{
+		Name: "TransferAPI.SyncAccountTransferList", Method: utils.HTTPMethodPost, Path: "/account/syncTransferList", Group: utils.GroupSync,
+		Result: utils.ResultType[types.TransferListResult](),
+	}
+
+	epGetCoinList = &utils.Endpoint{
+		Name: "CoinAPI.GetCoinList", Method: utils.HTTPMethodPost, Path: "/user/getCoinList", Group: utils.GroupMetadata,
+		Result: utils.ResultType[types.CoinInfoListResult](),
+	}
+)
+
+// endpoints lists every WaaS endpoint wrapped by the SDK.
+var endpoints = []*utils.Endpoint{
+	epRegisterMobileUser, epRegisterEmailUser, epGetMobileUser, epGetEmailUser, epSyncUserList,
+	epGetUserAccount, epGetUserAddress, epGetCompanyAccount, epGetUserAddressInfo, epSyncUserAddressList,
+	epWithdraw, epWithdrawList, epSyncWithdrawList, epDepositList, epSyncDepositList, epMinerFeeList, epSyncMinerFeeList,
+	epAccountTransfer, epGetAccountTransferList, epSyncAccountTransferList,
+	epGetCoinList,
+}
+
+// Endpoints returns a copy of the WaaS endpoint table.
+func Endpoints() []utils.Endpoint {
+	table := make([]utils.Endpoint, len(endpoints))
+	for i, ep := range endpoints {
+		table[i] = *ep
+	}
+	return table
+}
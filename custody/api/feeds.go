@@ -0,0 +1,41 @@
+// Package api provides API implementations for WaaS operations
+package api
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// Names of the feeds created by this package, which are also their checkpoint keys.
+const (
+	FeedDeposits  = "waas.deposits"
+	FeedWithdraws = "waas.withdraws"
+	FeedMinerFees = "waas.miner_fees"
+	FeedTransfers = "waas.transfers"
+)
+
+// NewDepositFeed creates the utils.Syncer feed handing every deposit of
+// SyncDepositList to handle.
+func NewDepositFeed(billing BillingService, handle func(context.Context, *types.Deposit) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedDeposits, depositPages(billing), depositID, handle, opts...)
+}
+
+// NewWithdrawFeed creates the utils.Syncer feed handing every withdrawal of
+// SyncWithdrawList to handle.
+func NewWithdrawFeed(billing BillingService, handle func(context.Context, *types.Withdraw) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedWithdraws, withdrawPages(billing), withdrawID, handle, opts...)
+}
+
+// NewMinerFeeFeed creates the utils.Syncer feed handing every miner fee record
+// of SyncMinerFeeList to handle.
+func NewMinerFeeFeed(billing BillingService, handle func(context.Context, *types.MinerFee) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedMinerFees, minerFeePages(billing), minerFeeID, handle, opts...)
+}
+
+// NewTransferFeed creates the utils.Syncer feed handing every account transfer
+// of SyncAccountTransferList to handle.
+func NewTransferFeed(transfers TransferService, handle func(context.Context, *types.Transfer) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedTransfers, transferPages(transfers), transferID, handle, opts...)
+}
@@ -0,0 +1,96 @@
+// Package api provides API implementations for WaaS operations
+package api
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// Final statuses after which a reconcile source stops looking a record up.
+var (
+	// DefaultDepositFinalStatuses are success (1) and failure (2).
+	DefaultDepositFinalStatuses = []int64{1, 2}
+
+	// DefaultWithdrawFinalStatuses are rejected (2), failed (4), success (5)
+	// and canceled (6).
+	DefaultWithdrawFinalStatuses = []int64{2, 4, 5, 6}
+)
+
+// AsyncNotifyObservation converts a decrypted notification to the observation
+// a utils.Reconciler expects. The observation kind is the notification side.
+func AsyncNotifyObservation(args *types.AsyncNotifyArgs) utils.Observation {
+	return utils.Observation{
+		Kind:      args.Side,
+		ID:        args.ID.Int64(),
+		RequestID: args.RequestID,
+		Status:    args.Status.Int64(),
+		UpdatedAt: args.UpdatedAt.Time,
+		Record:    args,
+	}
+}
+
+// NewDepositReconcileSource creates the utils.Reconciler source of kind
+// types.AsyncNotifySideDeposit, which syncs SyncDepositList and looks up open
+// deposits with DepositList. final overrides DefaultDepositFinalStatuses.
+func NewDepositReconcileSource(billing BillingService, final ...int64) utils.ReconcileSource {
+	lookup := func(ctx context.Context, open []utils.Observation) ([]*types.Deposit, error) {
+		result, err := billing.DepositListContext(ctx, observedIDs(open))
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return result.Data, nil
+	}
+	observe := func(d *types.Deposit) utils.Observation {
+		return utils.Observation{Kind: types.AsyncNotifySideDeposit, ID: depositID(d), Status: d.Status.Int64(), UpdatedAt: d.UpdatedAt.Time, Record: d}
+	}
+	if len(final) == 0 {
+		final = DefaultDepositFinalStatuses
+	}
+	return utils.NewReconcileSource(types.AsyncNotifySideDeposit, depositPages(billing), lookup, observe, final...)
+}
+
+// NewWithdrawReconcileSource creates the utils.Reconciler source of kind
+// types.AsyncNotifySideWithdraw, which syncs SyncWithdrawList and looks up
+// open withdrawals with WithdrawList. final overrides
+// DefaultWithdrawFinalStatuses.
+func NewWithdrawReconcileSource(billing BillingService, final ...int64) utils.ReconcileSource {
+	lookup := func(ctx context.Context, open []utils.Observation) ([]*types.Withdraw, error) {
+		requestIDs := observedRequestIDs(open)
+		if len(requestIDs) == 0 {
+			return nil, nil
+		}
+		result, err := billing.WithdrawListContext(ctx, requestIDs)
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return result.Data, nil
+	}
+	observe := func(w *types.Withdraw) utils.Observation {
+		return utils.Observation{Kind: types.AsyncNotifySideWithdraw, ID: withdrawID(w), RequestID: w.RequestID, Status: w.Status, UpdatedAt: w.UpdatedAt.Time, Record: w}
+	}
+	if len(final) == 0 {
+		final = DefaultWithdrawFinalStatuses
+	}
+	return utils.NewReconcileSource(types.AsyncNotifySideWithdraw, withdrawPages(billing), lookup, observe, final...)
+}
+
+func observedIDs(observations []utils.Observation) []int64 {
+	ids := make([]int64, len(observations))
+	for i, obs := range observations {
+		ids[i] = obs.ID
+	}
+	return ids
+}
+
+// observedRequestIDs returns the request IDs of the observations that have one.
+func observedRequestIDs(observations []utils.Observation) []string {
+	var requestIDs []string
+	for _, obs := range observations {
+		if obs.RequestID != "" {
+			requestIDs = append(requestIDs, obs.RequestID)
+		}
+	}
+	return requestIDs
+}
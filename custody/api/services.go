@@ -0,0 +1,90 @@
+// Package api provides API implementations for WaaS operations
+//
+// The *Service interfaces are implemented by the API facades of the client.
+// Code depending on them can be tested with the mocks of package custodymock.
+package api
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/custody/types"
+)
+
+// UserService is the interface of UserAPI, for user management.
+type UserService interface {
+	RegisterMobileUser(country, mobile string) (*types.UserInfoResult, error)
+	RegisterMobileUserContext(ctx context.Context, country, mobile string) (*types.UserInfoResult, error)
+	RegisterEmailUser(email string) (*types.UserInfoResult, error)
+	RegisterEmailUserContext(ctx context.Context, email string) (*types.UserInfoResult, error)
+	GetMobileUser(country, mobile string) (*types.UserInfoResult, error)
+	GetMobileUserContext(ctx context.Context, country, mobile string) (*types.UserInfoResult, error)
+	GetEmailUser(email string) (*types.UserInfoResult, error)
+	GetEmailUserContext(ctx context.Context, email string) (*types.UserInfoResult, error)
+	SyncUserList(maxID int64) (*types.UserListResult, error)
+	SyncUserListContext(ctx context.Context, maxID int64) (*types.UserListResult, error)
+}
+
+// AccountService is the interface of AccountAPI, for account management.
+type AccountService interface {
+	GetUserAccount(uid int64, symbol string) (*types.AccountResult, error)
+	GetUserAccountContext(ctx context.Context, uid int64, symbol string) (*types.AccountResult, error)
+	GetUserAddress(uid int64, symbol string) (*types.UserAddressResult, error)
+	GetUserAddressContext(ctx context.Context, uid int64, symbol string) (*types.UserAddressResult, error)
+	GetCompanyAccount(symbol string) (*types.CompanyAccountResult, error)
+	GetCompanyAccountContext(ctx context.Context, symbol string) (*types.CompanyAccountResult, error)
+	GetUserAddressInfo(address string) (*types.UserAddressResult, error)
+	GetUserAddressInfoContext(ctx context.Context, address string) (*types.UserAddressResult, error)
+	SyncUserAddressList(maxID int64) (*types.UserAddressListResult, error)
+	SyncUserAddressListContext(ctx context.Context, maxID int64) (*types.UserAddressListResult, error)
+}
+
+// BillingService is the interface of BillingAPI, for billing operations.
+type BillingService interface {
+	Withdraw(args *WithdrawArgs) (*types.WithdrawResult, error)
+	WithdrawContext(ctx context.Context, args *WithdrawArgs) (*types.WithdrawResult, error)
+	WithdrawList(requestIDs []string) (*types.WithdrawListResult, error)
+	WithdrawListContext(ctx context.Context, requestIDs []string) (*types.WithdrawListResult, error)
+	SyncWithdrawList(maxID int64) (*types.WithdrawListResult, error)
+	SyncWithdrawListContext(ctx context.Context, maxID int64) (*types.WithdrawListResult, error)
+	DepositList(ids []int64) (*types.DepositListResult, error)
+	DepositListContext(ctx context.Context, ids []int64) (*types.DepositListResult, error)
+	SyncDepositList(maxID int64) (*types.DepositListResult, error)
+	SyncDepositListContext(ctx context.Context, maxID int64) (*types.DepositListResult, error)
+	MinerFeeList(ids []int64) (*types.MinerFeeListResult, error)
+	MinerFeeListContext(ctx context.Context, ids []int64) (*types.MinerFeeListResult, error)
+	SyncMinerFeeList(maxID int64) (*types.MinerFeeListResult, error)
+	SyncMinerFeeListContext(ctx context.Context, maxID int64) (*types.MinerFeeListResult, error)
+}
+
+// CoinService is the interface of CoinAPI, for coin information.
+type CoinService interface {
+	GetCoinList() (*types.CoinInfoListResult, error)
+	GetCoinListContext(ctx context.Context) (*types.CoinInfoListResult, error)
+}
+
+// TransferService is the interface of TransferAPI, for transfer operations.
+type TransferService interface {
+	AccountTransfer(args *TransferArgs) (*types.TransferResult, error)
+	AccountTransferContext(ctx context.Context, args *TransferArgs) (*types.TransferResult, error)
+	GetAccountTransferList(requestIDs []string) (*types.TransferListResult, error)
+	GetAccountTransferListContext(ctx context.Context, requestIDs []string) (*types.TransferListResult, error)
+	SyncAccountTransferList(maxID int64) (*types.TransferListResult, error)
+	SyncAccountTransferListContext(ctx context.Context, maxID int64) (*types.TransferListResult, error)
+}
+
+// AsyncNotifyService is the interface of AsyncNotifyAPI, for notification handling.
+type AsyncNotifyService interface {
+	NotifyRequest(cipher string) (*types.AsyncNotifyArgs, error)
+	VerifyRequest(cipher string) (*WithdrawArgs, error)
+	VerifyResponse(args *WithdrawArgs) (string, error)
+}
+
+// Compile-time checks that the facades implement their interfaces.
+var (
+	_ UserService        = (*UserAPI)(nil)
+	_ AccountService     = (*AccountAPI)(nil)
+	_ BillingService     = (*BillingAPI)(nil)
+	_ CoinService        = (*CoinAPI)(nil)
+	_ TransferService    = (*TransferAPI)(nil)
+	_ AsyncNotifyService = (*AsyncNotifyAPI)(nil)
+)
@@ -2,9 +2,11 @@
 package api
 
 import (
-	"strings"
+	"context"
+	"errors"
 
 	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
 	"github.com/shopspring/decimal"
 )
 
@@ -36,6 +38,11 @@ type TransferArgs struct {
 //
 // Returns: Transfer result
 func (t *TransferAPI) AccountTransfer(args *TransferArgs) (*types.TransferResult, error) {
+	return t.AccountTransferContext(context.Background(), args)
+}
+
+// AccountTransferContext is like AccountTransfer but carries ctx through to the HTTP request.
+func (t *TransferAPI) AccountTransferContext(ctx context.Context, args *TransferArgs) (*types.TransferResult, error) {
 	params := map[string]interface{}{
 		"request_id": args.RequestID,
 		"from_uid":   args.FromUID,
@@ -48,48 +55,32 @@ func (t *TransferAPI) AccountTransfer(args *TransferArgs) (*types.TransferResult
 		params["remark"] = args.Remark
 	}
 
-	response, err := t.Post("/account/transfer", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = t.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.TransferResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.TransferResult](ctx, t.caller, epAccountTransfer, params)
 }
 
 // GetAccountTransferList gets account transfer list by request IDs
 // Parameters:
 //   - requestIDs: List of request IDs
 //
-// Returns: Transfer records
+// Returns: Transfer records; request IDs without a record are listed in NotFound
+//
+// Long lists are split into parallel requests, see Config.LookupBatch.
 func (t *TransferAPI) GetAccountTransferList(requestIDs []string) (*types.TransferListResult, error) {
-	params := map[string]interface{}{
-		"ids": strings.Join(requestIDs, ","),
-	}
-
-	response, err := t.Post("/account/transferList", params)
-	if err != nil {
-		return nil, err
-	}
+	return t.GetAccountTransferListContext(context.Background(), requestIDs)
+}
 
-	_, err = t.ValidateResponse(response)
-	if err != nil {
-		return nil, err
+// GetAccountTransferListContext is like GetAccountTransferList but carries ctx through to the HTTP request.
+func (t *TransferAPI) GetAccountTransferListContext(ctx context.Context, requestIDs []string) (*types.TransferListResult, error) {
+	if len(requestIDs) == 0 {
+		return nil, errors.New("parameter \"request_ids\" is required and must be a non-empty array")
 	}
-
-	var result types.TransferListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
+	result, notFound, err := utils.LookupByIDs(ctx, t.caller, epGetAccountTransferList, requestIDs,
+		func(r *types.TransferListResult) *[]*types.Transfer { return &r.Data },
+		func(t *types.Transfer) string { return t.RequestID })
+	if result != nil {
+		result.NotFound = notFound
 	}
-	return &result, nil
+	return result, err
 }
 
 // SyncAccountTransferList syncs account transfer list by max ID (pagination)
@@ -98,23 +89,14 @@ func (t *TransferAPI) GetAccountTransferList(requestIDs []string) (*types.Transf
 //
 // Returns: Synced transfer records
 func (t *TransferAPI) SyncAccountTransferList(maxID int64) (*types.TransferListResult, error) {
+	return t.SyncAccountTransferListContext(context.Background(), maxID)
+}
+
+// SyncAccountTransferListContext is like SyncAccountTransferList but carries ctx through to the HTTP request.
+func (t *TransferAPI) SyncAccountTransferListContext(ctx context.Context, maxID int64) (*types.TransferListResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := t.Post("/account/syncTransferList", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = t.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.TransferListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.TransferListResult](ctx, t.caller, epSyncAccountTransferList, params)
 }
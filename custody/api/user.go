@@ -2,8 +2,10 @@
 package api
 
 import (
+	"context"
+
 	"chainup.com/go-sdk/custody/types"
-	"encoding/json"
+	"chainup.com/go-sdk/utils"
 )
 
 // UserAPI provides user management and registration operations
@@ -18,15 +20,6 @@ func NewUserAPI(config ConfigProvider) *UserAPI {
 	}
 }
 
-// unmarshalResponse converts map response to the given result pointer.
-func unmarshalResponse(response map[string]interface{}, result interface{}) error {
-	jsonBytes, err := json.Marshal(response)
-	if err != nil {
-		return err
-	}
-	return json.Unmarshal(jsonBytes, result)
-}
-
 // RegisterMobileUser registers a new user using mobile phone
 // Parameters:
 //   - country: Country code (e.g., '86')
@@ -34,26 +27,17 @@ func unmarshalResponse(response map[string]interface{}, result interface{}) erro
 //
 // Returns: User registration result containing uid
 func (u *UserAPI) RegisterMobileUser(country, mobile string) (*types.UserInfoResult, error) {
+	return u.RegisterMobileUserContext(context.Background(), country, mobile)
+}
+
+// RegisterMobileUserContext is like RegisterMobileUser but carries ctx through to the HTTP request.
+func (u *UserAPI) RegisterMobileUserContext(ctx context.Context, country, mobile string) (*types.UserInfoResult, error) {
 	params := map[string]interface{}{
 		"country": country,
 		"mobile":  mobile,
 	}
 
-	response, err := u.Post("/user/createUser", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = u.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.UserInfoResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.UserInfoResult](ctx, u.caller, epRegisterMobileUser, params)
 }
 
 // RegisterEmailUser registers a new user using email
@@ -62,25 +46,16 @@ func (u *UserAPI) RegisterMobileUser(country, mobile string) (*types.UserInfoRes
 //
 // Returns: User registration result containing uid
 func (u *UserAPI) RegisterEmailUser(email string) (*types.UserInfoResult, error) {
+	return u.RegisterEmailUserContext(context.Background(), email)
+}
+
+// RegisterEmailUserContext is like RegisterEmailUser but carries ctx through to the HTTP request.
+func (u *UserAPI) RegisterEmailUserContext(ctx context.Context, email string) (*types.UserInfoResult, error) {
 	params := map[string]interface{}{
 		"email": email,
 	}
 
-	response, err := u.Post("/user/registerEmail", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = u.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.UserInfoResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.UserInfoResult](ctx, u.caller, epRegisterEmailUser, params)
 }
 
 // GetMobileUser gets user information by mobile phone
@@ -90,26 +65,17 @@ func (u *UserAPI) RegisterEmailUser(email string) (*types.UserInfoResult, error)
 //
 // Returns: User information
 func (u *UserAPI) GetMobileUser(country, mobile string) (*types.UserInfoResult, error) {
+	return u.GetMobileUserContext(context.Background(), country, mobile)
+}
+
+// GetMobileUserContext is like GetMobileUser but carries ctx through to the HTTP request.
+func (u *UserAPI) GetMobileUserContext(ctx context.Context, country, mobile string) (*types.UserInfoResult, error) {
 	params := map[string]interface{}{
 		"country": country,
 		"mobile":  mobile,
 	}
 
-	response, err := u.Post("/user/info", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = u.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.UserInfoResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.UserInfoResult](ctx, u.caller, epGetMobileUser, params)
 }
 
 // GetEmailUser gets user information by email
@@ -118,25 +84,16 @@ func (u *UserAPI) GetMobileUser(country, mobile string) (*types.UserInfoResult,
 //
 // Returns: User information
 func (u *UserAPI) GetEmailUser(email string) (*types.UserInfoResult, error) {
+	return u.GetEmailUserContext(context.Background(), email)
+}
+
+// GetEmailUserContext is like GetEmailUser but carries ctx through to the HTTP request.
+func (u *UserAPI) GetEmailUserContext(ctx context.Context, email string) (*types.UserInfoResult, error) {
 	params := map[string]interface{}{
 		"email": email,
 	}
 
-	response, err := u.Post("/user/info", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = u.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.UserInfoResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.UserInfoResult](ctx, u.caller, epGetEmailUser, params)
 }
 
 // SyncUserList syncs user list by max ID (pagination)
@@ -145,23 +102,14 @@ func (u *UserAPI) GetEmailUser(email string) (*types.UserInfoResult, error) {
 //
 // Returns: Synced user list
 func (u *UserAPI) SyncUserList(maxID int64) (*types.UserListResult, error) {
+	return u.SyncUserListContext(context.Background(), maxID)
+}
+
+// SyncUserListContext is like SyncUserList but carries ctx through to the HTTP request.
+func (u *UserAPI) SyncUserListContext(ctx context.Context, maxID int64) (*types.UserListResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := u.Post("/user/syncList", params)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = u.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.UserListResult
-	if err := unmarshalResponse(response, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return utils.CallEndpoint[types.UserListResult](ctx, u.caller, epSyncUserList, params)
 }
@@ -2,15 +2,45 @@
 package custody
 
 import (
+	"context"
+	"log/slog"
+	"net/http"
+
 	"chainup.com/go-sdk/custody/api"
+	"chainup.com/go-sdk/utils"
 )
 
 // Client is the main entry point for WaaS API operations.
-// It provides factory methods for creating API instances.
+// All API instances returned by a Client share one HTTP transport and are
+// created once, so the getters are cheap and the Client is safe for
+// concurrent use.
 type Client struct {
 	config *Config
+	base   *api.BaseAPI
+
+	userAPI        *api.UserAPI
+	accountAPI     *api.AccountAPI
+	billingAPI     *api.BillingAPI
+	coinAPI        *api.CoinAPI
+	transferAPI    *api.TransferAPI
+	asyncNotifyAPI *api.AsyncNotifyAPI
+}
+
+// Interface is the interface of Client. Code depending on it can be tested
+// with the mocks of package custodymock.
+type Interface interface {
+	GetUserAPI() api.UserService
+	GetAccountAPI() api.AccountService
+	GetBillingAPI() api.BillingService
+	GetCoinAPI() api.CoinService
+	GetTransferAPI() api.TransferService
+	GetAsyncNotifyAPI() api.AsyncNotifyService
+	RawCall(ctx context.Context, method, path string, params map[string]interface{}) (*utils.RawResponse, error)
+	HostStats() []utils.HostStats
 }
 
+var _ Interface = (*Client)(nil)
+
 // WaasClient is an alias for Client for backward compatibility.
 type WaasClient = Client
 
@@ -20,39 +50,64 @@ func NewWaasClient(config *Config) (*Client, error) {
 		return nil, err
 	}
 
+	base := api.NewBaseAPI(config)
+
 	return &Client{
-		config: config,
+		config:         config,
+		base:           base,
+		userAPI:        &api.UserAPI{BaseAPI: base},
+		accountAPI:     &api.AccountAPI{BaseAPI: base},
+		billingAPI:     &api.BillingAPI{BaseAPI: base},
+		coinAPI:        &api.CoinAPI{BaseAPI: base},
+		transferAPI:    &api.TransferAPI{BaseAPI: base},
+		asyncNotifyAPI: &api.AsyncNotifyAPI{BaseAPI: base},
 	}, nil
 }
 
 // GetUserAPI returns UserAPI instance for user management.
-func (c *Client) GetUserAPI() *api.UserAPI {
-	return api.NewUserAPI(c.config)
+func (c *Client) GetUserAPI() api.UserService {
+	return c.userAPI
 }
 
 // GetAccountAPI returns AccountAPI instance for account management.
-func (c *Client) GetAccountAPI() *api.AccountAPI {
-	return api.NewAccountAPI(c.config)
+func (c *Client) GetAccountAPI() api.AccountService {
+	return c.accountAPI
 }
 
 // GetBillingAPI returns BillingAPI instance for billing operations.
-func (c *Client) GetBillingAPI() *api.BillingAPI {
-	return api.NewBillingAPI(c.config)
+func (c *Client) GetBillingAPI() api.BillingService {
+	return c.billingAPI
 }
 
 // GetCoinAPI returns CoinAPI instance for coin information.
-func (c *Client) GetCoinAPI() *api.CoinAPI {
-	return api.NewCoinAPI(c.config)
+func (c *Client) GetCoinAPI() api.CoinService {
+	return c.coinAPI
 }
 
 // GetTransferAPI returns TransferAPI instance for transfer operations.
-func (c *Client) GetTransferAPI() *api.TransferAPI {
-	return api.NewTransferAPI(c.config)
+func (c *Client) GetTransferAPI() api.TransferService {
+	return c.transferAPI
 }
 
 // GetAsyncNotifyAPI returns AsyncNotifyAPI instance for notification handling.
-func (c *Client) GetAsyncNotifyAPI() *api.AsyncNotifyAPI {
-	return api.NewAsyncNotifyAPI(c.config)
+func (c *Client) GetAsyncNotifyAPI() api.AsyncNotifyService {
+	return c.asyncNotifyAPI
+}
+
+// RawCall executes an endpoint the SDK does not wrap yet, with the same
+// encryption, logging and error handling as the built-in methods. Decode the
+// result with utils.DecodeRaw or the RawResponse helpers:
+//
+//	resp, err := client.RawCall(ctx, utils.HTTPMethodPost, "/billing/newEndpoint", params)
+//	result, err := utils.DecodeRaw[MyResult](resp)
+func (c *Client) RawCall(ctx context.Context, method, path string, params map[string]interface{}) (*utils.RawResponse, error) {
+	return c.base.RawCall(ctx, method, path, params)
+}
+
+// HostStats returns the health of every host the client sends requests to,
+// including how often calls failed over from it.
+func (c *Client) HostStats() []utils.HostStats {
+	return c.base.HostStats()
 }
 
 // ClientBuilder helps build Client with a fluent interface.
@@ -106,6 +161,91 @@ func (b *ClientBuilder) SetTimeout(timeout int) *ClientBuilder {
 	return b
 }
 
+// SetRetryPolicy sets the retry policy for transient failures.
+func (b *ClientBuilder) SetRetryPolicy(policy *utils.RetryPolicy) *ClientBuilder {
+	b.configBuilder.SetRetryPolicy(policy)
+	return b
+}
+
+// SetHTTPClient sets a custom HTTP client for all requests.
+func (b *ClientBuilder) SetHTTPClient(client *http.Client) *ClientBuilder {
+	b.configBuilder.SetHTTPClient(client)
+	return b
+}
+
+// SetTransport sets a custom HTTP transport.
+func (b *ClientBuilder) SetTransport(transport http.RoundTripper) *ClientBuilder {
+	b.configBuilder.SetTransport(transport)
+	return b
+}
+
+// AddMiddleware appends middleware to the HTTP middleware chain.
+func (b *ClientBuilder) AddMiddleware(middlewares ...utils.Middleware) *ClientBuilder {
+	b.configBuilder.AddMiddleware(middlewares...)
+	return b
+}
+
+// SetLogger sets the structured logger.
+func (b *ClientBuilder) SetLogger(logger *slog.Logger) *ClientBuilder {
+	b.configBuilder.SetLogger(logger)
+	return b
+}
+
+// SetRedactor sets the redactor applied to logged payloads.
+func (b *ClientBuilder) SetRedactor(redactor *utils.Redactor) *ClientBuilder {
+	b.configBuilder.SetRedactor(redactor)
+	return b
+}
+
+// SetRateLimiter sets the client-side rate limiter, which may be shared between clients.
+func (b *ClientBuilder) SetRateLimiter(limiter *utils.RateLimiter) *ClientBuilder {
+	b.configBuilder.SetRateLimiter(limiter)
+	return b
+}
+
+// SetCircuitBreaker sets the circuit breaker guarding calls per host and endpoint group.
+func (b *ClientBuilder) SetCircuitBreaker(breaker *utils.CircuitBreaker) *ClientBuilder {
+	b.configBuilder.SetCircuitBreaker(breaker)
+	return b
+}
+
+// SetFailoverHosts sets the hosts tried in order when Host is unavailable.
+func (b *ClientBuilder) SetFailoverHosts(hosts ...string) *ClientBuilder {
+	b.configBuilder.SetFailoverHosts(hosts...)
+	return b
+}
+
+// SetTracer sets the tracer receiving one span per API call.
+func (b *ClientBuilder) SetTracer(tracer utils.Tracer) *ClientBuilder {
+	b.configBuilder.SetTracer(tracer)
+	return b
+}
+
+// SetMetrics sets the collector receiving per-call metrics.
+func (b *ClientBuilder) SetMetrics(metrics utils.Collector) *ClientBuilder {
+	b.configBuilder.SetMetrics(metrics)
+	return b
+}
+
+// SetCassette sets the cassette recording or replaying every call.
+func (b *ClientBuilder) SetCassette(cassette *utils.Cassette) *ClientBuilder {
+	b.configBuilder.SetCassette(cassette)
+	return b
+}
+
+// SetAllowUnverifiedResponses disables strict authenticity mode, accepting
+// success responses that were not decrypted with the ChainUp public key.
+func (b *ClientBuilder) SetAllowUnverifiedResponses(allow bool) *ClientBuilder {
+	b.configBuilder.SetAllowUnverifiedResponses(allow)
+	return b
+}
+
+// SetLookupBatch sets how lookups by ID lists are split into parallel requests.
+func (b *ClientBuilder) SetLookupBatch(settings utils.BatchSettings) *ClientBuilder {
+	b.configBuilder.SetLookupBatch(settings)
+	return b
+}
+
 // Build creates and returns a configured Client instance.
 func (b *ClientBuilder) Build() (*Client, error) {
 	config, err := b.configBuilder.Build()
@@ -0,0 +1,473 @@
+// Package custody provides tests and benchmarks for the WaaS client
+package custody
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chainup.com/go-sdk/custody/api"
+	"chainup.com/go-sdk/utils"
+	"chainup.com/go-sdk/utils/sdkerrors"
+	"github.com/shopspring/decimal"
+)
+
+// newBenchServer starts a server answering every call with an empty list and
+// counts the TCP connections it accepts.
+func newBenchServer(b *testing.B) (*httptest.Server, *int64) {
+	b.Helper()
+	var conns int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":"0","msg":"success","data":[]}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&conns, 1)
+		}
+	}
+	server.Start()
+	b.Cleanup(server.Close)
+	return server, &conns
+}
+
+// depositPage returns a sync response holding n deposit records.
+func depositPage(n int) string {
+	records := make([]string, n)
+	for i := range records {
+		records[i] = fmt.Sprintf(`{"id":%d,"uid":%d,"symbol":"ETH","base_symbol":"ETH","amount":"1.000000000000000001",`+
+			`"address_to":"0x52908400098527886e0f7030069857d2e4169ee7","txid":"0x%064d","confirmations":12,"status":2,`+
+			`"txid_type":0,"is_mining":0,"email":"","contract_address":"","created_at":1700000000000,"updated_at":1700000000000}`, i+1, 1000+i, i)
+	}
+	return `{"code":"0","msg":"success","data":[` + strings.Join(records, ",") + `]}`
+}
+
+// newBenchConfig returns a valid config pointing at host. The test servers
+// answer in plaintext, so strict authenticity mode is disabled.
+func newBenchConfig(b testing.TB, host string) *Config {
+	b.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		b.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		b.Fatalf("Failed to marshal public key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	return &Config{
+		Host:       host,
+		AppID:      "bench-app",
+		PrivateKey: string(privatePEM),
+		PublicKey:  string(publicPEM),
+
+		AllowUnverifiedResponses: true,
+	}
+}
+
+// BenchmarkGetUserAPI compares the cached getter with building a facade per call.
+func BenchmarkGetUserAPI(b *testing.B) {
+	config := newBenchConfig(b, "http://127.0.0.1")
+	client, err := NewWaasClient(config)
+	if err != nil {
+		b.Fatalf("Failed to create client: %v", err)
+	}
+
+	b.Run("Cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = client.GetUserAPI()
+		}
+	})
+
+	b.Run("PerCall", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = api.NewUserAPI(config)
+		}
+	})
+}
+
+// BenchmarkSyncUserList compares one shared transport with a fresh transport per
+// call, reporting the number of TCP connections opened per operation.
+func BenchmarkSyncUserList(b *testing.B) {
+	b.Run("SharedTransport", func(b *testing.B) {
+		server, conns := newBenchServer(b)
+		client, err := NewWaasClient(newBenchConfig(b, server.URL))
+		if err != nil {
+			b.Fatalf("Failed to create client: %v", err)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.GetUserAPI().SyncUserList(0); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(atomic.LoadInt64(conns))/float64(b.N), "conns/op")
+	})
+
+	b.Run("FreshTransport", func(b *testing.B) {
+		server, conns := newBenchServer(b)
+		config := newBenchConfig(b, server.URL)
+		if err := config.Validate(); err != nil {
+			b.Fatalf("Invalid config: %v", err)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			transport := utils.NewDefaultTransport()
+			perCall := *config
+			perCall.Transport = transport
+			if _, err := api.NewUserAPI(&perCall).SyncUserList(0); err != nil {
+				b.Fatal(err)
+			}
+			transport.CloseIdleConnections()
+		}
+		b.ReportMetric(float64(atomic.LoadInt64(conns))/float64(b.N), "conns/op")
+	})
+}
+
+// BenchmarkSyncDepositListDecode measures a sync call returning a full page of
+// deposits, where response decoding dominates the allocations.
+func BenchmarkSyncDepositListDecode(b *testing.B) {
+	page := []byte(depositPage(100))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(page)
+	}))
+	b.Cleanup(server.Close)
+
+	client, err := NewWaasClient(newBenchConfig(b, server.URL))
+	if err != nil {
+		b.Fatalf("Failed to create client: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := client.GetBillingAPI().SyncDepositList(0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(result.Data) != 100 {
+			b.Fatalf("expected 100 deposits, got %d", len(result.Data))
+		}
+	}
+}
+
+func TestValidateHasNoTransportSideEffect(t *testing.T) {
+	config := newBenchConfig(t, "http://127.0.0.1")
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if config.Transport != nil {
+		t.Fatalf("Validate() set Transport = %v, want nil", config.Transport)
+	}
+	if _, err := NewWaasClient(config); err != nil {
+		t.Fatalf("NewWaasClient() error = %v", err)
+	}
+	if config.Transport != nil {
+		t.Fatalf("NewWaasClient() set Transport = %v, want nil", config.Transport)
+	}
+}
+
+func TestRawPostRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		wantCalls int32
+	}{
+		{name: "without request_id", data: map[string]interface{}{"symbol": "ETH"}, wantCalls: 1},
+		{name: "with request_id", data: map[string]interface{}{"symbol": "ETH", "request_id": "r-1"}, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			config := newBenchConfig(t, server.URL)
+			config.RetryPolicy = &utils.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+			if err := config.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			if _, err := api.NewBillingAPI(config).PostContext(context.Background(), "/billing/withdraw", tt.data); err == nil {
+				t.Fatal("PostContext() error = nil, want HTTP 503")
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Fatalf("PostContext() sent %d requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRawCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/billing/newEndpoint":
+			_, _ = w.Write([]byte(`{"code":"0","msg":"success","data":{"id":9007199254740993,"amount":"1.000000000000000001"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"code":"100004","msg":"request_id already exists"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewWaasClient(newBenchConfig(t, server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	type record struct {
+		ID     int64           `json:"id"`
+		Amount decimal.Decimal `json:"amount"`
+	}
+
+	t.Run("decode", func(t *testing.T) {
+		resp, err := client.RawCall(context.Background(), utils.HTTPMethodPost, "/billing/newEndpoint", map[string]interface{}{"symbol": "ETH"})
+		if err != nil {
+			t.Fatalf("RawCall() error = %v", err)
+		}
+
+		var data record
+		if err := resp.DecodeData(&data); err != nil {
+			t.Fatalf("DecodeData() error = %v", err)
+		}
+		if data.ID != 9007199254740993 || data.Amount.String() != "1.000000000000000001" {
+			t.Fatalf("DecodeData() = %+v", data)
+		}
+
+		full, err := utils.DecodeRaw[struct {
+			Code string `json:"code"`
+			Data record `json:"data"`
+		}](resp)
+		if err != nil {
+			t.Fatalf("DecodeRaw() error = %v", err)
+		}
+		if full.Code != "0" || full.Data.ID != data.ID {
+			t.Fatalf("DecodeRaw() = %+v", full)
+		}
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		_, err := client.RawCall(context.Background(), utils.HTTPMethodPost, "/billing/other", map[string]interface{}{"request_id": "r-1"})
+		var apiErr *sdkerrors.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("RawCall() error = %v, want *sdkerrors.APIError", err)
+		}
+		if apiErr.Endpoint != "/billing/other" || apiErr.RequestID != "r-1" {
+			t.Fatalf("APIError = %+v", apiErr)
+		}
+		if !errors.Is(err, sdkerrors.ErrDuplicateRequestID) {
+			t.Fatalf("errors.Is(ErrDuplicateRequestID) = false for %v", err)
+		}
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		if _, err := client.RawCall(context.Background(), http.MethodPut, "/billing/newEndpoint", nil); err == nil {
+			t.Fatalf("RawCall() should reject PUT")
+		}
+	})
+}
+
+func TestTracing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":"0","msg":"success","data":{"id":1}}`))
+	}))
+	defer server.Close()
+
+	recorder := utils.NewTraceRecorder()
+	config := newBenchConfig(t, server.URL)
+	config.Tracer = recorder
+	client, err := NewWaasClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.GetBillingAPI().WithdrawContext(context.Background(), &api.WithdrawArgs{
+		RequestID: "r-1", FromUID: 1, ToAddress: "0xabc", Amount: decimal.RequireFromString("0.1"), Symbol: "ETH",
+	})
+	if err != nil {
+		t.Fatalf("WithdrawContext() error = %v", err)
+	}
+
+	spans := recorder.Spans()
+	if len(spans) == 0 || spans[0].Name != "waas.BillingAPI.Withdraw" || spans[0].ParentID != 0 {
+		t.Fatalf("root span = %+v", spans)
+	}
+	root := spans[0]
+	wantAttrs := map[string]interface{}{
+		"path": "/billing/withdraw", "app_id": "bench-app", "request_id": "r-1", "symbol": "ETH", "code": "0",
+	}
+	for key, want := range wantAttrs {
+		if got := root.Attributes[key]; got != want {
+			t.Fatalf("attribute %s = %v, want %v", key, got, want)
+		}
+	}
+
+	var names []string
+	for _, child := range recorder.Children(root.ID) {
+		names = append(names, child.Name)
+	}
+	want := []string{utils.SpanEncrypt, utils.SpanHTTP, utils.SpanDecrypt, utils.SpanDecode}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("child spans = %v, want %v", names, want)
+	}
+}
+
+func TestCassette(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":"0","msg":"success","data":{"id":7,"sign":"server-signature"}}`))
+	}))
+	path := filepath.Join(t.TempDir(), "withdraw.json")
+	args := &api.WithdrawArgs{
+		RequestID: "r-1", FromUID: 1, ToAddress: "0xabc", Amount: decimal.RequireFromString("0.1"), Symbol: "ETH",
+	}
+
+	// Record against the server.
+	recorder := utils.NewCassetteRecorder(path)
+	config := newBenchConfig(t, server.URL)
+	config.Cassette = recorder
+	client, err := NewWaasClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	recorded, err := client.GetBillingAPI().WithdrawContext(context.Background(), args)
+	if err != nil {
+		t.Fatalf("WithdrawContext() error = %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Close()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, unwanted := range []string{"server-signature", `"time"`, `"app_id"`} {
+		if strings.Contains(string(raw), unwanted) {
+			t.Fatalf("cassette contains %s:\n%s", unwanted, raw)
+		}
+	}
+	if !strings.Contains(string(raw), `"request_id": "r-1"`) {
+		t.Fatalf("cassette misses the plaintext request args:\n%s", raw)
+	}
+
+	// Replay without the server.
+	cassette, err := utils.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+	config = newBenchConfig(t, server.URL)
+	config.Cassette = cassette
+	client, err = NewWaasClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    *api.WithdrawArgs
+		wantErr error
+	}{
+		{name: "recorded call", args: args},
+		{name: "repeated call", args: args},
+		{name: "unrecorded call", args: &api.WithdrawArgs{
+			RequestID: "r-2", FromUID: 1, ToAddress: "0xabc", Amount: decimal.RequireFromString("0.1"), Symbol: "ETH",
+		}, wantErr: utils.ErrCassetteMiss},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayed, err := client.GetBillingAPI().WithdrawContext(context.Background(), tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithdrawContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && replayed.Data.ID != recorded.Data.ID {
+				t.Fatalf("replayed ID = %d, want %d", replayed.Data.ID, recorded.Data.ID)
+			}
+		})
+	}
+}
+
+func TestStrictAuthenticity(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	metrics := utils.NewMetrics()
+	config := newBenchConfig(t, server.URL)
+	config.AllowUnverifiedResponses = false
+	config.Metrics = metrics
+	client, err := NewWaasClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	cipher, err := config.CryptoProvider.EncryptWithPrivateKey(`{"code":"0","msg":"success","data":{"id":1}}`)
+	if err != nil {
+		t.Fatalf("EncryptWithPrivateKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    error
+		wantReason string
+	}{
+		{name: "encrypted", body: `{"data":"` + cipher + `"}`},
+		{name: "plaintext success", body: `{"code":"0","msg":"success","data":{"id":1}}`,
+			wantErr: sdkerrors.ErrUnauthenticResponse, wantReason: sdkerrors.ReasonNotEncrypted},
+		{name: "undecryptable", body: `{"data":"bm90IGEgY2lwaGVy"}`,
+			wantErr: sdkerrors.ErrUnauthenticResponse, wantReason: sdkerrors.ReasonDecryptFailed},
+		{name: "plaintext error", body: `{"code":"100004","msg":"request_id already exists"}`, wantErr: sdkerrors.ErrDuplicateRequestID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = []byte(tt.body)
+			_, err := client.GetBillingAPI().WithdrawContext(context.Background(), &api.WithdrawArgs{
+				RequestID: "r-1", FromUID: 1, ToAddress: "0xabc", Amount: decimal.RequireFromString("0.1"), Symbol: "ETH",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithdrawContext() error = %v, want %v", err, tt.wantErr)
+			}
+			var authErr *sdkerrors.AuthenticityError
+			if tt.wantReason != "" && (!errors.As(err, &authErr) || authErr.Reason != tt.wantReason || authErr.Endpoint != "/billing/withdraw") {
+				t.Fatalf("AuthenticityError = %+v", authErr)
+			}
+		})
+	}
+
+	var out strings.Builder
+	if err := metrics.Write(&out); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, want := range []string{
+		`chainup_sdk_unverified_responses_total{service="waas",endpoint="BillingAPI.Withdraw",reason="not_encrypted",action="rejected"} 1`,
+		`chainup_sdk_calls_total{service="waas",endpoint="BillingAPI.Withdraw",code="",error_class="unauthentic_response"} 2`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("metrics miss %s:\n%s", want, out.String())
+		}
+	}
+}
@@ -4,6 +4,8 @@ package custody
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 
 	"chainup.com/go-sdk/utils"
 )
@@ -36,6 +38,60 @@ type Config struct {
 
 	// CryptoProvider is a custom crypto provider (optional).
 	CryptoProvider utils.CryptoProvider
+
+	// RetryPolicy enables automatic retries of transient failures (optional).
+	// Money-moving calls are only retried when they carry a request_id.
+	RetryPolicy *utils.RetryPolicy
+
+	// HTTPClient is a custom HTTP client used for all requests (optional).
+	// When set, Timeout and Transport are ignored.
+	HTTPClient *http.Client
+
+	// Transport is a custom RoundTripper, e.g. for proxies, TLS roots or mTLS (optional).
+	Transport http.RoundTripper
+
+	// Middlewares run around every HTTP round trip (optional).
+	Middlewares []utils.Middleware
+
+	// Logger receives structured SDK logs (optional).
+	// When nil, debug output goes to stdout if Debug is set and is discarded otherwise.
+	Logger *slog.Logger
+
+	// Redactor masks secrets and PII in logged payloads (optional, default: utils.NewRedactor()).
+	Redactor *utils.Redactor
+
+	// RateLimiter throttles calls per app ID and endpoint group (optional).
+	// Share one limiter between clients using the same credentials.
+	RateLimiter *utils.RateLimiter
+
+	// CircuitBreaker rejects calls immediately while a host keeps failing (optional).
+	CircuitBreaker *utils.CircuitBreaker
+
+	// FailoverHosts are tried in order when Host is unavailable (optional).
+	// Reads fail over automatically; money-moving calls only with a request_id.
+	FailoverHosts []string
+
+	// Tracer receives one span per API call with encrypt, http, decrypt and
+	// decode child spans (optional).
+	Tracer utils.Tracer
+
+	// Metrics receives the start, outcome and latency of every API call (optional).
+	// Use utils.NewMetrics for a Prometheus-compatible collector.
+	Metrics utils.Collector
+
+	// Cassette records the plaintext request args and decrypted responses of
+	// every call, or replays them without any network (optional, for tests).
+	Cassette *utils.Cassette
+
+	// AllowUnverifiedResponses disables strict authenticity mode (optional).
+	// By default a success response that was not decrypted with the ChainUp
+	// public key is rejected with *sdkerrors.AuthenticityError; when set, it
+	// is returned as-is and only counted by the metrics collector.
+	AllowUnverifiedResponses bool
+
+	// LookupBatch splits lookups by long ID lists into requests run in
+	// parallel (optional, default: 100 IDs per request, 4 requests at once).
+	LookupBatch utils.BatchSettings
 }
 
 // WaasConfig is an alias for Config for backward compatibility.
@@ -111,6 +167,76 @@ func (c *Config) GetCryptoProvider() utils.CryptoProvider {
 	return c.CryptoProvider
 }
 
+// GetRetryPolicy returns the retry policy.
+func (c *Config) GetRetryPolicy() *utils.RetryPolicy {
+	return c.RetryPolicy
+}
+
+// GetHTTPClient returns the custom HTTP client.
+func (c *Config) GetHTTPClient() *http.Client {
+	return c.HTTPClient
+}
+
+// GetTransport returns the custom HTTP transport.
+func (c *Config) GetTransport() http.RoundTripper {
+	return c.Transport
+}
+
+// GetMiddlewares returns the HTTP middleware chain.
+func (c *Config) GetMiddlewares() []utils.Middleware {
+	return c.Middlewares
+}
+
+// GetLogger returns the structured logger.
+func (c *Config) GetLogger() *slog.Logger {
+	return c.Logger
+}
+
+// GetRedactor returns the log redactor.
+func (c *Config) GetRedactor() *utils.Redactor {
+	return c.Redactor
+}
+
+// GetRateLimiter returns the client-side rate limiter (may be nil).
+func (c *Config) GetRateLimiter() *utils.RateLimiter {
+	return c.RateLimiter
+}
+
+// GetCircuitBreaker returns the circuit breaker (may be nil).
+func (c *Config) GetCircuitBreaker() *utils.CircuitBreaker {
+	return c.CircuitBreaker
+}
+
+// GetFailoverHosts returns the hosts tried after Host.
+func (c *Config) GetFailoverHosts() []string {
+	return c.FailoverHosts
+}
+
+// GetTracer returns the tracer (may be nil).
+func (c *Config) GetTracer() utils.Tracer {
+	return c.Tracer
+}
+
+// GetMetrics returns the metrics collector (may be nil).
+func (c *Config) GetMetrics() utils.Collector {
+	return c.Metrics
+}
+
+// GetCassette returns the cassette (may be nil).
+func (c *Config) GetCassette() *utils.Cassette {
+	return c.Cassette
+}
+
+// GetAllowUnverifiedResponses reports whether strict authenticity mode is disabled.
+func (c *Config) GetAllowUnverifiedResponses() bool {
+	return c.AllowUnverifiedResponses
+}
+
+// GetLookupBatch returns the batching of lookups by ID lists.
+func (c *Config) GetLookupBatch() utils.BatchSettings {
+	return c.LookupBatch
+}
+
 // ConfigBuilder helps build Config with a fluent interface.
 type ConfigBuilder struct {
 	config *Config
@@ -180,6 +306,91 @@ func (b *ConfigBuilder) SetCryptoProvider(provider utils.CryptoProvider) *Config
 	return b
 }
 
+// SetRetryPolicy sets the retry policy for transient failures.
+func (b *ConfigBuilder) SetRetryPolicy(policy *utils.RetryPolicy) *ConfigBuilder {
+	b.config.RetryPolicy = policy
+	return b
+}
+
+// SetHTTPClient sets a custom HTTP client for all requests.
+func (b *ConfigBuilder) SetHTTPClient(client *http.Client) *ConfigBuilder {
+	b.config.HTTPClient = client
+	return b
+}
+
+// SetTransport sets a custom HTTP transport.
+func (b *ConfigBuilder) SetTransport(transport http.RoundTripper) *ConfigBuilder {
+	b.config.Transport = transport
+	return b
+}
+
+// AddMiddleware appends middleware to the HTTP middleware chain.
+func (b *ConfigBuilder) AddMiddleware(middlewares ...utils.Middleware) *ConfigBuilder {
+	b.config.Middlewares = append(b.config.Middlewares, middlewares...)
+	return b
+}
+
+// SetLogger sets the structured logger.
+func (b *ConfigBuilder) SetLogger(logger *slog.Logger) *ConfigBuilder {
+	b.config.Logger = logger
+	return b
+}
+
+// SetRedactor sets the redactor applied to logged payloads.
+func (b *ConfigBuilder) SetRedactor(redactor *utils.Redactor) *ConfigBuilder {
+	b.config.Redactor = redactor
+	return b
+}
+
+// SetRateLimiter sets the client-side rate limiter, which may be shared between clients.
+func (b *ConfigBuilder) SetRateLimiter(limiter *utils.RateLimiter) *ConfigBuilder {
+	b.config.RateLimiter = limiter
+	return b
+}
+
+// SetCircuitBreaker sets the circuit breaker guarding calls per host and endpoint group.
+func (b *ConfigBuilder) SetCircuitBreaker(breaker *utils.CircuitBreaker) *ConfigBuilder {
+	b.config.CircuitBreaker = breaker
+	return b
+}
+
+// SetFailoverHosts sets the hosts tried in order when Host is unavailable.
+func (b *ConfigBuilder) SetFailoverHosts(hosts ...string) *ConfigBuilder {
+	b.config.FailoverHosts = hosts
+	return b
+}
+
+// SetTracer sets the tracer receiving one span per API call.
+func (b *ConfigBuilder) SetTracer(tracer utils.Tracer) *ConfigBuilder {
+	b.config.Tracer = tracer
+	return b
+}
+
+// SetMetrics sets the collector receiving per-call metrics.
+func (b *ConfigBuilder) SetMetrics(metrics utils.Collector) *ConfigBuilder {
+	b.config.Metrics = metrics
+	return b
+}
+
+// SetCassette sets the cassette recording or replaying every call.
+func (b *ConfigBuilder) SetCassette(cassette *utils.Cassette) *ConfigBuilder {
+	b.config.Cassette = cassette
+	return b
+}
+
+// SetAllowUnverifiedResponses disables strict authenticity mode, accepting
+// success responses that were not decrypted with the ChainUp public key.
+func (b *ConfigBuilder) SetAllowUnverifiedResponses(allow bool) *ConfigBuilder {
+	b.config.AllowUnverifiedResponses = allow
+	return b
+}
+
+// SetLookupBatch sets how lookups by ID lists are split into parallel requests.
+func (b *ConfigBuilder) SetLookupBatch(settings utils.BatchSettings) *ConfigBuilder {
+	b.config.LookupBatch = settings
+	return b
+}
+
 // Build creates and validates the Config.
 func (b *ConfigBuilder) Build() (*Config, error) {
 	if err := b.config.Validate(); err != nil {
@@ -0,0 +1,109 @@
+// Package custodymock provides mocks of the custody client and its API facades,
+// for testing code that depends on custody.Interface or the api.*Service
+// interfaces without a server.
+//
+// All facades of a Client share one Mock, which records every call in
+// order and serves the programmed responses. Methods are named like the
+// endpoints, "<Facade>.<Method>", and the plain and Context variants of a
+// method share one name:
+//
+//	client := custodymock.NewClient()
+//	client.Return("BillingAPI.Withdraw", &types.WithdrawResult{Code: "0"}, nil)
+//
+//	err := payouts.Run(ctx, client) // accepts a custody.Interface
+//	calls := client.CallsTo("BillingAPI.Withdraw")
+package custodymock
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/custody"
+	"chainup.com/go-sdk/custody/api"
+	"chainup.com/go-sdk/internal/mocktest"
+	"chainup.com/go-sdk/utils"
+)
+
+// Mock records the calls of the mocked methods and serves their programmed
+// responses, see Mock.Return and Mock.Handle.
+type Mock = mocktest.Mock
+
+// Call is a call recorded by a Mock.
+type Call = mocktest.Call
+
+// Handler computes the response of a mocked method from its arguments.
+type Handler = mocktest.Handler
+
+// ErrNotProgrammed is returned by a mocked method that has no programmed
+// response.
+var ErrNotProgrammed = mocktest.ErrNotProgrammed
+
+// Client is a mock custody.Interface. Program and inspect it through the
+// embedded Mock; the facade fields share it.
+type Client struct {
+	*Mock
+
+	User        *UserAPI
+	Account     *AccountAPI
+	Billing     *BillingAPI
+	Coin        *CoinAPI
+	Transfer    *TransferAPI
+	AsyncNotify *AsyncNotifyAPI
+}
+
+// NewClient creates a mock client with no programmed responses.
+func NewClient() *Client {
+	mock := &Mock{}
+	return &Client{
+		Mock:        mock,
+		User:        &UserAPI{Mock: mock},
+		Account:     &AccountAPI{Mock: mock},
+		Billing:     &BillingAPI{Mock: mock},
+		Coin:        &CoinAPI{Mock: mock},
+		Transfer:    &TransferAPI{Mock: mock},
+		AsyncNotify: &AsyncNotifyAPI{Mock: mock},
+	}
+}
+
+// GetUserAPI returns the UserAPI mock.
+func (c *Client) GetUserAPI() api.UserService {
+	return c.User
+}
+
+// GetAccountAPI returns the AccountAPI mock.
+func (c *Client) GetAccountAPI() api.AccountService {
+	return c.Account
+}
+
+// GetBillingAPI returns the BillingAPI mock.
+func (c *Client) GetBillingAPI() api.BillingService {
+	return c.Billing
+}
+
+// GetCoinAPI returns the CoinAPI mock.
+func (c *Client) GetCoinAPI() api.CoinService {
+	return c.Coin
+}
+
+// GetTransferAPI returns the TransferAPI mock.
+func (c *Client) GetTransferAPI() api.TransferService {
+	return c.Transfer
+}
+
+// GetAsyncNotifyAPI returns the AsyncNotifyAPI mock.
+func (c *Client) GetAsyncNotifyAPI() api.AsyncNotifyService {
+	return c.AsyncNotify
+}
+
+// RawCall records a call to Client.RawCall.
+func (c *Client) RawCall(_ context.Context, method, path string, params map[string]interface{}) (*utils.RawResponse, error) {
+	return mocktest.Called[*utils.RawResponse](c.Mock, "Client.RawCall", method, path, params)
+}
+
+// HostStats records a call to Client.HostStats. It returns nil unless a
+// response is programmed.
+func (c *Client) HostStats() []utils.HostStats {
+	stats, _ := mocktest.Called[[]utils.HostStats](c.Mock, "Client.HostStats")
+	return stats
+}
+
+var _ custody.Interface = (*Client)(nil)
@@ -0,0 +1,66 @@
+package custodymock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"chainup.com/go-sdk/custody"
+	"chainup.com/go-sdk/custody/api"
+	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
+	"chainup.com/go-sdk/utils/sdkerrors"
+	"github.com/shopspring/decimal"
+)
+
+// withdrawAll withdraws the balance of a user, as code under test would.
+func withdrawAll(ctx context.Context, client custody.Interface, uid int64, to string) error {
+	account, err := client.GetAccountAPI().GetUserAccountContext(ctx, uid, "ETH")
+	if err != nil {
+		return err
+	}
+	_, err = client.GetBillingAPI().WithdrawContext(ctx, &api.WithdrawArgs{
+		RequestID: "w-1", FromUID: uid, ToAddress: to, Amount: account.Data.NormalBalance, Symbol: "ETH",
+	})
+	return err
+}
+
+func TestClient(t *testing.T) {
+	client := NewClient()
+	client.Return("AccountAPI.GetUserAccount", &types.AccountResult{Data: &types.Account{NormalBalance: decimal.NewFromInt(2)}}, nil)
+	client.Return("BillingAPI.Withdraw", nil, sdkerrors.ErrInsufficientBalance)
+
+	err := withdrawAll(context.Background(), client, 7, "0xabc")
+	if !errors.Is(err, sdkerrors.ErrInsufficientBalance) {
+		t.Fatalf("withdrawAll() error = %v", err)
+	}
+	calls := client.CallsTo("BillingAPI.Withdraw")
+	if len(calls) != 1 || !calls[0].Args[0].(*api.WithdrawArgs).Amount.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("CallsTo() = %+v", calls)
+	}
+	if _, err := client.Billing.Withdraw(&api.WithdrawArgs{}); err == nil || len(client.Calls()) != 3 {
+		t.Fatalf("Withdraw() error = %v, calls = %+v", err, client.Calls())
+	}
+}
+
+func TestDepositCursor(t *testing.T) {
+	client := NewClient()
+	client.Handle("BillingAPI.SyncDepositList", func(args ...interface{}) (interface{}, error) {
+		if maxID := args[0].(int64); maxID < 4 {
+			return &types.DepositListResult{Data: []*types.Deposit{{ID: int(maxID) + 1}, {ID: int(maxID) + 2}}}, nil
+		}
+		return &types.DepositListResult{}, nil
+	})
+
+	cursor := api.NewDepositCursor(context.Background(), client.GetBillingAPI(), utils.WithStartID(1))
+	var ids []int
+	for cursor.Next() {
+		ids = append(ids, cursor.Item().ID)
+	}
+	if cursor.Err() != nil || len(ids) != 4 || ids[0] != 2 || ids[3] != 5 {
+		t.Fatalf("deposits = %v, err = %v", ids, cursor.Err())
+	}
+	if calls := client.CallsTo("BillingAPI.SyncDepositList"); len(calls) != 3 {
+		t.Fatalf("CallsTo() = %+v", calls)
+	}
+}
@@ -0,0 +1,265 @@
+// Package custodymock provides mocks of the custody client and its API facades.
+package custodymock
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/custody/api"
+	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/internal/mocktest"
+)
+
+// UserAPI is a mock api.UserService.
+type UserAPI struct {
+	*Mock
+}
+
+// RegisterMobileUser records a call to UserAPI.RegisterMobileUser.
+func (m *UserAPI) RegisterMobileUser(country, mobile string) (*types.UserInfoResult, error) {
+	return m.RegisterMobileUserContext(context.Background(), country, mobile)
+}
+
+// RegisterMobileUserContext records a call to UserAPI.RegisterMobileUser.
+func (m *UserAPI) RegisterMobileUserContext(_ context.Context, country, mobile string) (*types.UserInfoResult, error) {
+	return mocktest.Called[*types.UserInfoResult](m.Mock, "UserAPI.RegisterMobileUser", country, mobile)
+}
+
+// RegisterEmailUser records a call to UserAPI.RegisterEmailUser.
+func (m *UserAPI) RegisterEmailUser(email string) (*types.UserInfoResult, error) {
+	return m.RegisterEmailUserContext(context.Background(), email)
+}
+
+// RegisterEmailUserContext records a call to UserAPI.RegisterEmailUser.
+func (m *UserAPI) RegisterEmailUserContext(_ context.Context, email string) (*types.UserInfoResult, error) {
+	return mocktest.Called[*types.UserInfoResult](m.Mock, "UserAPI.RegisterEmailUser", email)
+}
+
+// GetMobileUser records a call to UserAPI.GetMobileUser.
+func (m *UserAPI) GetMobileUser(country, mobile string) (*types.UserInfoResult, error) {
+	return m.GetMobileUserContext(context.Background(), country, mobile)
+}
+
+// GetMobileUserContext records a call to UserAPI.GetMobileUser.
+func (m *UserAPI) GetMobileUserContext(_ context.Context, country, mobile string) (*types.UserInfoResult, error) {
+	return mocktest.Called[*types.UserInfoResult](m.Mock, "UserAPI.GetMobileUser", country, mobile)
+}
+
+// GetEmailUser records a call to UserAPI.GetEmailUser.
+func (m *UserAPI) GetEmailUser(email string) (*types.UserInfoResult, error) {
+	return m.GetEmailUserContext(context.Background(), email)
+}
+
+// GetEmailUserContext records a call to UserAPI.GetEmailUser.
+func (m *UserAPI) GetEmailUserContext(_ context.Context, email string) (*types.UserInfoResult, error) {
+	return mocktest.Called[*types.UserInfoResult](m.Mock, "UserAPI.GetEmailUser", email)
+}
+
+// SyncUserList records a call to UserAPI.SyncUserList.
+func (m *UserAPI) SyncUserList(maxID int64) (*types.UserListResult, error) {
+	return m.SyncUserListContext(context.Background(), maxID)
+}
+
+// SyncUserListContext records a call to UserAPI.SyncUserList.
+func (m *UserAPI) SyncUserListContext(_ context.Context, maxID int64) (*types.UserListResult, error) {
+	return mocktest.Called[*types.UserListResult](m.Mock, "UserAPI.SyncUserList", maxID)
+}
+
+// AccountAPI is a mock api.AccountService.
+type AccountAPI struct {
+	*Mock
+}
+
+// GetUserAccount records a call to AccountAPI.GetUserAccount.
+func (m *AccountAPI) GetUserAccount(uid int64, symbol string) (*types.AccountResult, error) {
+	return m.GetUserAccountContext(context.Background(), uid, symbol)
+}
+
+// GetUserAccountContext records a call to AccountAPI.GetUserAccount.
+func (m *AccountAPI) GetUserAccountContext(_ context.Context, uid int64, symbol string) (*types.AccountResult, error) {
+	return mocktest.Called[*types.AccountResult](m.Mock, "AccountAPI.GetUserAccount", uid, symbol)
+}
+
+// GetUserAddress records a call to AccountAPI.GetUserAddress.
+func (m *AccountAPI) GetUserAddress(uid int64, symbol string) (*types.UserAddressResult, error) {
+	return m.GetUserAddressContext(context.Background(), uid, symbol)
+}
+
+// GetUserAddressContext records a call to AccountAPI.GetUserAddress.
+func (m *AccountAPI) GetUserAddressContext(_ context.Context, uid int64, symbol string) (*types.UserAddressResult, error) {
+	return mocktest.Called[*types.UserAddressResult](m.Mock, "AccountAPI.GetUserAddress", uid, symbol)
+}
+
+// GetCompanyAccount records a call to AccountAPI.GetCompanyAccount.
+func (m *AccountAPI) GetCompanyAccount(symbol string) (*types.CompanyAccountResult, error) {
+	return m.GetCompanyAccountContext(context.Background(), symbol)
+}
+
+// GetCompanyAccountContext records a call to AccountAPI.GetCompanyAccount.
+func (m *AccountAPI) GetCompanyAccountContext(_ context.Context, symbol string) (*types.CompanyAccountResult, error) {
+	return mocktest.Called[*types.CompanyAccountResult](m.Mock, "AccountAPI.GetCompanyAccount", symbol)
+}
+
+// GetUserAddressInfo records a call to AccountAPI.GetUserAddressInfo.
+func (m *AccountAPI) GetUserAddressInfo(address string) (*types.UserAddressResult, error) {
+	return m.GetUserAddressInfoContext(context.Background(), address)
+}
+
+// GetUserAddressInfoContext records a call to AccountAPI.GetUserAddressInfo.
+func (m *AccountAPI) GetUserAddressInfoContext(_ context.Context, address string) (*types.UserAddressResult, error) {
+	return mocktest.Called[*types.UserAddressResult](m.Mock, "AccountAPI.GetUserAddressInfo", address)
+}
+
+// SyncUserAddressList records a call to AccountAPI.SyncUserAddressList.
+func (m *AccountAPI) SyncUserAddressList(maxID int64) (*types.UserAddressListResult, error) {
+	return m.SyncUserAddressListContext(context.Background(), maxID)
+}
+
+// SyncUserAddressListContext records a call to AccountAPI.SyncUserAddressList.
+func (m *AccountAPI) SyncUserAddressListContext(_ context.Context, maxID int64) (*types.UserAddressListResult, error) {
+	return mocktest.Called[*types.UserAddressListResult](m.Mock, "AccountAPI.SyncUserAddressList", maxID)
+}
+
+// BillingAPI is a mock api.BillingService.
+type BillingAPI struct {
+	*Mock
+}
+
+// Withdraw records a call to BillingAPI.Withdraw.
+func (m *BillingAPI) Withdraw(args *api.WithdrawArgs) (*types.WithdrawResult, error) {
+	return m.WithdrawContext(context.Background(), args)
+}
+
+// WithdrawContext records a call to BillingAPI.Withdraw.
+func (m *BillingAPI) WithdrawContext(_ context.Context, args *api.WithdrawArgs) (*types.WithdrawResult, error) {
+	return mocktest.Called[*types.WithdrawResult](m.Mock, "BillingAPI.Withdraw", args)
+}
+
+// WithdrawList records a call to BillingAPI.WithdrawList.
+func (m *BillingAPI) WithdrawList(requestIDs []string) (*types.WithdrawListResult, error) {
+	return m.WithdrawListContext(context.Background(), requestIDs)
+}
+
+// WithdrawListContext records a call to BillingAPI.WithdrawList.
+func (m *BillingAPI) WithdrawListContext(_ context.Context, requestIDs []string) (*types.WithdrawListResult, error) {
+	return mocktest.Called[*types.WithdrawListResult](m.Mock, "BillingAPI.WithdrawList", requestIDs)
+}
+
+// SyncWithdrawList records a call to BillingAPI.SyncWithdrawList.
+func (m *BillingAPI) SyncWithdrawList(maxID int64) (*types.WithdrawListResult, error) {
+	return m.SyncWithdrawListContext(context.Background(), maxID)
+}
+
+// SyncWithdrawListContext records a call to BillingAPI.SyncWithdrawList.
+func (m *BillingAPI) SyncWithdrawListContext(_ context.Context, maxID int64) (*types.WithdrawListResult, error) {
+	return mocktest.Called[*types.WithdrawListResult](m.Mock, "BillingAPI.SyncWithdrawList", maxID)
+}
+
+// DepositList records a call to BillingAPI.DepositList.
+func (m *BillingAPI) DepositList(ids []int64) (*types.DepositListResult, error) {
+	return m.DepositListContext(context.Background(), ids)
+}
+
+// DepositListContext records a call to BillingAPI.DepositList.
+func (m *BillingAPI) DepositListContext(_ context.Context, ids []int64) (*types.DepositListResult, error) {
+	return mocktest.Called[*types.DepositListResult](m.Mock, "BillingAPI.DepositList", ids)
+}
+
+// SyncDepositList records a call to BillingAPI.SyncDepositList.
+func (m *BillingAPI) SyncDepositList(maxID int64) (*types.DepositListResult, error) {
+	return m.SyncDepositListContext(context.Background(), maxID)
+}
+
+// SyncDepositListContext records a call to BillingAPI.SyncDepositList.
+func (m *BillingAPI) SyncDepositListContext(_ context.Context, maxID int64) (*types.DepositListResult, error) {
+	return mocktest.Called[*types.DepositListResult](m.Mock, "BillingAPI.SyncDepositList", maxID)
+}
+
+// MinerFeeList records a call to BillingAPI.MinerFeeList.
+func (m *BillingAPI) MinerFeeList(ids []int64) (*types.MinerFeeListResult, error) {
+	return m.MinerFeeListContext(context.Background(), ids)
+}
+
+// MinerFeeListContext records a call to BillingAPI.MinerFeeList.
+func (m *BillingAPI) MinerFeeListContext(_ context.Context, ids []int64) (*types.MinerFeeListResult, error) {
+	return mocktest.Called[*types.MinerFeeListResult](m.Mock, "BillingAPI.MinerFeeList", ids)
+}
+
+// SyncMinerFeeList records a call to BillingAPI.SyncMinerFeeList.
+func (m *BillingAPI) SyncMinerFeeList(maxID int64) (*types.MinerFeeListResult, error) {
+	return m.SyncMinerFeeListContext(context.Background(), maxID)
+}
+
+// SyncMinerFeeListContext records a call to BillingAPI.SyncMinerFeeList.
+func (m *BillingAPI) SyncMinerFeeListContext(_ context.Context, maxID int64) (*types.MinerFeeListResult, error) {
+	return mocktest.Called[*types.MinerFeeListResult](m.Mock, "BillingAPI.SyncMinerFeeList", maxID)
+}
+
+// CoinAPI is a mock api.CoinService.
+type CoinAPI struct {
+	*Mock
+}
+
+// GetCoinList records a call to CoinAPI.GetCoinList.
+func (m *CoinAPI) GetCoinList() (*types.CoinInfoListResult, error) {
+	return m.GetCoinListContext(context.Background())
+}
+
+// GetCoinListContext records a call to CoinAPI.GetCoinList.
+func (m *CoinAPI) GetCoinListContext(_ context.Context) (*types.CoinInfoListResult, error) {
+	return mocktest.Called[*types.CoinInfoListResult](m.Mock, "CoinAPI.GetCoinList")
+}
+
+// TransferAPI is a mock api.TransferService.
+type TransferAPI struct {
+	*Mock
+}
+
+// AccountTransfer records a call to TransferAPI.AccountTransfer.
+func (m *TransferAPI) AccountTransfer(args *api.TransferArgs) (*types.TransferResult, error) {
+	return m.AccountTransferContext(context.Background(), args)
+}
+
+// AccountTransferContext records a call to TransferAPI.AccountTransfer.
+func (m *TransferAPI) AccountTransferContext(_ context.Context, args *api.TransferArgs) (*types.TransferResult, error) {
+	return mocktest.Called[*types.TransferResult](m.Mock, "TransferAPI.AccountTransfer", args)
+}
+
+// GetAccountTransferList records a call to TransferAPI.GetAccountTransferList.
+func (m *TransferAPI) GetAccountTransferList(requestIDs []string) (*types.TransferListResult, error) {
+	return m.GetAccountTransferListContext(context.Background(), requestIDs)
+}
+
+// GetAccountTransferListContext records a call to TransferAPI.GetAccountTransferList.
+func (m *TransferAPI) GetAccountTransferListContext(_ context.Context, requestIDs []string) (*types.TransferListResult, error) {
+	return mocktest.Called[*types.TransferListResult](m.Mock, "TransferAPI.GetAccountTransferList", requestIDs)
+}
+
+// SyncAccountTransferList records a call to TransferAPI.SyncAccountTransferList.
+func (m *TransferAPI) SyncAccountTransferList(maxID int64) (*types.TransferListResult, error) {
+	return m.SyncAccountTransferListContext(context.Background(), maxID)
+}
+
+// SyncAccountTransferListContext records a call to TransferAPI.SyncAccountTransferList.
+func (m *TransferAPI) SyncAccountTransferListContext(_ context.Context, maxID int64) (*types.TransferListResult, error) {
+	return mocktest.Called[*types.TransferListResult](m.Mock, "TransferAPI.SyncAccountTransferList", maxID)
+}
+
+// AsyncNotifyAPI is a mock api.AsyncNotifyService.
+type AsyncNotifyAPI struct {
+	*Mock
+}
+
+// NotifyRequest records a call to AsyncNotifyAPI.NotifyRequest.
+func (m *AsyncNotifyAPI) NotifyRequest(cipher string) (*types.AsyncNotifyArgs, error) {
+	return mocktest.Called[*types.AsyncNotifyArgs](m.Mock, "AsyncNotifyAPI.NotifyRequest", cipher)
+}
+
+// VerifyRequest records a call to AsyncNotifyAPI.VerifyRequest.
+func (m *AsyncNotifyAPI) VerifyRequest(cipher string) (*api.WithdrawArgs, error) {
+	return mocktest.Called[*api.WithdrawArgs](m.Mock, "AsyncNotifyAPI.VerifyRequest", cipher)
+}
+
+// VerifyResponse records a call to AsyncNotifyAPI.VerifyResponse.
+func (m *AsyncNotifyAPI) VerifyResponse(args *api.WithdrawArgs) (string, error) {
+	return mocktest.Called[string](m.Mock, "AsyncNotifyAPI.VerifyResponse", args)
+}
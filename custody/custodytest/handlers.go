@@ -0,0 +1,355 @@
+// Package custodytest provides an in-process fake WaaS server for tests.
+package custodytest
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
+	"github.com/shopspring/decimal"
+)
+
+// handler serves one endpoint. s.mu is held while it runs.
+type handler func(s *Server, args map[string]interface{}) (interface{}, error)
+
+// routes maps endpoint paths, relative to /api/v2, to their handlers.
+var routes = map[string]handler{
+	"/user/createUser":               (*Server).createUser,
+	"/user/registerEmail":            (*Server).registerEmail,
+	"/user/info":                     (*Server).userInfo,
+	"/user/syncList":                 (*Server).syncUserList,
+	"/user/getCoinList":              (*Server).coinList,
+	"/account/getByUidAndSymbol":     (*Server).userAccount,
+	"/account/getDepositAddress":     (*Server).userAddress,
+	"/account/getCompanyBySymbol":    (*Server).companyAccount,
+	"/account/getDepositAddressInfo": (*Server).userAddressInfo,
+	"/address/syncList":              (*Server).syncAddressList,
+	"/billing/withdraw":              (*Server).createWithdraw,
+	"/billing/withdrawList":          (*Server).withdrawList,
+	"/billing/syncWithdrawList":      (*Server).syncWithdrawList,
+	"/billing/depositList":           (*Server).depositList,
+	"/billing/syncDepositList":       (*Server).syncDepositList,
+	"/billing/minerFeeList":          (*Server).minerFeeList,
+	"/billing/syncMinerFeeList":      (*Server).syncMinerFeeList,
+	"/account/transfer":              (*Server).createTransfer,
+	"/account/transferList":          (*Server).transferList,
+	"/account/syncTransferList":      (*Server).syncTransferList,
+}
+
+var errInvalidArgs = &failure{code: CodeInvalidArgs, msg: "invalid parameters"}
+
+func (s *Server) createUser(args map[string]interface{}) (interface{}, error) {
+	country, mobile := utils.StringField(args, "country"), utils.StringField(args, "mobile")
+	if country == "" || mobile == "" {
+		return nil, errInvalidArgs
+	}
+	if s.findUser(country, mobile, "") != nil {
+		return nil, &failure{code: CodeUserExists, msg: "user already exists"}
+	}
+	u := s.addUser(&user{country: country, mobile: mobile, info: types.UserInfo{Nickname: mobile}})
+	return u.info, nil
+}
+
+func (s *Server) registerEmail(args map[string]interface{}) (interface{}, error) {
+	email := utils.StringField(args, "email")
+	if email == "" {
+		return nil, errInvalidArgs
+	}
+	if s.findUser("", "", email) != nil {
+		return nil, &failure{code: CodeUserExists, msg: "user already exists"}
+	}
+	u := s.addUser(&user{email: email, info: types.UserInfo{Nickname: email}})
+	return u.info, nil
+}
+
+func (s *Server) userInfo(args map[string]interface{}) (interface{}, error) {
+	u := s.findUser(utils.StringField(args, "country"), utils.StringField(args, "mobile"), utils.StringField(args, "email"))
+	if u == nil {
+		return nil, &failure{code: CodeUserNotFound, msg: "user not found"}
+	}
+	return u.info, nil
+}
+
+func (s *Server) syncUserList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	infos := make([]types.UserInfo, 0, len(s.users))
+	for _, u := range s.users {
+		infos = append(infos, u.info)
+	}
+	return syncPage(infos, func(u types.UserInfo) int64 { return u.UID.Int64() }, maxID), nil
+}
+
+func (s *Server) coinList(map[string]interface{}) (interface{}, error) {
+	return s.coins, nil
+}
+
+func (s *Server) userAccount(args map[string]interface{}) (interface{}, error) {
+	uid, symbol, err := s.userAndCoin(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.account(uid, symbol), nil
+}
+
+func (s *Server) userAddress(args map[string]interface{}) (interface{}, error) {
+	uid, symbol, err := s.userAndCoin(args)
+	if err != nil {
+		return nil, err
+	}
+	coin, _ := s.coin(symbol)
+	return s.depositAddress(uid, coin), nil
+}
+
+func (s *Server) companyAccount(args map[string]interface{}) (interface{}, error) {
+	coin, err := s.coin(utils.StringField(args, "symbol"))
+	if err != nil {
+		return nil, err
+	}
+	if account, ok := s.company[coin.Symbol]; ok {
+		return account, nil
+	}
+	return &types.CompanyAccount{Symbol: coin.Symbol}, nil
+}
+
+func (s *Server) userAddressInfo(args map[string]interface{}) (interface{}, error) {
+	address := utils.StringField(args, "address")
+	for _, a := range s.addresses {
+		if a.Address == address {
+			return a, nil
+		}
+	}
+	return nil, &failure{code: CodeAddressNotFound, msg: "address not found"}
+}
+
+func (s *Server) syncAddressList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.addresses, func(a *types.UserAddress) int64 { return a.Id.Int64() }, maxID), nil
+}
+
+func (s *Server) createWithdraw(args map[string]interface{}) (interface{}, error) {
+	requestID := utils.StringField(args, "request_id")
+	uid, ok := intArg(args, "from_uid")
+	amount, amountOK := decimalArg(args, "amount")
+	if requestID == "" || !ok || !amountOK || !amount.IsPositive() {
+		return nil, errInvalidArgs
+	}
+	if s.requestIDs[requestID] {
+		return nil, &failure{code: CodeDuplicateRequestID, msg: "request_id already exists"}
+	}
+	u, err := s.userByUID(uid)
+	if err != nil {
+		return nil, err
+	}
+	coin, err := s.coin(utils.StringField(args, "symbol"))
+	if err != nil {
+		return nil, err
+	}
+	toAddress := utils.StringField(args, "to_address")
+	if !validAddress(coin, toAddress) {
+		return nil, &failure{code: CodeInvalidAddress, msg: "address invalid"}
+	}
+	account := s.account(uid, coin.Symbol)
+	if account.NormalBalance.LessThan(amount) {
+		return nil, &failure{code: CodeInsufficientBalance, msg: "insufficient balance"}
+	}
+
+	account.NormalBalance = account.NormalBalance.Sub(amount)
+	account.LockBalance = account.LockBalance.Add(amount)
+	s.requestIDs[requestID] = true
+
+	now := utils.Timestamp{Time: time.Now()}
+	id := s.newID()
+	s.withdraws = append(s.withdraws, &types.Withdraw{
+		Id:                utils.FlexInt(id),
+		RequestID:         requestID,
+		Uid:               uid,
+		Email:             u.email,
+		Symbol:            coin.Symbol,
+		BaseSymbol:        coin.BaseSymbol,
+		ContractAddress:   coin.ContractAddress,
+		AddressTo:         toAddress,
+		Amount:            amount,
+		Status:            WithdrawPending,
+		FeeSymbol:         coin.BaseSymbol,
+		WithdrawFeeSymbol: coin.Symbol,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	})
+	return map[string]int64{"id": id}, nil
+}
+
+func (s *Server) withdrawList(args map[string]interface{}) (interface{}, error) {
+	ids := stringSet(utils.StringField(args, "ids"))
+	list := make([]*types.Withdraw, 0)
+	for _, withdraw := range s.withdraws {
+		if ids[withdraw.RequestID] {
+			list = append(list, withdraw)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) syncWithdrawList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.withdraws, func(w *types.Withdraw) int64 { return w.Id.Int64() }, maxID), nil
+}
+
+func (s *Server) depositList(args map[string]interface{}) (interface{}, error) {
+	ids := stringSet(utils.StringField(args, "ids"))
+	list := make([]*types.Deposit, 0)
+	for _, deposit := range s.deposits {
+		if ids[strconv.Itoa(deposit.ID)] {
+			list = append(list, deposit)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) syncDepositList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.deposits, func(d *types.Deposit) int64 { return int64(d.ID) }, maxID), nil
+}
+
+func (s *Server) minerFeeList(args map[string]interface{}) (interface{}, error) {
+	ids := stringSet(utils.StringField(args, "ids"))
+	list := make([]*types.MinerFee, 0)
+	for _, fee := range s.minerFees {
+		if ids[strconv.Itoa(fee.ID)] {
+			list = append(list, fee)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) syncMinerFeeList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.minerFees, func(f *types.MinerFee) int64 { return int64(f.ID) }, maxID), nil
+}
+
+func (s *Server) createTransfer(args map[string]interface{}) (interface{}, error) {
+	requestID := utils.StringField(args, "request_id")
+	fromUID, fromOK := intArg(args, "from_uid")
+	toUID, toOK := intArg(args, "to_uid")
+	amount, amountOK := decimalArg(args, "amount")
+	if requestID == "" || !fromOK || !toOK || !amountOK || !amount.IsPositive() {
+		return nil, errInvalidArgs
+	}
+	if s.requestIDs[requestID] {
+		return nil, &failure{code: CodeDuplicateRequestID, msg: "request_id already exists"}
+	}
+	for _, uid := range []int64{fromUID, toUID} {
+		if _, err := s.userByUID(uid); err != nil {
+			return nil, err
+		}
+	}
+	coin, err := s.coin(utils.StringField(args, "symbol"))
+	if err != nil {
+		return nil, err
+	}
+	from := s.account(fromUID, coin.Symbol)
+	if from.NormalBalance.LessThan(amount) {
+		return nil, &failure{code: CodeInsufficientBalance, msg: "insufficient balance"}
+	}
+
+	to := s.account(toUID, coin.Symbol)
+	from.NormalBalance = from.NormalBalance.Sub(amount)
+	to.NormalBalance = to.NormalBalance.Add(amount)
+	s.requestIDs[requestID] = true
+
+	id := s.newID()
+	s.transfers = append(s.transfers, &types.Transfer{
+		ID:        id,
+		RequestID: requestID,
+		Symbol:    coin.Symbol,
+		Amount:    amount,
+		From:      strconv.FormatInt(fromUID, 10),
+		To:        strconv.FormatInt(toUID, 10),
+		CreatedAt: utils.Timestamp{Time: time.Now()},
+		Receipt:   txid(id),
+		Remark:    utils.StringField(args, "remark"),
+	})
+	return map[string]int64{"id": id}, nil
+}
+
+func (s *Server) transferList(args map[string]interface{}) (interface{}, error) {
+	ids := stringSet(utils.StringField(args, "ids"))
+	list := make([]*types.Transfer, 0)
+	for _, transfer := range s.transfers {
+		if ids[transfer.RequestID] {
+			list = append(list, transfer)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) syncTransferList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.transfers, func(t *types.Transfer) int64 { return t.ID }, maxID), nil
+}
+
+// userAndCoin returns the existing user and coin named by the uid and symbol args.
+func (s *Server) userAndCoin(args map[string]interface{}) (int64, string, error) {
+	uid, ok := intArg(args, "uid")
+	if !ok {
+		return 0, "", errInvalidArgs
+	}
+	if _, err := s.userByUID(uid); err != nil {
+		return 0, "", err
+	}
+	coin, err := s.coin(utils.StringField(args, "symbol"))
+	if err != nil {
+		return 0, "", err
+	}
+	return uid, coin.Symbol, nil
+}
+
+// validAddress reports whether address matches the address format of coin.
+func validAddress(coin *types.CoinInfo, address string) bool {
+	if address == "" {
+		return false
+	}
+	if coin.AddressRegex == "" {
+		return true
+	}
+	re, err := regexp.Compile(coin.AddressRegex)
+	return err == nil && re.MatchString(address)
+}
+
+// intArg returns an integer arg, sent either as a number or as a string.
+func intArg(args map[string]interface{}, key string) (int64, bool) {
+	switch v := args[key].(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// decimalArg returns a decimal arg, sent either as a number or as a string.
+func decimalArg(args map[string]interface{}, key string) (decimal.Decimal, bool) {
+	value := utils.StringField(args, key)
+	if value == "" {
+		return decimal.Zero, false
+	}
+	d, err := decimal.NewFromString(value)
+	return d, err == nil
+}
+
+// stringSet splits a comma separated ID list.
+func stringSet(ids string) map[string]bool {
+	set := make(map[string]bool)
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			set[id] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,612 @@
+// Package custodytest provides an in-process fake WaaS server for tests.
+//
+// The server speaks the real WaaS protocol: requests carry the app_id and the
+// request args encrypted with the client private key, and responses carry the
+// code/msg/data document encrypted with the server private key. It keeps an
+// in-memory model of users, accounts, deposit addresses, deposits,
+// withdrawals, transfers and miner fees, so every custody/api method works end
+// to end. Tests drive the model directly, e.g. to inject deposits,
+// confirmations and error codes:
+//
+//	server := custodytest.NewServer()
+//	defer server.Close()
+//
+//	client, err := server.NewClient()
+//	uid := server.CreateUser("alice@example.com")
+//	deposit, err := server.InjectDeposit(uid, "ETH", decimal.RequireFromString("1.5"))
+//	err = server.ConfirmDeposit(deposit.ID, 12)
+package custodytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"chainup.com/go-sdk/custody"
+	"chainup.com/go-sdk/custody/types"
+	"chainup.com/go-sdk/utils"
+	"chainup.com/go-sdk/utils/sdkerrors"
+	"github.com/shopspring/decimal"
+)
+
+// AppID is the app ID the server accepts.
+const AppID = "custodytest-app"
+
+// SyncPageSize is the number of records returned by the Sync* endpoints.
+const SyncPageSize = 100
+
+// Response codes returned by the server. The codes of the sdkerrors
+// categories are the ChainUp codes registered by default in sdkerrors, so
+// errors returned by the server match errors.Is.
+const (
+	CodeSuccess             = "0"
+	CodeInvalidArgs         = "1001"
+	CodeInvalidAppID        = "1002"
+	CodeRateLimited         = sdkerrors.CodeRateLimited
+	CodeUserNotFound        = "2001"
+	CodeUserExists          = "2002"
+	CodeSymbolNotSupported  = "2003"
+	CodeAddressNotFound     = "2004"
+	CodeInsufficientBalance = sdkerrors.CodeInsufficientBalance
+	CodeDuplicateRequestID  = sdkerrors.CodeDuplicateRequestID
+	CodeInvalidAddress      = sdkerrors.CodeInvalidAddress
+)
+
+// Deposit statuses.
+const (
+	DepositConfirming = 0
+	DepositSuccess    = 1
+	DepositFailed     = 2
+)
+
+// Withdrawal statuses.
+const (
+	WithdrawPending = 0
+	WithdrawPaying  = 3
+	WithdrawFailed  = 4
+	WithdrawSuccess = 5
+)
+
+// apiPrefix is the path prefix of every WaaS endpoint.
+const apiPrefix = "/api/v2"
+
+// Request is a call received by the server, with its decrypted args.
+type Request struct {
+	Path string
+	Args map[string]interface{}
+}
+
+// Server is a fake WaaS server. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server, to be used as the client host.
+	URL string
+
+	server           *httptest.Server
+	crypto           *utils.RSACryptoProvider
+	clientPrivateKey string
+	serverPublicKey  string
+
+	mu         sync.Mutex
+	nextID     int64
+	users      []*user
+	coins      []*types.CoinInfo
+	accounts   map[accountKey]*types.Account
+	company    map[string]*types.CompanyAccount
+	minerFee   map[string]decimal.Decimal
+	addresses  []*types.UserAddress
+	deposits   []*types.Deposit
+	withdraws  []*types.Withdraw
+	transfers  []*types.Transfer
+	minerFees  []*types.MinerFee
+	requestIDs map[string]bool
+	errors     map[string][]failure
+	requests   []Request
+}
+
+// user is a registered user; exactly one of mobile and email is set.
+type user struct {
+	info    types.UserInfo
+	country string
+	mobile  string
+	email   string
+}
+
+// accountKey identifies the account of a user in one coin.
+type accountKey struct {
+	uid    int64
+	symbol string
+}
+
+// failure is an error response.
+type failure struct {
+	code string
+	msg  string
+}
+
+func (f *failure) Error() string {
+	return f.code + ": " + f.msg
+}
+
+// NewServer starts a fake WaaS server with fresh key pairs and the BTC and ETH
+// coins. It panics when the keys cannot be generated, like httptest.NewServer
+// does when it cannot listen. The caller should Close it when done.
+func NewServer() *Server {
+	clientPrivateKey, clientPublicKey, err := utils.GenerateRSAKeyPair(2048)
+	if err != nil {
+		panic("custodytest: " + err.Error())
+	}
+	serverPrivateKey, serverPublicKey, err := utils.GenerateRSAKeyPair(2048)
+	if err != nil {
+		panic("custodytest: " + err.Error())
+	}
+	crypto, err := utils.NewRSACryptoProvider(serverPrivateKey, clientPublicKey, utils.DefaultCharset)
+	if err != nil {
+		panic("custodytest: " + err.Error())
+	}
+
+	s := &Server{
+		crypto:           crypto,
+		clientPrivateKey: clientPrivateKey,
+		serverPublicKey:  serverPublicKey,
+		accounts:         make(map[accountKey]*types.Account),
+		company:          make(map[string]*types.CompanyAccount),
+		minerFee:         make(map[string]decimal.Decimal),
+		requestIDs:       make(map[string]bool),
+		errors:           make(map[string][]failure),
+	}
+	s.AddCoin(types.CoinInfo{
+		Symbol: "BTC", BaseSymbol: "BTC", RealSymbol: "BTC", CoinNet: "BTC", Decimals: 8,
+		AddressRegex:        `^(bc1|tb1)[0-9a-z]{11,71}$|^[13mn2][a-km-zA-HJ-NP-Z1-9]{25,39}$`,
+		DepositConfirmation: 1, WithdrawConfirmation: 1,
+	})
+	s.AddCoin(types.CoinInfo{
+		Symbol: "ETH", BaseSymbol: "ETH", RealSymbol: "ETH", CoinNet: "ETH", Decimals: 18,
+		AddressRegex:        `^0x[0-9a-fA-F]{40}$`,
+		DepositConfirmation: 12, WithdrawConfirmation: 12,
+	})
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Config returns a client config pointing at the server.
+func (s *Server) Config() *custody.Config {
+	return &custody.Config{
+		Host:       s.URL,
+		AppID:      AppID,
+		PrivateKey: s.clientPrivateKey,
+		PublicKey:  s.serverPublicKey,
+	}
+}
+
+// NewClient creates a client talking to the server.
+func (s *Server) NewClient() (*custody.Client, error) {
+	return custody.NewWaasClient(s.Config())
+}
+
+// InjectError makes the next call to path, e.g. "/billing/withdraw", fail
+// with code and msg. Errors injected for the same path are returned in order.
+func (s *Server) InjectError(path, code, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[path] = append(s.errors[path], failure{code: code, msg: msg})
+}
+
+// Requests returns the calls received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// serveHTTP decrypts a request, dispatches it and encrypts the response.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	handler, ok := routes[path]
+	if !ok || path == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Form.Get("app_id") != AppID {
+		s.reply(w, nil, &failure{code: CodeInvalidAppID, msg: "invalid app_id"})
+		return
+	}
+	plain, err := s.crypto.DecryptWithPublicKey(r.Form.Get("data"))
+	if err != nil {
+		s.reply(w, nil, &failure{code: CodeInvalidArgs, msg: "failed to decrypt data"})
+		return
+	}
+	var args map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(plain))
+	decoder.UseNumber()
+	if err := decoder.Decode(&args); err != nil {
+		s.reply(w, nil, &failure{code: CodeInvalidArgs, msg: "invalid data"})
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: path, Args: args})
+	var data interface{}
+	if queued := s.errors[path]; len(queued) > 0 {
+		s.errors[path] = queued[1:]
+		err = &queued[0]
+	} else {
+		data, err = handler(s, args)
+	}
+	// Encode while the lock is held: data points into the model.
+	document, encodeErr := encodeDocument(data, err)
+	s.mu.Unlock()
+
+	if encodeErr != nil {
+		http.Error(w, encodeErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.write(w, document)
+}
+
+// reply writes the encrypted response document of data or err.
+func (s *Server) reply(w http.ResponseWriter, data interface{}, err error) {
+	document, err := encodeDocument(data, err)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.write(w, document)
+}
+
+// encodeDocument encodes the code/msg/data response document.
+func encodeDocument(data interface{}, err error) ([]byte, error) {
+	document := map[string]interface{}{"code": CodeSuccess, "msg": "success", "data": data}
+	var f *failure
+	if errors.As(err, &f) {
+		document = map[string]interface{}{"code": f.code, "msg": f.msg, "data": nil}
+	} else if err != nil {
+		document = map[string]interface{}{"code": CodeInvalidArgs, "msg": err.Error(), "data": nil}
+	}
+	return json.Marshal(document)
+}
+
+// write encrypts document into the response envelope.
+func (s *Server) write(w http.ResponseWriter, document []byte) {
+	cipher, err := s.crypto.EncryptWithPrivateKey(string(document))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var body bytes.Buffer
+	_ = json.NewEncoder(&body).Encode(map[string]string{"data": cipher})
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body.Bytes())
+}
+
+// CreateUser registers a user by email, as RegisterEmailUser does, and
+// returns its UID. An existing user is returned as is.
+func (s *Server) CreateUser(email string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u := s.findUser("", "", email); u != nil {
+		return u.info.UID.Int64()
+	}
+	return s.addUser(&user{email: email, info: types.UserInfo{Nickname: email}}).info.UID.Int64()
+}
+
+// AddCoin adds a coin, or replaces the coin with the same symbol.
+func (s *Server) AddCoin(coin types.CoinInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.coins {
+		if existing.Symbol == coin.Symbol {
+			s.coins[i] = &coin
+			return
+		}
+	}
+	s.coins = append(s.coins, &coin)
+}
+
+// SetBalance sets the normal balance of a user in symbol.
+func (s *Server) SetBalance(uid int64, symbol string, balance decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.account(uid, symbol).NormalBalance = balance
+}
+
+// Balance returns the account of a user in symbol.
+func (s *Server) Balance(uid int64, symbol string) types.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.account(uid, symbol)
+}
+
+// SetCompanyBalance sets the balances of the company account in symbol.
+func (s *Server) SetCompanyBalance(symbol string, balance, feeBalance decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.company[symbol] = &types.CompanyAccount{
+		Symbol:            symbol,
+		Balance:           balance,
+		FeeAccountBalance: feeBalance,
+		TotalBalance:      balance.Add(feeBalance),
+	}
+}
+
+// SetMinerFee sets the miner fee charged for completed withdrawals of symbol.
+func (s *Server) SetMinerFee(symbol string, fee decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minerFee[symbol] = fee
+}
+
+// InjectDeposit records an incoming deposit of amount to the deposit address
+// of a user. The deposit starts unconfirmed; see ConfirmDeposit.
+func (s *Server) InjectDeposit(uid int64, symbol string, amount decimal.Decimal) (types.Deposit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, err := s.userByUID(uid)
+	if err != nil {
+		return types.Deposit{}, err
+	}
+	coin, err := s.coin(symbol)
+	if err != nil {
+		return types.Deposit{}, err
+	}
+
+	now := utils.Timestamp{Time: time.Now()}
+	id := s.newID()
+	deposit := &types.Deposit{
+		ID:         int(id),
+		Uid:        int(uid),
+		Email:      u.email,
+		Symbol:     coin.Symbol,
+		BaseSymbol: coin.BaseSymbol,
+		AddressTo:  s.depositAddress(uid, coin).Address,
+		Amount:     amount,
+		Status:     DepositConfirming,
+		Txid:       txid(id),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	s.deposits = append(s.deposits, deposit)
+	return *deposit, nil
+}
+
+// ConfirmDeposit sets the confirmations of a deposit. Once they reach the
+// deposit confirmations of the coin, the deposit succeeds and the amount is
+// credited to the user.
+func (s *Server) ConfirmDeposit(id int, confirmations int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deposit, err := s.deposit(id)
+	if err != nil {
+		return err
+	}
+	deposit.Confirmations = utils.FlexInt(confirmations)
+	deposit.UpdatedAt = utils.Timestamp{Time: time.Now()}
+
+	coin, _ := s.coin(deposit.Symbol)
+	if deposit.Status == DepositConfirming && int64(confirmations) >= coin.DepositConfirmation.Int64() {
+		deposit.Status = DepositSuccess
+		account := s.account(int64(deposit.Uid), deposit.Symbol)
+		account.NormalBalance = account.NormalBalance.Add(deposit.Amount)
+	}
+	return nil
+}
+
+// FailDeposit marks an unconfirmed deposit as failed.
+func (s *Server) FailDeposit(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deposit, err := s.deposit(id)
+	if err != nil {
+		return err
+	}
+	if deposit.Status != DepositConfirming {
+		return fmt.Errorf("custodytest: deposit %d is no longer confirming", id)
+	}
+	deposit.Status = DepositFailed
+	deposit.UpdatedAt = utils.Timestamp{Time: time.Now()}
+	return nil
+}
+
+// ConfirmWithdraw broadcasts a withdrawal and sets its confirmations. Once
+// they reach the withdraw confirmations of the coin, the withdrawal succeeds,
+// the locked amount is released and a miner fee record is added.
+func (s *Server) ConfirmWithdraw(id int64, confirmations int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	withdraw, err := s.withdraw(id)
+	if err != nil {
+		return err
+	}
+	if withdraw.Status != WithdrawPending && withdraw.Status != WithdrawPaying {
+		return fmt.Errorf("custodytest: withdrawal %d is no longer in progress", id)
+	}
+
+	now := utils.Timestamp{Time: time.Now()}
+	withdraw.Status = WithdrawPaying
+	withdraw.Txid = txid(id)
+	withdraw.Confirmations = utils.FlexInt(confirmations)
+	withdraw.UpdatedAt = now
+
+	coin, _ := s.coin(withdraw.Symbol)
+	if int64(confirmations) < coin.WithdrawConfirmation.Int64() {
+		return nil
+	}
+	withdraw.Status = WithdrawSuccess
+	withdraw.RealFee = s.minerFee[withdraw.Symbol]
+	account := s.account(withdraw.Uid, withdraw.Symbol)
+	account.LockBalance = account.LockBalance.Sub(withdraw.Amount)
+
+	s.minerFees = append(s.minerFees, &types.MinerFee{
+		ID:            int(s.newID()),
+		AddressFrom:   withdraw.AddressFrom,
+		AddressTo:     withdraw.AddressTo,
+		Amount:        withdraw.Amount,
+		BaseSymbol:    withdraw.BaseSymbol,
+		Confirmations: withdraw.Confirmations,
+		CreatedAt:     now,
+		Email:         withdraw.Email,
+		Fee:           withdraw.RealFee,
+		Status:        1,
+		Symbol:        withdraw.Symbol,
+		Txid:          withdraw.Txid,
+		UpdatedAt:     decimal.NewFromInt(now.UnixMilli()),
+	})
+	return nil
+}
+
+// FailWithdraw marks a withdrawal in progress as failed and refunds the user.
+func (s *Server) FailWithdraw(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	withdraw, err := s.withdraw(id)
+	if err != nil {
+		return err
+	}
+	if withdraw.Status != WithdrawPending && withdraw.Status != WithdrawPaying {
+		return fmt.Errorf("custodytest: withdrawal %d is no longer in progress", id)
+	}
+	withdraw.Status = WithdrawFailed
+	withdraw.UpdatedAt = utils.Timestamp{Time: time.Now()}
+	account := s.account(withdraw.Uid, withdraw.Symbol)
+	account.LockBalance = account.LockBalance.Sub(withdraw.Amount)
+	account.NormalBalance = account.NormalBalance.Add(withdraw.Amount)
+	return nil
+}
+
+// newID returns the next record ID. s.mu must be held.
+func (s *Server) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// addUser registers u with a new UID. s.mu must be held.
+func (s *Server) addUser(u *user) *user {
+	u.info.UID = utils.FlexInt(s.newID())
+	s.users = append(s.users, u)
+	return u
+}
+
+// findUser returns the user with the given mobile or email. s.mu must be held.
+func (s *Server) findUser(country, mobile, email string) *user {
+	for _, u := range s.users {
+		if email != "" && u.email == email || mobile != "" && u.country == country && u.mobile == mobile {
+			return u
+		}
+	}
+	return nil
+}
+
+// userByUID returns the user with uid. s.mu must be held.
+func (s *Server) userByUID(uid int64) (*user, error) {
+	for _, u := range s.users {
+		if u.info.UID.Int64() == uid {
+			return u, nil
+		}
+	}
+	return nil, &failure{code: CodeUserNotFound, msg: "user not found"}
+}
+
+// coin returns the coin with symbol. s.mu must be held.
+func (s *Server) coin(symbol string) (*types.CoinInfo, error) {
+	for _, coin := range s.coins {
+		if coin.Symbol == symbol {
+			return coin, nil
+		}
+	}
+	return nil, &failure{code: CodeSymbolNotSupported, msg: "symbol not supported"}
+}
+
+// account returns the account of a user in symbol, creating it. s.mu must be held.
+func (s *Server) account(uid int64, symbol string) *types.Account {
+	key := accountKey{uid: uid, symbol: symbol}
+	account, ok := s.accounts[key]
+	if !ok {
+		account = &types.Account{}
+		s.accounts[key] = account
+	}
+	return account
+}
+
+// depositAddress returns the deposit address of a user in coin, creating it. s.mu must be held.
+func (s *Server) depositAddress(uid int64, coin *types.CoinInfo) *types.UserAddress {
+	for _, address := range s.addresses {
+		if address.UID.Int64() == uid && address.Symbol == coin.Symbol {
+			return address
+		}
+	}
+
+	id := s.newID()
+	address := &types.UserAddress{Id: utils.FlexInt(id), UID: utils.FlexInt(uid), Symbol: coin.Symbol}
+	if coin.BaseSymbol == "BTC" {
+		address.Address = fmt.Sprintf("bc1q%038x", id)
+	} else {
+		address.Address = fmt.Sprintf("0x%040x", id)
+	}
+	s.addresses = append(s.addresses, address)
+	s.account(uid, coin.Symbol).DepositAddress = address.Address
+	return address
+}
+
+// deposit returns the deposit with id. s.mu must be held.
+func (s *Server) deposit(id int) (*types.Deposit, error) {
+	for _, deposit := range s.deposits {
+		if deposit.ID == id {
+			return deposit, nil
+		}
+	}
+	return nil, fmt.Errorf("custodytest: deposit %d not found", id)
+}
+
+// withdraw returns the withdrawal with id. s.mu must be held.
+func (s *Server) withdraw(id int64) (*types.Withdraw, error) {
+	for _, withdraw := range s.withdraws {
+		if withdraw.Id.Int64() == id {
+			return withdraw, nil
+		}
+	}
+	return nil, fmt.Errorf("custodytest: withdrawal %d not found", id)
+}
+
+// txid returns a fake transaction hash for a record.
+func txid(id int64) string {
+	return fmt.Sprintf("0x%064x", id)
+}
+
+// syncPage returns up to SyncPageSize records with an ID above maxID, in ID order.
+func syncPage[T any](records []T, id func(T) int64, maxID int64) []T {
+	page := make([]T, 0)
+	for _, record := range records {
+		if id(record) > maxID {
+			page = append(page, record)
+		}
+	}
+	sort.Slice(page, func(i, j int) bool { return id(page[i]) < id(page[j]) })
+	if len(page) > SyncPageSize {
+		page = page[:SyncPageSize]
+	}
+	return page
+}
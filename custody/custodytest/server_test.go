@@ -0,0 +1,197 @@
+// Package custodytest provides tests for the fake WaaS server.
+package custodytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"chainup.com/go-sdk/custody"
+	"chainup.com/go-sdk/custody/api"
+	"chainup.com/go-sdk/utils"
+	"chainup.com/go-sdk/utils/sdkerrors"
+	"github.com/shopspring/decimal"
+)
+
+func TestServerDepositAndWithdraw(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client, err := server.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	ctx := context.Background()
+
+	registered, err := client.GetUserAPI().RegisterEmailUserContext(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("RegisterEmailUserContext() error = %v", err)
+	}
+	uid := registered.Data.UID.Int64()
+	if found, err := client.GetUserAPI().GetEmailUserContext(ctx, "alice@example.com"); err != nil || found.Data.UID.Int64() != uid {
+		t.Fatalf("GetEmailUserContext() = %+v, %v", found, err)
+	}
+
+	address, err := client.GetAccountAPI().GetUserAddressContext(ctx, uid, "ETH")
+	if err != nil {
+		t.Fatalf("GetUserAddressContext() error = %v", err)
+	}
+	if info, err := client.GetAccountAPI().GetUserAddressInfoContext(ctx, address.Data.Address); err != nil || info.Data.UID.Int64() != uid {
+		t.Fatalf("GetUserAddressInfoContext() = %+v, %v", info, err)
+	}
+
+	deposit, err := server.InjectDeposit(uid, "ETH", decimal.RequireFromString("2"))
+	if err != nil {
+		t.Fatalf("InjectDeposit() error = %v", err)
+	}
+	if deposit.AddressTo != address.Data.Address {
+		t.Fatalf("deposit address = %s, want %s", deposit.AddressTo, address.Data.Address)
+	}
+	if err := server.ConfirmDeposit(deposit.ID, 12); err != nil {
+		t.Fatalf("ConfirmDeposit() error = %v", err)
+	}
+	deposits, err := client.GetBillingAPI().SyncDepositListContext(ctx, 0)
+	if err != nil || len(deposits.Data) != 1 || deposits.Data[0].Status.Int64() != DepositSuccess {
+		t.Fatalf("SyncDepositListContext() = %+v, %v", deposits, err)
+	}
+
+	withdrawArgs := &api.WithdrawArgs{
+		RequestID: "w-1", FromUID: uid, ToAddress: "0x00000000000000000000000000000000000000ff",
+		Amount: decimal.RequireFromString("1.5"), Symbol: "ETH",
+	}
+	withdrawn, err := client.GetBillingAPI().WithdrawContext(ctx, withdrawArgs)
+	if err != nil {
+		t.Fatalf("WithdrawContext() error = %v", err)
+	}
+	account, err := client.GetAccountAPI().GetUserAccountContext(ctx, uid, "ETH")
+	if err != nil || !account.Data.NormalBalance.Equal(decimal.RequireFromString("0.5")) || !account.Data.LockBalance.Equal(decimal.RequireFromString("1.5")) {
+		t.Fatalf("GetUserAccountContext() = %+v, %v", account.Data, err)
+	}
+
+	server.SetMinerFee("ETH", decimal.RequireFromString("0.001"))
+	if err := server.ConfirmWithdraw(withdrawn.Data.ID, 12); err != nil {
+		t.Fatalf("ConfirmWithdraw() error = %v", err)
+	}
+	withdraws, err := client.GetBillingAPI().WithdrawListContext(ctx, []string{"w-1"})
+	if err != nil || len(withdraws.Data) != 1 || withdraws.Data[0].Status != WithdrawSuccess || withdraws.Data[0].Txid == "" {
+		t.Fatalf("WithdrawListContext() = %+v, %v", withdraws, err)
+	}
+	fees, err := client.GetBillingAPI().SyncMinerFeeListContext(ctx, 0)
+	if err != nil || len(fees.Data) != 1 || !fees.Data[0].Fee.Equal(decimal.RequireFromString("0.001")) {
+		t.Fatalf("SyncMinerFeeListContext() = %+v, %v", fees, err)
+	}
+	if got := server.Balance(uid, "ETH"); !got.LockBalance.IsZero() {
+		t.Fatalf("lock balance = %s, want 0", got.LockBalance)
+	}
+
+	tests := []struct {
+		name    string
+		args    api.WithdrawArgs
+		inject  string
+		wantErr error
+	}{
+		{name: "duplicate request_id", args: *withdrawArgs, wantErr: sdkerrors.ErrDuplicateRequestID},
+		{name: "insufficient balance", args: api.WithdrawArgs{
+			RequestID: "w-2", FromUID: uid, ToAddress: withdrawArgs.ToAddress, Amount: decimal.RequireFromString("5"), Symbol: "ETH",
+		}, wantErr: sdkerrors.ErrInsufficientBalance},
+		{name: "invalid address", args: api.WithdrawArgs{
+			RequestID: "w-3", FromUID: uid, ToAddress: "not-an-address", Amount: decimal.RequireFromString("0.1"), Symbol: "ETH",
+		}, wantErr: sdkerrors.ErrInvalidAddress},
+		{name: "injected error", args: api.WithdrawArgs{
+			RequestID: "w-4", FromUID: uid, ToAddress: withdrawArgs.ToAddress, Amount: decimal.RequireFromString("0.1"), Symbol: "ETH",
+		}, inject: "too many requests", wantErr: sdkerrors.ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.inject != "" {
+				server.InjectError("/billing/withdraw", CodeRateLimited, tt.inject)
+			}
+			_, err := client.GetBillingAPI().WithdrawContext(ctx, &tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithdrawContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerTransfer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client, err := server.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	ctx := context.Background()
+
+	from, err := client.GetUserAPI().RegisterMobileUserContext(ctx, "86", "13800000000")
+	if err != nil {
+		t.Fatalf("RegisterMobileUserContext() error = %v", err)
+	}
+	to := server.CreateUser("bob@example.com")
+	server.SetBalance(from.Data.UID.Int64(), "BTC", decimal.RequireFromString("1"))
+
+	_, err = client.GetTransferAPI().AccountTransferContext(ctx, &api.TransferArgs{
+		RequestID: "t-1", FromUID: from.Data.UID.Int64(), ToUID: to, Symbol: "BTC", Amount: decimal.RequireFromString("0.25"),
+	})
+	if err != nil {
+		t.Fatalf("AccountTransferContext() error = %v", err)
+	}
+	transfers, err := client.GetTransferAPI().GetAccountTransferListContext(ctx, []string{"t-1"})
+	if err != nil || len(transfers.Data) != 1 || !transfers.Data[0].Amount.Equal(decimal.RequireFromString("0.25")) {
+		t.Fatalf("GetAccountTransferListContext() = %+v, %v", transfers, err)
+	}
+	if got := server.Balance(to, "BTC").NormalBalance; !got.Equal(decimal.RequireFromString("0.25")) {
+		t.Fatalf("receiver balance = %s, want 0.25", got)
+	}
+
+	users, err := client.GetUserAPI().SyncUserListContext(ctx, 0)
+	if err != nil || len(users.Data) != 2 {
+		t.Fatalf("SyncUserListContext() = %+v, %v", users, err)
+	}
+	coins, err := client.GetCoinAPI().GetCoinListContext(ctx)
+	if err != nil || len(coins.Data) != 2 {
+		t.Fatalf("GetCoinListContext() = %+v, %v", coins, err)
+	}
+	if got := server.Requests(); len(got) != 5 || got[1].Path != "/account/transfer" {
+		t.Fatalf("Requests() = %+v", got)
+	}
+}
+
+func TestServerBatchedLookup(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	config := server.Config()
+	config.LookupBatch = utils.BatchSettings{Size: 2, Concurrency: 2}
+	client, err := custody.NewWaasClient(config)
+	if err != nil {
+		t.Fatalf("NewWaasClient() error = %v", err)
+	}
+
+	uid := server.CreateUser("alice@example.com")
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		deposit, err := server.InjectDeposit(uid, "ETH", decimal.RequireFromString("1"))
+		if err != nil {
+			t.Fatalf("InjectDeposit() error = %v", err)
+		}
+		ids = append(ids, int64(deposit.ID))
+	}
+
+	lookup := append([]int64{ids[0], 999}, ids...)
+	deposits, err := client.GetBillingAPI().DepositListContext(context.Background(), lookup)
+	if err != nil {
+		t.Fatalf("DepositListContext() error = %v", err)
+	}
+	if len(deposits.Data) != 5 || len(deposits.NotFound) != 1 || deposits.NotFound[0] != 999 {
+		t.Fatalf("DepositListContext() = %d records, not found %v", len(deposits.Data), deposits.NotFound)
+	}
+	if n := len(server.Requests()); n != 3 {
+		t.Fatalf("requests = %d, want 3 batches of at most 2 IDs", n)
+	}
+
+	if _, err := client.GetBillingAPI().DepositListContext(context.Background(), nil); err == nil {
+		t.Fatalf("DepositListContext(nil) should fail")
+	}
+	if n := len(server.Requests()); n != 3 {
+		t.Fatalf("requests = %d after an empty lookup, want it rejected locally", n)
+	}
+}
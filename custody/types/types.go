@@ -148,7 +148,7 @@ type Withdraw struct {
 	FeeSymbol         string          `json:"fee_symbol"`
 	Id                FlexInt         `json:"id"`
 	RealFee           decimal.Decimal `json:"real_fee"`
-	RequestId         string          `json:"request_id"`
+	RequestId         string          `json:"-"` // Deprecated: Use RequestID; filled from the same field when decoding.
 	SaasStatus        FlexInt         `json:"saas_status"`
 	Status            int64           `json:"status"`
 	Symbol            string          `json:"symbol"`
@@ -160,6 +160,17 @@ type Withdraw struct {
 	WithdrawFeeSymbol string          `json:"withdraw_fee_symbol"`
 }
 
+// UnmarshalJSON decodes a withdrawal and mirrors RequestID into the
+// deprecated RequestId field.
+func (w *Withdraw) UnmarshalJSON(data []byte) error {
+	type plain Withdraw
+	if err := utils.DecodeJSON(data, (*plain)(w)); err != nil {
+		return err
+	}
+	w.RequestId = w.RequestID
+	return nil
+}
+
 // WithdrawResult represents withdrawal response.
 type WithdrawResult struct {
 	Code string `json:"code"`
@@ -174,6 +185,9 @@ type WithdrawListResult struct {
 	Code string      `json:"code"`
 	Msg  string      `json:"msg"`
 	Data []*Withdraw `json:"data"`
+
+	// NotFound lists the requested request IDs no record was returned for.
+	NotFound []string `json:"-"`
 }
 
 // -----------------------------------------------------------------------------
@@ -204,6 +218,9 @@ type DepositListResult struct {
 	Code string     `json:"code"`
 	Msg  string     `json:"msg"`
 	Data []*Deposit `json:"data"`
+
+	// NotFound lists the requested IDs no record was returned for.
+	NotFound []int64 `json:"-"`
 }
 
 // -----------------------------------------------------------------------------
@@ -234,6 +251,9 @@ type MinerFeeListResult struct {
 	Code string      `json:"code"`
 	Msg  string      `json:"msg"`
 	Data []*MinerFee `json:"data"`
+
+	// NotFound lists the requested IDs no record was returned for.
+	NotFound []int64 `json:"-"`
 }
 
 // -----------------------------------------------------------------------------
@@ -267,6 +287,9 @@ type TransferListResult struct {
 	Code string      `json:"code"`
 	Msg  string      `json:"msg"`
 	Data []*Transfer `json:"data"`
+
+	// NotFound lists the requested request IDs no record was returned for.
+	NotFound []string `json:"-"`
 }
 
 // -----------------------------------------------------------------------------
@@ -293,3 +316,9 @@ type AsyncNotifyArgs struct {
 	Confirmations     FlexInt         `json:"confirmations"`       // Number of confirmations
 	Status            FlexInt         `json:"status"`              // Status code
 }
+
+// AsyncNotifyArgs.Side values.
+const (
+	AsyncNotifySideDeposit  = "deposit"
+	AsyncNotifySideWithdraw = "withdraw"
+)
@@ -4,6 +4,8 @@ package types
 import (
 	"encoding/json"
 	"testing"
+
+	"chainup.com/go-sdk/utils"
 )
 
 func TestFlexInt_UnmarshalJSON(t *testing.T) {
@@ -198,3 +200,39 @@ func TestFlexInt_UserInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestWithdraw_LosslessDecode(t *testing.T) {
+	// Decrypted responses are decoded into a map first and then into the
+	// typed result, so numbers must survive both steps exactly.
+	decrypted := `{"code":"0","msg":"","data":[{"id":9007199254740993,"request_id":"r-1","amount":1.000000000000000001,"fee":0.000000000000000001,"uid":9007199254740995}]}`
+
+	var response map[string]interface{}
+	if err := utils.DecodeJSON([]byte(decrypted), &response); err != nil {
+		t.Fatalf("DecodeJSON() error = %v", err)
+	}
+
+	var result WithdrawListResult
+	if err := utils.Remarshal(response, &result); err != nil {
+		t.Fatalf("Remarshal() error = %v", err)
+	}
+	if len(result.Data) != 1 {
+		t.Fatalf("expected 1 withdraw, got %d", len(result.Data))
+	}
+
+	w := result.Data[0]
+	if w.Id.Int64() != 9007199254740993 {
+		t.Errorf("Expected id 9007199254740993, got %d", w.Id.Int64())
+	}
+	if w.Uid != 9007199254740995 {
+		t.Errorf("Expected uid 9007199254740995, got %d", w.Uid)
+	}
+	if w.Amount.String() != "1.000000000000000001" {
+		t.Errorf("Expected amount 1.000000000000000001, got %s", w.Amount.String())
+	}
+	if w.Fee.String() != "0.000000000000000001" {
+		t.Errorf("Expected fee 0.000000000000000001, got %s", w.Fee.String())
+	}
+	if w.RequestID != "r-1" || w.RequestId != "r-1" {
+		t.Errorf("Expected request_id r-1, got %q (deprecated field %q)", w.RequestID, w.RequestId)
+	}
+}
@@ -180,7 +180,7 @@ func main() {
 	if err != nil {
 		log.Printf("Failed to create Web3 transaction: %v", err)
 	} else if web3Result != nil {
-		fmt.Printf("Web3 transaction created: OrderID=%s\n", web3Result.Data.TransID)
+		fmt.Printf("Web3 transaction created: OrderID=%d\n", web3Result.Data.TransID)
 	} else {
 		fmt.Printf("Web3 transaction response: no data returned\n")
 	}
@@ -2,4 +2,4 @@ module chainup.com/go-sdk
 
 go 1.21
 
-require github.com/shopspring/decimal v1.3.1 // indirect
+require github.com/shopspring/decimal v1.3.1
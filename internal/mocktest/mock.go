@@ -0,0 +1,142 @@
+// Package mocktest provides the call recorder shared by the mocks of packages
+// custodymock and mpcmock.
+package mocktest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotProgrammed is returned by a mocked method that has no programmed
+// response.
+var ErrNotProgrammed = errors.New("mock method has no programmed response")
+
+// Call is a call recorded by a Mock. Method is the facade and method
+// name, e.g. "BillingAPI.Withdraw", and Args the call arguments without the
+// context.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Handler computes the response of a mocked method from its arguments.
+type Handler func(args ...interface{}) (interface{}, error)
+
+// mockResponse is a programmed response.
+type mockResponse struct {
+	result interface{}
+	err    error
+}
+
+// Mock records calls and serves programmed responses for the mock facades of
+// the custodymock and mpcmock packages. Methods are named like the endpoints,
+// "<Facade>.<Method>", and the plain and Context variants of a method share
+// one name. The zero value is ready to use, and a Mock is safe for
+// concurrent use.
+//
+// Responses queued with Return are served in order; once they are used up,
+// the handler set with Handle computes the response, or else the last
+// queued response is served again. A method with neither fails with
+// ErrNotProgrammed.
+type Mock struct {
+	mu       sync.Mutex
+	calls    []Call
+	queued   map[string][]mockResponse
+	last     map[string]mockResponse
+	handlers map[string]Handler
+}
+
+// Return queues a response for the next call to method. result must have the
+// method's result type, e.g. *types.WithdrawResult, or be nil.
+func (m *Mock) Return(method string, result interface{}, err error) *Mock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.queued == nil {
+		m.queued = make(map[string][]mockResponse)
+	}
+	m.queued[method] = append(m.queued[method], mockResponse{result: result, err: err})
+	return m
+}
+
+// Handle sets the handler computing the responses of method once its queued
+// responses are used up.
+func (m *Mock) Handle(method string, handler Handler) *Mock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.handlers == nil {
+		m.handlers = make(map[string]Handler)
+	}
+	m.handlers[method] = handler
+	return m
+}
+
+// Calls returns the calls recorded so far, in order.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls to method recorded so far, in order.
+func (m *Mock) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the recorded calls and the programmed responses.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls, m.queued, m.last, m.handlers = nil, nil, nil, nil
+}
+
+// Called records a call to method and returns its programmed response.
+func (m *Mock) Called(method string, args ...interface{}) (interface{}, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+	if queued := m.queued[method]; len(queued) > 0 {
+		m.queued[method] = queued[1:]
+		if m.last == nil {
+			m.last = make(map[string]mockResponse)
+		}
+		m.last[method] = queued[0]
+		m.mu.Unlock()
+		return queued[0].result, queued[0].err
+	}
+	handler, hasHandler := m.handlers[method]
+	last, hasLast := m.last[method]
+	m.mu.Unlock()
+
+	switch {
+	case hasHandler:
+		// Call the handler unlocked, so it can inspect the mock.
+		return handler(args...)
+	case hasLast:
+		return last.result, last.err
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrNotProgrammed, method)
+	}
+}
+
+// Called is Called with the result converted to the method's result type
+// T. A nil result is returned as the zero T.
+func Called[T any](m *Mock, method string, args ...interface{}) (T, error) {
+	var zero T
+	result, err := m.Called(method, args...)
+	if result == nil {
+		return zero, err
+	}
+	typed, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("mock %s: programmed result is %T, want %T", method, result, zero)
+	}
+	return typed, err
+}
@@ -0,0 +1,54 @@
+// Package mocktest provides the call recorder shared by the mocks of packages
+// custodymock and mpcmock.
+package mocktest
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMock(t *testing.T) {
+	errBoom := errors.New("boom")
+	mock := &Mock{}
+	mock.Return("API.Queued", "first", nil).Return("API.Queued", "second", errBoom)
+	mock.Handle("API.Handled", func(args ...interface{}) (interface{}, error) {
+		return args[0].(string) + "!", nil
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		arg     string
+		want    string
+		wantErr error
+	}{
+		{name: "first queued", method: "API.Queued", want: "first"},
+		{name: "second queued", method: "API.Queued", want: "second", wantErr: errBoom},
+		{name: "last repeated", method: "API.Queued", want: "second", wantErr: errBoom},
+		{name: "handler", method: "API.Handled", arg: "hi", want: "hi!"},
+		{name: "not programmed", method: "API.Missing", wantErr: ErrNotProgrammed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Called[string](mock, tt.method, tt.arg)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Called() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	mock.Return("API.Typed", 42, nil)
+	if _, err := Called[string](mock, "API.Typed"); err == nil {
+		t.Fatal("Called() with a mistyped result succeeded")
+	}
+	if got := mock.CallsTo("API.Queued"); len(got) != 3 {
+		t.Fatalf("CallsTo() = %+v", got)
+	}
+	if got := mock.Calls(); len(got) != 6 || got[3].Method != "API.Handled" || got[3].Args[0] != "hi" {
+		t.Fatalf("Calls() = %+v", got)
+	}
+	mock.Reset()
+	if got := mock.Calls(); len(got) != 0 {
+		t.Fatalf("Calls() after Reset = %+v", got)
+	}
+}
@@ -2,12 +2,14 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"github.com/shopspring/decimal"
 	"strconv"
 	"strings"
 
 	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
 )
 
 // AutoSweepAPI provides auto-sweep operations
@@ -26,13 +28,11 @@ func NewAutoSweepAPI(config MpcConfigProvider) *AutoSweepAPI {
 // walletIDs: List of wallet IDs
 // symbol: Coin symbol
 func (a *AutoSweepAPI) AutoCollectSubWallets(walletIDs []int64, symbol string) (*types.AutoCollectResult, error) {
-	if len(walletIDs) == 0 {
-		return nil, errors.New("parameter \"sub_wallet_ids\" is required")
-	}
-	if symbol == "" {
-		return nil, errors.New("parameter \"symbol\" is required")
-	}
+	return a.AutoCollectSubWalletsContext(context.Background(), walletIDs, symbol)
+}
 
+// AutoCollectSubWalletsContext is like AutoCollectSubWallets but carries ctx through to the HTTP request.
+func (a *AutoSweepAPI) AutoCollectSubWalletsContext(ctx context.Context, walletIDs []int64, symbol string) (*types.AutoCollectResult, error) {
 	// Convert wallet IDs to comma-separated string
 	idStrs := make([]string, len(walletIDs))
 	for i, id := range walletIDs {
@@ -44,33 +44,21 @@ func (a *AutoSweepAPI) AutoCollectSubWallets(walletIDs []int64, symbol string) (
 		"symbol":         symbol,
 	}
 
-	response, err := a.Post("/api/mpc/auto_collect/sub_wallets", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := a.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var collectResult types.AutoCollectResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &collectResult); err != nil {
-		return nil, err
-	}
-
-	return &collectResult, nil
+	return utils.CallEndpoint[types.AutoCollectResult](ctx, a.caller, epAutoCollectSubWallets, params)
 }
 
 // SetAutoCollectSymbol sets auto-collection symbol configuration
 // args: Auto collect symbol arguments (symbol, collectMin, fuelingLimit)
 func (a *AutoSweepAPI) SetAutoCollectSymbol(args *types.SetAutoCollectSymbolArgs) (bool, error) {
+	return a.SetAutoCollectSymbolContext(context.Background(), args)
+}
+
+// SetAutoCollectSymbolContext is like SetAutoCollectSymbol but carries ctx through to the HTTP request.
+func (a *AutoSweepAPI) SetAutoCollectSymbolContext(ctx context.Context, args *types.SetAutoCollectSymbolArgs) (bool, error) {
 	if args == nil {
 		return false, errors.New("args cannot be nil")
 	}
-	if args.Symbol == "" {
-		return false, errors.New("parameter \"symbol\" is required")
-	}
+
 	if args.CollectMin.LessThanOrEqual(decimal.Zero) {
 		return false, errors.New("parameter \"collect_min\" is required")
 	}
@@ -85,45 +73,24 @@ func (a *AutoSweepAPI) SetAutoCollectSymbol(args *types.SetAutoCollectSymbolArgs
 		"fueling_limit": args.FuelingLimit,
 	}
 
-	response, err := a.Post("/api/mpc/auto_collect/symbol/set", params)
-	if err != nil {
+	if _, err := utils.CallEndpoint[utils.ResponseStatus](ctx, a.caller, epSetAutoCollectSymbol, params); err != nil {
 		return false, err
 	}
 
-	result, err := a.ValidateResponse(response)
-	if err != nil {
-		return false, err
-	}
-
-	var collectResult types.AutoCollectResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &collectResult); err != nil {
-		return false, err
-	}
-
-	return collectResult.Code == "0", nil
+	return true, nil
 }
 
 // SyncAutoCollectRecords syncs auto-collection records
 // maxID: Starting record ID, default is 0
 func (a *AutoSweepAPI) SyncAutoCollectRecords(maxID int64) (*types.AutoCollectRecordResult, error) {
+	return a.SyncAutoCollectRecordsContext(context.Background(), maxID)
+}
+
+// SyncAutoCollectRecordsContext is like SyncAutoCollectRecords but carries ctx through to the HTTP request.
+func (a *AutoSweepAPI) SyncAutoCollectRecordsContext(ctx context.Context, maxID int64) (*types.AutoCollectRecordResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := a.Get("/api/mpc/billing/sync_auto_collect_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := a.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var recordResult types.AutoCollectRecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &recordResult); err != nil {
-		return nil, err
-	}
-
-	return &recordResult, nil
+	return utils.CallEndpoint[types.AutoCollectRecordResult](ctx, a.caller, epSyncAutoCollectRecords, params)
 }
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"chainup.com/go-sdk/utils"
+	"chainup.com/go-sdk/utils/sdkerrors"
 )
 
 // MpcBaseAPI provides common functionality for all MPC API implementations.
@@ -14,31 +18,114 @@ type MpcBaseAPI struct {
 	config         MpcConfigProvider
 	httpClient     *utils.MpcHTTPClient
 	cryptoProvider utils.CryptoProvider
+	logger         *slog.Logger
+	redactor       *utils.Redactor
+	tracer         utils.Tracer
+	metrics        utils.Collector
+	cassette       *utils.Cassette
+	// strict rejects success responses not decrypted with the public key.
+	strict bool
+	// caller executes the typed endpoint calls.
+	caller *utils.Caller
 }
 
 // NewMpcBaseAPI creates a new MpcBaseAPI instance.
 func NewMpcBaseAPI(config MpcConfigProvider) *MpcBaseAPI {
-	return &MpcBaseAPI{
+	logger := utils.ResolveLogger(config.GetLogger(), config.IsDebug())
+	redactor := config.GetRedactor()
+	if redactor == nil {
+		redactor = utils.NewRedactor()
+	}
+	m := &MpcBaseAPI{
 		config: config,
 		httpClient: utils.NewMpcHTTPClient(
 			config.GetDomain(),
 			config.GetAppID(),
 			config.GetApiKey(),
-			utils.DefaultTimeout,
+			config.GetTimeout(),
 			config.IsDebug(),
+			utils.WithRetryPolicy(config.GetRetryPolicy()),
+			utils.WithHTTPClient(config.GetHTTPClient()),
+			utils.WithTransport(transportOf(config)),
+			utils.WithMiddleware(config.GetMiddlewares()...),
+			utils.WithLogger(logger),
+			utils.WithRedactor(redactor),
+			utils.WithRateLimiter(config.GetRateLimiter()),
+			utils.WithCircuitBreaker(config.GetCircuitBreaker()),
+			utils.WithFailoverHosts(config.GetFailoverDomains()...),
 		),
 		cryptoProvider: config.GetCryptoProvider(),
+		logger:         logger,
+		redactor:       redactor,
+		tracer:         config.GetTracer(),
+		metrics:        config.GetMetrics(),
+		cassette:       config.GetCassette(),
+		strict:         !config.GetAllowUnverifiedResponses(),
+	}
+	m.caller = &utils.Caller{Invoke: m.invoke, StartSpan: m.startSpan, Tracer: m.tracer, Batch: config.GetLookupBatch()}
+	return m
+}
+
+// transportOf returns the transport of config, or the shared default transport
+// when config sets neither a Transport nor an HTTPClient.
+func transportOf(config MpcConfigProvider) http.RoundTripper {
+	if transport := config.GetTransport(); transport != nil || config.GetHTTPClient() != nil {
+		return transport
 	}
+	return utils.SharedTransport()
 }
 
 // Post executes a POST request to the specified path with the given data.
 func (m *MpcBaseAPI) Post(path string, data map[string]interface{}) (map[string]interface{}, error) {
-	return m.executeRequest(utils.HTTPMethodPost, path, data)
+	return m.executeRequest(context.Background(), utils.HTTPMethodPost, path, data)
 }
 
 // Get executes a GET request to the specified path with the given data.
 func (m *MpcBaseAPI) Get(path string, data map[string]interface{}) (map[string]interface{}, error) {
-	return m.executeRequest(utils.HTTPMethodGet, path, data)
+	return m.executeRequest(context.Background(), utils.HTTPMethodGet, path, data)
+}
+
+// PostContext executes a POST request bound to ctx.
+func (m *MpcBaseAPI) PostContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return m.executeRequest(ctx, utils.HTTPMethodPost, path, data)
+}
+
+// GetContext executes a GET request bound to ctx.
+func (m *MpcBaseAPI) GetContext(ctx context.Context, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	return m.executeRequest(ctx, utils.HTTPMethodGet, path, data)
+}
+
+// RawCall executes an endpoint the SDK does not wrap yet. path is relative to the domain, e.g. "/api/mpc/billing/withdraw_list".
+// params are encrypted and sent exactly like the built-in methods do, with the
+// same logging and error handling; a non-zero response code is returned as
+// *sdkerrors.APIError. POST calls are only retried when params carry a request_id.
+func (m *MpcBaseAPI) RawCall(ctx context.Context, method, path string, params map[string]interface{}) (*utils.RawResponse, error) {
+	switch method {
+	case utils.HTTPMethodGet:
+	case utils.HTTPMethodPost:
+		ctx = utils.WithIdempotencyKey(ctx, utils.StringField(params, "request_id"))
+	default:
+		return nil, fmt.Errorf("unsupported HTTP method %q", method)
+	}
+
+	// The request args are extended with common parameters, so work on a copy.
+	data := make(map[string]interface{}, len(params)+2)
+	for key, value := range params {
+		data[key] = value
+	}
+
+	ctx, span := m.startSpan(ctx, "RawCall", path, data)
+	body, err := m.invoke(ctx, method, path, data)
+	utils.EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.RawResponse{Body: body}, nil
+}
+
+// HostStats returns the health of every domain the API sends requests to.
+func (m *MpcBaseAPI) HostStats() []utils.HostStats {
+	return m.httpClient.HostStats()
 }
 
 // ValidateResponse validates response and handles errors.
@@ -47,44 +134,128 @@ func (m *MpcBaseAPI) ValidateResponse(response map[string]interface{}) (interfac
 	return ValidateResponse(response)
 }
 
-// executeRequest executes an MPC API request with encryption and decryption.
-func (m *MpcBaseAPI) executeRequest(method, path string, data map[string]interface{}) (map[string]interface{}, error) {
+// executeRequest executes an MPC API request and decodes the decrypted
+// response into a map. Typed methods use utils.CallEndpoint instead, which
+// skips the map.
+func (m *MpcBaseAPI) executeRequest(ctx context.Context, method, path string, data map[string]interface{}) (response map[string]interface{}, err error) {
+	// A raw POST may move money, so it is only retried when it carries a request_id.
+	if _, ok := utils.CallInfoFromContext(ctx); !ok && method == utils.HTTPMethodPost {
+		ctx = utils.WithIdempotencyKey(ctx, utils.StringField(data, "request_id"))
+	}
+
+	ctx, span := m.startSpan(ctx, "Request", path, data)
+	defer func() { utils.EndSpan(span, err) }()
+
+	body, err := m.invoke(ctx, method, path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	_, decodeSpan := utils.StartSpan(ctx, m.tracer, utils.SpanDecode)
+	err = utils.DecodeJSON(body, &response)
+	utils.EndSpan(decodeSpan, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted data: %w", err)
+	}
+	return response, nil
+}
+
+// startSpan starts the span of one logical API call named after the API method.
+func (m *MpcBaseAPI) startSpan(ctx context.Context, name, path string, params map[string]interface{}) (context.Context, utils.Span) {
+	return utils.StartCallSpan(ctx, m.tracer, "mpc."+name, path, m.config.GetAppID(), params)
+}
+
+// invoke executes an MPC API request with encryption and decryption and
+// returns the decrypted response body. The HTTP round trip is bound to ctx.
+// Every call is summarized in one log line. A non-zero response code is
+// returned as *sdkerrors.APIError carrying the endpoint and request_id of the call.
+func (m *MpcBaseAPI) invoke(ctx context.Context, method, path string, data map[string]interface{}) ([]byte, error) {
+	start := time.Now()
+	finish := utils.TrackCall(ctx, m.metrics, "mpc", path)
+	var (
+		body   []byte
+		status utils.ResponseStatus
+	)
+	err := m.httpClient.RetryRateLimited(ctx, m.config.GetAppID(), func() (err error) {
+		status = utils.ResponseStatus{}
+		body, err = m.cassette.Do(ctx, "mpc", method, path, data, func() ([]byte, error) {
+			return m.doRequest(ctx, method, path, data)
+		})
+		if err != nil {
+			return err
+		}
+		if decodeErr := utils.DecodeJSON(body, &status); decodeErr != nil {
+			return fmt.Errorf("invalid JSON response: %w", decodeErr)
+		}
+		if apiErr := sdkerrors.FromCode(status.Code, status.Msg); apiErr != nil {
+			apiErr.Endpoint = path
+			apiErr.RequestID = utils.StringField(data, "request_id")
+			return apiErr
+		}
+		return nil
+	})
+
+	call := utils.APICall{
+		Service:   "mpc",
+		Path:      path,
+		AppID:     m.config.GetAppID(),
+		RequestID: utils.StringField(data, "request_id"),
+		Code:      status.CodeString(),
+		Latency:   time.Since(start),
+		Err:       err,
+	}
+	utils.LogAPICall(ctx, utils.CallLogger(ctx, m.logger), call)
+	if code := status.CodeString(); code != "" {
+		utils.SpanFromContext(ctx).SetAttribute("code", code)
+	}
+	finish(status.CodeString(), err)
+
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// doRequest encrypts data, sends it and returns the decrypted response body.
+func (m *MpcBaseAPI) doRequest(ctx context.Context, method, path string, data map[string]interface{}) ([]byte, error) {
 	// Build and encrypt request
-	encryptedData, err := m.buildEncryptedRequest(data)
+	encryptedData, err := m.buildEncryptedRequest(ctx, path, data)
 	if err != nil {
 		return nil, err
 	}
 
 	// Send request
-	response, err := m.sendRequest(method, path, encryptedData)
+	response, err := m.sendRequest(ctx, method, path, encryptedData)
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse and decrypt response
-	return m.parseResponse(response)
+	// Decrypt response
+	return m.decryptResponse(ctx, path, response)
 }
 
 // buildEncryptedRequest builds and encrypts the request data.
-func (m *MpcBaseAPI) buildEncryptedRequest(data map[string]interface{}) (string, error) {
+func (m *MpcBaseAPI) buildEncryptedRequest(ctx context.Context, path string, data map[string]interface{}) (string, error) {
 	rawJSON, err := m.buildRequestArgs(data)
 	if err != nil {
 		return "", err
 	}
 
-	m.debugLog("[MPC Request Args]: %s", rawJSON)
+	if logger := utils.CallLogger(ctx, m.logger); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "mpc request args", "path", path, "args", m.redactor.RedactJSON(rawJSON))
+	}
 
 	if m.cryptoProvider == nil {
 		return "", nil
 	}
 
+	_, span := utils.StartSpan(ctx, m.tracer, utils.SpanEncrypt)
 	encrypted, err := m.cryptoProvider.EncryptWithPrivateKey(rawJSON)
+	utils.EndSpan(span, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to encrypt request data: %w", err)
 	}
 
-	m.debugLogTruncated("[MPC Encrypted Data]", encrypted, 100)
-
 	return encrypted, nil
 }
 
@@ -106,77 +277,48 @@ func (m *MpcBaseAPI) buildRequestArgs(data map[string]interface{}) (string, erro
 }
 
 // sendRequest sends the HTTP request.
-func (m *MpcBaseAPI) sendRequest(method, path, encryptedData string) (string, error) {
+func (m *MpcBaseAPI) sendRequest(ctx context.Context, method, path, encryptedData string) (string, error) {
 	requestData := map[string]interface{}{
 		"data":   encryptedData,
 		"app_id": m.config.GetAppID(),
 	}
 
+	ctx, span := utils.StartSpan(ctx, m.tracer, utils.SpanHTTP)
 	var response string
 	var err error
+	defer func() { utils.EndSpan(span, err) }()
 
 	switch method {
 	case utils.HTTPMethodPost:
-		response, err = m.httpClient.Post(path, requestData)
+		response, err = m.httpClient.PostContext(ctx, path, requestData)
 	default:
-		response, err = m.httpClient.Get(path, requestData)
+		response, err = m.httpClient.GetContext(ctx, path, requestData)
 	}
 
 	if err != nil {
 		return "", err
 	}
 
-	m.debugLog("[MPC Response]: %s", response)
-
 	return response, nil
 }
 
-// parseResponse parses and decrypts the response.
-func (m *MpcBaseAPI) parseResponse(response string) (map[string]interface{}, error) {
-	var parsed map[string]interface{}
-	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
-		return nil, fmt.Errorf("invalid JSON response: %w", err)
-	}
-
-	// Check if response has encrypted data field
-	dataField, ok := parsed["data"].(string)
-	if !ok || dataField == "" || m.cryptoProvider == nil {
-		return parsed, nil
-	}
-
-	// Decrypt the data
-	decrypted, err := m.cryptoProvider.DecryptWithPublicKey(dataField)
+// decryptResponse returns the decrypted response body. A response that
+// carries no encrypted data or cannot be decrypted is returned as-is when it
+// is an error response, and rejected in strict mode when it reports success.
+func (m *MpcBaseAPI) decryptResponse(ctx context.Context, path, response string) ([]byte, error) {
+	_, span := utils.StartSpan(ctx, m.tracer, utils.SpanDecrypt)
+	body, decrypted, err := utils.OpenEnvelope([]byte(response), m.cryptoProvider)
+	utils.EndSpan(span, err)
 	if err != nil {
-		m.debugLog("[MPC Decrypt Error]: %v", err)
-		return parsed, nil
+		utils.CallLogger(ctx, m.logger).WarnContext(ctx, "mpc response decryption failed", "path", path, "error", err)
 	}
-
-	m.debugLog("[MPC Decrypted]: %s", decrypted)
-
-	// Parse decrypted JSON
-	var decryptedResponse map[string]interface{}
-	if err := json.Unmarshal([]byte(decrypted), &decryptedResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse decrypted data: %w", err)
+	if !decrypted {
+		return body, utils.CheckAuthenticity(ctx, m.metrics, "mpc", path, body, err, m.strict)
 	}
 
-	return decryptedResponse, nil
-}
-
-// debugLog logs a message if debug mode is enabled.
-func (m *MpcBaseAPI) debugLog(format string, args ...interface{}) {
-	if m.config.IsDebug() {
-		fmt.Printf(format+"\n", args...)
+	if logger := utils.CallLogger(ctx, m.logger); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "mpc response decrypted", "path", path, "body", m.redactor.RedactJSON(string(body)))
 	}
-}
 
-// debugLogTruncated logs a truncated message if debug mode is enabled.
-func (m *MpcBaseAPI) debugLogTruncated(prefix, value string, maxLen int) {
-	if !m.config.IsDebug() {
-		return
-	}
-	if len(value) > maxLen {
-		fmt.Printf("%s: %s...\n", prefix, value[:maxLen])
-	} else {
-		fmt.Printf("%s: %s\n", prefix, value)
-	}
+	return body, nil
 }
@@ -3,6 +3,8 @@ package api
 
 import (
 	"crypto/rsa"
+	"log/slog"
+	"net/http"
 
 	"chainup.com/go-sdk/utils"
 )
@@ -23,9 +25,54 @@ type MpcConfigProvider interface {
 	// IsDebug returns whether debug mode is enabled.
 	IsDebug() bool
 
+	// GetTimeout returns the HTTP request timeout in seconds.
+	GetTimeout() int
+
 	// GetCryptoProvider returns the crypto provider for encryption/decryption.
 	GetCryptoProvider() utils.CryptoProvider
 
 	// GetSignPrivateKey returns the private key for transaction signing.
 	GetSignPrivateKey() *rsa.PrivateKey
+
+	// GetRetryPolicy returns the retry policy for transient failures (may be nil).
+	GetRetryPolicy() *utils.RetryPolicy
+
+	// GetHTTPClient returns a custom HTTP client (may be nil).
+	GetHTTPClient() *http.Client
+
+	// GetTransport returns a custom HTTP transport (may be nil).
+	GetTransport() http.RoundTripper
+
+	// GetMiddlewares returns the HTTP middleware chain.
+	GetMiddlewares() []utils.Middleware
+
+	// GetLogger returns the structured logger (may be nil).
+	GetLogger() *slog.Logger
+
+	// GetRedactor returns the redactor applied to logged payloads (may be nil).
+	GetRedactor() *utils.Redactor
+
+	// GetRateLimiter returns the client-side rate limiter (may be nil).
+	GetRateLimiter() *utils.RateLimiter
+
+	// GetCircuitBreaker returns the circuit breaker (may be nil).
+	GetCircuitBreaker() *utils.CircuitBreaker
+
+	// GetFailoverDomains returns the domains tried after GetDomain (may be empty).
+	GetFailoverDomains() []string
+
+	// GetTracer returns the tracer (may be nil).
+	GetTracer() utils.Tracer
+
+	// GetMetrics returns the metrics collector (may be nil).
+	GetMetrics() utils.Collector
+
+	// GetCassette returns the cassette (may be nil).
+	GetCassette() *utils.Cassette
+
+	// GetAllowUnverifiedResponses reports whether strict authenticity mode is disabled.
+	GetAllowUnverifiedResponses() bool
+
+	// GetLookupBatch returns the batching of lookups by ID lists.
+	GetLookupBatch() utils.BatchSettings
 }
@@ -0,0 +1,68 @@
+// Package api provides MPC API implementations
+package api
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// NewWithdrawRecordCursor iterates over all withdrawal records with SyncWithdrawRecords.
+func NewWithdrawRecordCursor(ctx context.Context, withdraws WithdrawService, opts ...utils.CursorOption) *utils.Cursor[*types.WithdrawRecord] {
+	return utils.NewCursor(ctx, withdrawRecordPages(withdraws), withdrawRecordID, opts...)
+}
+
+// NewDepositRecordCursor iterates over all deposit records with SyncDepositRecords.
+func NewDepositRecordCursor(ctx context.Context, deposits DepositService, opts ...utils.CursorOption) *utils.Cursor[*types.DepositRecord] {
+	return utils.NewCursor(ctx, depositRecordPages(deposits), depositRecordID, opts...)
+}
+
+// NewWeb3RecordCursor iterates over all Web3 transaction records with SyncWeb3Records.
+func NewWeb3RecordCursor(ctx context.Context, web3 Web3Service, opts ...utils.CursorOption) *utils.Cursor[*types.Web3TransRecord] {
+	return utils.NewCursor(ctx, web3RecordPages(web3), web3RecordID, opts...)
+}
+
+// NewAutoCollectRecordCursor iterates over all auto-collect records with SyncAutoCollectRecords.
+func NewAutoCollectRecordCursor(ctx context.Context, sweeps AutoSweepService, opts ...utils.CursorOption) *utils.Cursor[*types.AutoCollectRecord] {
+	return utils.NewCursor(ctx, autoCollectRecordPages(sweeps), autoCollectRecordID, opts...)
+}
+
+// NewBuyResourceRecordCursor iterates over all Tron resource purchase records
+// with SyncBuyResourceRecords.
+func NewBuyResourceRecordCursor(ctx context.Context, tron TronResourceService, opts ...utils.CursorOption) *utils.Cursor[*types.TronBuyResourceRecord] {
+	return utils.NewCursor(ctx, buyResourceRecordPages(tron), buyResourceRecordID, opts...)
+}
+
+func withdrawRecordPages(withdraws WithdrawService) utils.PageFunc[*types.WithdrawRecord] {
+	return utils.PageOf(withdraws.SyncWithdrawRecordsContext, func(r *types.WithdrawRecordResult) []*types.WithdrawRecord { return r.Data })
+}
+
+func withdrawRecordID(w *types.WithdrawRecord) int64 { return w.ID }
+
+func depositRecordPages(deposits DepositService) utils.PageFunc[*types.DepositRecord] {
+	return utils.PageOf(deposits.SyncDepositRecordsContext, func(r *types.DepositRecordResult) []*types.DepositRecord { return r.Data })
+}
+
+func depositRecordID(d *types.DepositRecord) int64 { return d.ID }
+
+func web3RecordPages(web3 Web3Service) utils.PageFunc[*types.Web3TransRecord] {
+	return utils.PageOf(web3.SyncWeb3RecordsContext, func(r *types.Web3RecordResult) []*types.Web3TransRecord { return r.Data })
+}
+
+func web3RecordID(w *types.Web3TransRecord) int64 { return w.ID }
+
+func autoCollectRecordPages(sweeps AutoSweepService) utils.PageFunc[*types.AutoCollectRecord] {
+	return utils.PageOf(sweeps.SyncAutoCollectRecordsContext, func(r *types.AutoCollectRecordResult) []*types.AutoCollectRecord { return r.Data })
+}
+
+func autoCollectRecordID(a *types.AutoCollectRecord) int64 { return a.ID }
+
+func buyResourceRecordPages(tron TronResourceService) utils.PageFunc[*types.TronBuyResourceRecord] {
+	sync := func(ctx context.Context, maxID int64) (*types.TronBuyResourceRecordResult, error) {
+		return tron.SyncBuyResourceRecordsContext(ctx, int(maxID))
+	}
+	return utils.PageOf(sync, func(r *types.TronBuyResourceRecordResult) []*types.TronBuyResourceRecord { return r.Data })
+}
+
+func buyResourceRecordID(t *types.TronBuyResourceRecord) int64 { return int64(t.ID) }
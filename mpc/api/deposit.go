@@ -2,11 +2,11 @@
 package api
 
 import (
+	"context"
 	"errors"
-	"strconv"
-	"strings"
 
 	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
 )
 
 // DepositAPI provides deposit record operations
@@ -22,61 +22,37 @@ func NewDepositAPI(config MpcConfigProvider) *DepositAPI {
 }
 
 // GetDepositRecords gets deposit records by IDs
-// ids: List of deposit IDs (up to 100)
+// ids: List of deposit IDs; long lists are split into parallel requests of
+// up to 100 IDs, see Config.LookupBatch. IDs without a record are listed in NotFound.
 func (d *DepositAPI) GetDepositRecords(ids []int64) (*types.DepositRecordResult, error) {
+	return d.GetDepositRecordsContext(context.Background(), ids)
+}
+
+// GetDepositRecordsContext is like GetDepositRecords but carries ctx through to the HTTP request.
+func (d *DepositAPI) GetDepositRecordsContext(ctx context.Context, ids []int64) (*types.DepositRecordResult, error) {
 	if len(ids) == 0 {
 		return nil, errors.New("parameter \"ids\" is required and must be a non-empty array")
 	}
 
-	// Convert IDs to comma-separated string
-	idStrs := make([]string, len(ids))
-	for i, id := range ids {
-		idStrs[i] = strconv.FormatInt(id, 10)
-	}
-
-	params := map[string]interface{}{
-		"ids": strings.Join(idStrs, ","),
-	}
-
-	response, err := d.Get("/api/mpc/billing/deposit_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := d.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var depositResult types.DepositRecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &depositResult); err != nil {
-		return nil, err
+	result, notFound, err := utils.LookupByIDs(ctx, d.caller, epGetDepositRecords, ids,
+		func(r *types.DepositRecordResult) *[]*types.DepositRecord { return &r.Data }, depositRecordID)
+	if result != nil {
+		result.NotFound = notFound
 	}
-
-	return &depositResult, nil
+	return result, err
 }
 
 // SyncDepositRecords syncs deposit records by max ID
 // maxID: Deposit record initial ID, default is 0
 func (d *DepositAPI) SyncDepositRecords(maxID int64) (*types.DepositRecordResult, error) {
+	return d.SyncDepositRecordsContext(context.Background(), maxID)
+}
+
+// SyncDepositRecordsContext is like SyncDepositRecords but carries ctx through to the HTTP request.
+func (d *DepositAPI) SyncDepositRecordsContext(ctx context.Context, maxID int64) (*types.DepositRecordResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := d.Get("/api/mpc/billing/sync_deposit_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := d.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var depositResult types.DepositRecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &depositResult); err != nil {
-		return nil, err
-	}
-
-	return &depositResult, nil
+	return utils.CallEndpoint[types.DepositRecordResult](ctx, d.caller, epSyncDepositRecords, params)
 }
@@ -0,0 +1,154 @@
+// Package api provides MPC API implementations.
+package api
+
+import (
+	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// MPC endpoint descriptors. Every typed API method executes through one of
+// these entries; see Endpoints for the full table.
+var (
+	epCreateWallet = &utils.Endpoint{
+		Name: "WalletAPI.CreateWallet", Method: utils.HTTPMethodPost, Path: "/api/mpc/sub_wallet/create", Group: utils.GroupMetadata,
+		NonIdempotent: true,
+		Result:        utils.ResultType[types.WalletCreateResult](),
+	}
+	epCreateWalletAddress = &utils.Endpoint{
+		Name: "WalletAPI.CreateWalletAddress", Method: utils.HTTPMethodPost, Path: "/api/mpc/sub_wallet/create/address", Group: utils.GroupMetadata,
+		Required: []string{"sub_wallet_id", "symbol"}, NonIdempotent: true,
+		Result: utils.ResultType[types.WalletAddressResult](),
+	}
+	epQueryWalletAddress = &utils.Endpoint{
+		Name: "WalletAPI.QueryWalletAddress", Method: utils.HTTPMethodPost, Path: "/api/mpc/sub_wallet/get/address/list", Group: utils.GroupMetadata,
+		Required: []string{"sub_wallet_id", "symbol"},
+		Result:   utils.ResultType[types.WalletAddressListResult](),
+	}
+	epGetWalletAssets = &utils.Endpoint{
+		Name: "WalletAPI.GetWalletAssets", Method: utils.HTTPMethodGet, Path: "/api/mpc/sub_wallet/assets", Group: utils.GroupMetadata,
+		Required: []string{"sub_wallet_id", "symbol"},
+		Result:   utils.ResultType[types.WalletAssetsResult](),
+	}
+	epChangeWalletShowStatus = &utils.Endpoint{
+		Name: "WalletAPI.ChangeWalletShowStatus", Method: utils.HTTPMethodPost, Path: "/api/mpc/sub_wallet/change_show_status", Group: utils.GroupMetadata,
+		Required: []string{"sub_wallet_ids"},
+		Result:   utils.ResultType[utils.ResponseStatus](),
+	}
+	epWalletAddressInfo = &utils.Endpoint{
+		Name: "WalletAPI.WalletAddressInfo", Method: utils.HTTPMethodGet, Path: "/api/mpc/sub_wallet/address/info", Group: utils.GroupMetadata,
+		Required: []string{"address"},
+		Result:   utils.ResultType[types.WalletAddressInfoResult](),
+	}
+
+	epGetSupportMainChain = &utils.Endpoint{
+		Name: "WorkSpaceAPI.GetSupportMainChain", Method: utils.HTTPMethodGet, Path: "/api/mpc/wallet/open_coin", Group: utils.GroupMetadata,
+		Result: utils.ResultType[types.SupportMainChainResult](),
+	}
+	epGetCoinDetails = &utils.Endpoint{
+		Name: "WorkSpaceAPI.GetCoinDetails", Method: utils.HTTPMethodGet, Path: "/api/mpc/coin_list", Group: utils.GroupMetadata,
+		Result: utils.ResultType[types.CoinDetailsResult](),
+	}
+	epGetLastBlockHeight = &utils.Endpoint{
+		Name: "WorkSpaceAPI.GetLastBlockHeight", Method: utils.HTTPMethodGet, Path: "/api/mpc/chain_height", Group: utils.GroupMetadata,
+		Required: []string{"base_symbol"},
+		Result:   utils.ResultType[types.BlockHeightResult](),
+	}
+
+	epGetDepositRecords = &utils.Endpoint{
+		Name: "DepositAPI.GetDepositRecords", Method: utils.HTTPMethodGet, Path: "/api/mpc/billing/deposit_list", Group: utils.GroupMetadata,
+		Required: []string{"ids"},
+		Result:   utils.ResultType[types.DepositRecordResult](),
+	}
+	epSyncDepositRecords = &utils.Endpoint{
+		Name: "DepositAPI.SyncDepositRecords", Method: utils.HTTPMethodGet, Path: "/api/mpc/billing/sync_deposit_list", Group: utils.GroupSync,
+		Result: utils.ResultType[types.DepositRecordResult](),
+	}
+
+	epWithdraw = &utils.Endpoint{
+		Name: "WithdrawAPI.Withdraw", Method: utils.HTTPMethodPost, Path: "/api/mpc/billing/withdraw", Group: utils.GroupMoneyMoving,
+		Required:       []string{"sub_wallet_id", "symbol", "amount", "address_to"},
+		IdempotencyKey: "request_id",
+		Result:         utils.ResultType[types.WithdrawResponse](),
+	}
+	epGetWithdrawRecords = &utils.Endpoint{
+		Name: "WithdrawAPI.GetWithdrawRecords", Method: utils.HTTPMethodGet, Path: "/api/mpc/billing/withdraw_list", Group: utils.GroupMetadata,
+		Required: []string{"ids"},
+		Result:   utils.ResultType[types.WithdrawRecordResult](),
+	}
+	epSyncWithdrawRecords = &utils.Endpoint{
+		Name: "WithdrawAPI.SyncWithdrawRecords", Method: utils.HTTPMethodGet, Path: "/api/mpc/billing/sync_withdraw_list", Group: utils.GroupSync,
+		Result: utils.ResultType[types.WithdrawRecordResult](),
+	}
+
+	epCreateWeb3Trans = &utils.Endpoint{
+		Name: "Web3API.CreateWeb3Trans", Method: utils.HTTPMethodPost, Path: "/api/mpc/web3/trans/create", Group: utils.GroupMoneyMoving,
+		Required:       []string{"sub_wallet_id", "main_chain_symbol", "interactive_contract"},
+		IdempotencyKey: "request_id",
+		Result:         utils.ResultType[types.Web3TransResponse](),
+	}
+	epAccelerationWeb3Trans = &utils.Endpoint{
+		Name: "Web3API.AccelerationWeb3Trans", Method: utils.HTTPMethodPost, Path: "/api/mpc/web3/pending", Group: utils.GroupMoneyMoving,
+		Required: []string{"trans_id"}, NonIdempotent: true,
+		Result: utils.ResultType[utils.ResponseStatus](),
+	}
+	epGetWeb3Records = &utils.Endpoint{
+		Name: "Web3API.GetWeb3Records", Method: utils.HTTPMethodGet, Path: "/api/mpc/web3/trans_list", Group: utils.GroupMetadata,
+		Required: []string{"ids"},
+		Result:   utils.ResultType[types.Web3RecordResult](),
+	}
+	epSyncWeb3Records = &utils.Endpoint{
+		Name: "Web3API.SyncWeb3Records", Method: utils.HTTPMethodGet, Path: "/api/mpc/web3/sync_trans_list", Group: utils.GroupSync,
+		Result: utils.ResultType[types.Web3RecordResult](),
+	}
+
+	epAutoCollectSubWallets = &utils.Endpoint{
+		Name: "AutoSweepAPI.AutoCollectSubWallets", Method: utils.HTTPMethodPost, Path: "/api/mpc/auto_collect/sub_wallets", Group: utils.GroupMoneyMoving,
+		Required: []string{"sub_wallet_ids", "symbol"}, NonIdempotent: true,
+		Result: utils.ResultType[types.AutoCollectResult](),
+	}
+	epSetAutoCollectSymbol = &utils.Endpoint{
+		Name: "AutoSweepAPI.SetAutoCollectSymbol", Method: utils.HTTPMethodPost, Path: "/api/mpc/auto_collect/symbol/set", Group: utils.GroupMetadata,
+		Required: []string{"symbol"},
+		Result:   utils.ResultType[utils.ResponseStatus](),
+	}
+	epSyncAutoCollectRecords = &utils.Endpoint{
+		Name: "AutoSweepAPI.SyncAutoCollectRecords", Method: utils.HTTPMethodGet, Path: "/api/mpc/billing/sync_auto_collect_list", Group: utils.GroupSync,
+		Result: utils.ResultType[types.AutoCollectRecordResult](),
+	}
+
+	epCreateTronDelegate = &utils.Endpoint{
+		Name: "TronResourceAPI.CreateTronDelegate", Method: utils.HTTPMethodPost, Path: "/api/mpc/tron/delegate", Group: utils.GroupMoneyMoving,
+		Required:       []string{"request_id", "address_from", "service_charge_type"},
+		IdempotencyKey: "request_id",
+		Result:         utils.ResultType[types.TronBuyResourceResult](),
+	}
+	epGetBuyResourceRecords = &utils.Endpoint{
+		Name: "TronResourceAPI.GetBuyResourceRecords", Method: utils.HTTPMethodPost, Path: "/api/mpc/tron/delegate/trans_list", Group: utils.GroupMetadata,
+		Required: []string{"ids"},
+		Result:   utils.ResultType[types.TronBuyResourceRecordResult](),
+	}
+	epSyncBuyResourceRecords = &utils.Endpoint{
+		Name: "TronResourceAPI.SyncBuyResourceRecords", Method: utils.HTTPMethodPost, Path: "/api/mpc/tron/delegate/sync_trans_list", Group: utils.GroupSync,
+		Result: utils.ResultType[types.TronBuyResourceRecordResult](),
+	}
+)
+
+// endpoints lists every MPC endpoint wrapped by the SDK.
+var endpoints = []*utils.Endpoint{
+	epCreateWallet, epCreateWalletAddress, epQueryWalletAddress, epGetWalletAssets, epChangeWalletShowStatus, epWalletAddressInfo,
+	epGetSupportMainChain, epGetCoinDetails, epGetLastBlockHeight,
+	epGetDepositRecords, epSyncDepositRecords,
+	epWithdraw, epGetWithdrawRecords, epSyncWithdrawRecords,
+	epCreateWeb3Trans, epAccelerationWeb3Trans, epGetWeb3Records, epSyncWeb3Records,
+	epAutoCollectSubWallets, epSetAutoCollectSymbol, epSyncAutoCollectRecords,
+	epCreateTronDelegate, epGetBuyResourceRecords, epSyncBuyResourceRecords,
+}
+
+// Endpoints returns a copy of the MPC endpoint table.
+func Endpoints() []utils.Endpoint {
+	table := make([]utils.Endpoint, len(endpoints))
+	for i, ep := range endpoints {
+		table[i] = *ep
+	}
+	return table
+}
@@ -2,26 +2,17 @@ package api
 
 import (
 	"errors"
-	"fmt"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
 )
 
 // ResponseError represents an API error response.
-type ResponseError struct {
-	Code    int
-	Message string
-}
-
-// Error implements the error interface.
-func (e *ResponseError) Error() string {
-	return fmt.Sprintf("API Error [%d]: %s", e.Code, e.Message)
-}
+// It is an alias of sdkerrors.APIError shared by the WaaS and MPC clients.
+type ResponseError = sdkerrors.APIError
 
 // NewResponseError creates a new ResponseError.
 func NewResponseError(code int, message string) *ResponseError {
-	return &ResponseError{
-		Code:    code,
-		Message: message,
-	}
+	return sdkerrors.NewAPIError(code, message)
 }
 
 // IsResponseError checks if an error is a ResponseError.
@@ -0,0 +1,48 @@
+// Package api provides MPC API implementations
+package api
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// Names of the feeds created by this package, which are also their checkpoint keys.
+const (
+	FeedDeposits      = "mpc.deposits"
+	FeedWithdraws     = "mpc.withdraws"
+	FeedWeb3          = "mpc.web3"
+	FeedAutoCollects  = "mpc.auto_collects"
+	FeedTronDelegates = "mpc.tron_delegates"
+)
+
+// NewDepositRecordFeed creates the utils.Syncer feed handing every deposit
+// record of SyncDepositRecords to handle.
+func NewDepositRecordFeed(deposits DepositService, handle func(context.Context, *types.DepositRecord) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedDeposits, depositRecordPages(deposits), depositRecordID, handle, opts...)
+}
+
+// NewWithdrawRecordFeed creates the utils.Syncer feed handing every withdrawal
+// record of SyncWithdrawRecords to handle.
+func NewWithdrawRecordFeed(withdraws WithdrawService, handle func(context.Context, *types.WithdrawRecord) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedWithdraws, withdrawRecordPages(withdraws), withdrawRecordID, handle, opts...)
+}
+
+// NewWeb3RecordFeed creates the utils.Syncer feed handing every Web3
+// transaction record of SyncWeb3Records to handle.
+func NewWeb3RecordFeed(web3 Web3Service, handle func(context.Context, *types.Web3TransRecord) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedWeb3, web3RecordPages(web3), web3RecordID, handle, opts...)
+}
+
+// NewAutoCollectRecordFeed creates the utils.Syncer feed handing every
+// auto-collect record of SyncAutoCollectRecords to handle.
+func NewAutoCollectRecordFeed(sweeps AutoSweepService, handle func(context.Context, *types.AutoCollectRecord) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedAutoCollects, autoCollectRecordPages(sweeps), autoCollectRecordID, handle, opts...)
+}
+
+// NewBuyResourceRecordFeed creates the utils.Syncer feed handing every Tron
+// resource delegation record of SyncBuyResourceRecords to handle.
+func NewBuyResourceRecordFeed(tron TronResourceService, handle func(context.Context, *types.TronBuyResourceRecord) error, opts ...utils.CursorOption) utils.Feed {
+	return utils.NewFeed(FeedTronDelegates, buyResourceRecordPages(tron), buyResourceRecordID, handle, opts...)
+}
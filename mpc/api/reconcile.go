@@ -0,0 +1,140 @@
+// Package api provides MPC API implementations
+package api
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// DefaultFinalStatuses are the record statuses after which a reconcile source
+// stops looking a record up: success (2000) and failure (2400).
+var DefaultFinalStatuses = []int64{2000, 2400}
+
+// NotifyObservation converts a decrypted notification to the observation a
+// utils.Reconciler expects. The observation kind is the notification side.
+func NotifyObservation(data *types.NotifyData) utils.Observation {
+	return utils.Observation{
+		Kind:      data.Side,
+		ID:        data.ID.Int64(),
+		RequestID: data.RequestID,
+		Status:    data.Status.Int64(),
+		UpdatedAt: data.UpdatedAt.Time,
+		Record:    data,
+	}
+}
+
+// NewDepositReconcileSource creates the utils.Reconciler source of kind
+// types.NotifySideDeposit, which syncs SyncDepositRecords and looks up open
+// deposits with GetDepositRecords. final overrides DefaultFinalStatuses.
+func NewDepositReconcileSource(deposits DepositService, final ...int64) utils.ReconcileSource {
+	lookup := func(ctx context.Context, open []utils.Observation) ([]*types.DepositRecord, error) {
+		result, err := deposits.GetDepositRecordsContext(ctx, observedIDs(open))
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return result.Data, nil
+	}
+	observe := func(d *types.DepositRecord) utils.Observation {
+		return utils.Observation{Kind: types.NotifySideDeposit, ID: d.ID, Status: d.Status.Int64(), UpdatedAt: d.UpdatedAt.Time, Record: d}
+	}
+	return utils.NewReconcileSource(types.NotifySideDeposit, depositRecordPages(deposits), lookup, observe, finalStatuses(final)...)
+}
+
+// NewWithdrawReconcileSource creates the utils.Reconciler source of kind
+// types.NotifySideWithdraw, which syncs SyncWithdrawRecords and looks up open
+// withdrawals with GetWithdrawRecords. Withdrawals without a request_id are
+// looked up with SyncWithdrawRecords from just before their ID. final
+// overrides DefaultFinalStatuses.
+func NewWithdrawReconcileSource(withdraws WithdrawService, final ...int64) utils.ReconcileSource {
+	lookup := func(ctx context.Context, open []utils.Observation) ([]*types.WithdrawRecord, error) {
+		records, err := syncByIDs(ctx, open, withdrawRecordPages(withdraws), withdrawRecordID)
+		requestIDs := observedRequestIDs(open)
+		if err != nil || len(requestIDs) == 0 {
+			return records, err
+		}
+		result, err := withdraws.GetWithdrawRecordsContext(ctx, requestIDs)
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return append(records, result.Data...), nil
+	}
+	observe := func(w *types.WithdrawRecord) utils.Observation {
+		return utils.Observation{Kind: types.NotifySideWithdraw, ID: w.ID, RequestID: w.RequestID, Status: w.Status.Int64(), UpdatedAt: w.UpdatedAt.Time, Record: w}
+	}
+	return utils.NewReconcileSource(types.NotifySideWithdraw, withdrawRecordPages(withdraws), lookup, observe, finalStatuses(final)...)
+}
+
+// NewWeb3ReconcileSource creates the utils.Reconciler source of kind
+// types.NotifySideWeb3, which syncs SyncWeb3Records and looks up open Web3
+// transactions with GetWeb3Records. Transactions without a request_id are
+// looked up with SyncWeb3Records from just before their ID. final overrides
+// DefaultFinalStatuses.
+func NewWeb3ReconcileSource(web3 Web3Service, final ...int64) utils.ReconcileSource {
+	lookup := func(ctx context.Context, open []utils.Observation) ([]*types.Web3TransRecord, error) {
+		records, err := syncByIDs(ctx, open, web3RecordPages(web3), web3RecordID)
+		requestIDs := observedRequestIDs(open)
+		if err != nil || len(requestIDs) == 0 {
+			return records, err
+		}
+		result, err := web3.GetWeb3RecordsContext(ctx, requestIDs)
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return append(records, result.Data...), nil
+	}
+	observe := func(t *types.Web3TransRecord) utils.Observation {
+		return utils.Observation{Kind: types.NotifySideWeb3, ID: t.ID, RequestID: t.RequestID, Status: t.Status.Int64(), UpdatedAt: t.UpdatedAt.Time, Record: t}
+	}
+	return utils.NewReconcileSource(types.NotifySideWeb3, web3RecordPages(web3), lookup, observe, finalStatuses(final)...)
+}
+
+func finalStatuses(final []int64) []int64 {
+	if len(final) == 0 {
+		return DefaultFinalStatuses
+	}
+	return final
+}
+
+func observedIDs(observations []utils.Observation) []int64 {
+	ids := make([]int64, len(observations))
+	for i, obs := range observations {
+		ids[i] = obs.ID
+	}
+	return ids
+}
+
+// observedRequestIDs returns the request IDs of the observations that have one.
+func observedRequestIDs(observations []utils.Observation) []string {
+	var requestIDs []string
+	for _, obs := range observations {
+		if obs.RequestID != "" {
+			requestIDs = append(requestIDs, obs.RequestID)
+		}
+	}
+	return requestIDs
+}
+
+// syncByIDs looks up the records of the observations without a request ID,
+// which the lookups by request ID cannot find, by syncing the page that
+// starts at each of them.
+func syncByIDs[T any](ctx context.Context, observations []utils.Observation, fetch utils.PageFunc[T], id func(T) int64) ([]T, error) {
+	var records []T
+	for _, obs := range observations {
+		if obs.RequestID != "" {
+			continue
+		}
+		page, err := fetch(ctx, obs.ID-1)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range page {
+			if id(record) == obs.ID {
+				records = append(records, record)
+				break
+			}
+		}
+	}
+	return records, nil
+}
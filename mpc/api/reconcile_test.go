@@ -0,0 +1,80 @@
+// Package api provides MPC API implementations
+package api
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
+)
+
+// fakeWithdraws serves withdrawal records from memory and records the request
+// IDs it was asked for.
+type fakeWithdraws struct {
+	WithdrawService
+	records    []*types.WithdrawRecord
+	requestIDs [][]string
+}
+
+func (f *fakeWithdraws) GetWithdrawRecordsContext(ctx context.Context, requestIDs []string) (*types.WithdrawRecordResult, error) {
+	f.requestIDs = append(f.requestIDs, requestIDs)
+	result := &types.WithdrawRecordResult{}
+	for _, record := range f.records {
+		for _, requestID := range requestIDs {
+			if record.RequestID == requestID {
+				result.Data = append(result.Data, record)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeWithdraws) SyncWithdrawRecordsContext(ctx context.Context, maxID int64) (*types.WithdrawRecordResult, error) {
+	result := &types.WithdrawRecordResult{}
+	for _, record := range f.records {
+		if record.ID > maxID && len(result.Data) < 2 {
+			result.Data = append(result.Data, record)
+		}
+	}
+	return result, nil
+}
+
+func TestWithdrawReconcileSourceLookup(t *testing.T) {
+	withdraws := &fakeWithdraws{records: []*types.WithdrawRecord{
+		{ID: 1, RequestID: "r-1", Status: 2000},
+		{ID: 2, Status: 2000},
+		{ID: 3, Status: 1000},
+		{ID: 4, RequestID: "r-4", Status: 2400},
+	}}
+	source := NewWithdrawReconcileSource(withdraws)
+
+	open := []utils.Observation{
+		{Kind: types.NotifySideWithdraw, ID: 1, RequestID: "r-1", Status: 1000},
+		{Kind: types.NotifySideWithdraw, ID: 2, Status: 1000},
+		{Kind: types.NotifySideWithdraw, ID: 4, RequestID: "r-4", Status: 1000},
+	}
+	current, err := source.Lookup(context.Background(), open)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	got := make(map[int64]int64)
+	for _, obs := range current {
+		got[obs.ID] = obs.Status
+	}
+	if want := map[int64]int64{1: 2000, 2: 2000, 4: 2400}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lookup() statuses = %v, want %v", got, want)
+	}
+
+	if len(withdraws.requestIDs) != 1 {
+		t.Fatalf("GetWithdrawRecords called %d times, want 1", len(withdraws.requestIDs))
+	}
+	requestIDs := withdraws.requestIDs[0]
+	sort.Strings(requestIDs)
+	if !reflect.DeepEqual(requestIDs, []string{"r-1", "r-4"}) {
+		t.Fatalf("GetWithdrawRecords request IDs = %v, want [r-1 r-4]", requestIDs)
+	}
+}
@@ -0,0 +1,104 @@
+// Package api provides MPC API implementations
+//
+// The *Service interfaces are implemented by the API facades of the client.
+// Code depending on them can be tested with the mocks of package mpcmock.
+package api
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/mpc/types"
+)
+
+// WalletService is the interface of WalletAPI, for wallet operations.
+type WalletService interface {
+	CreateWallet(walletName string, showStatus types.AppShowStatus) (*types.WalletCreateResult, error)
+	CreateWalletContext(ctx context.Context, walletName string, showStatus types.AppShowStatus) (*types.WalletCreateResult, error)
+	CreateWalletAddress(walletID int64, symbol string) (*types.WalletAddressResult, error)
+	CreateWalletAddressContext(ctx context.Context, walletID int64, symbol string) (*types.WalletAddressResult, error)
+	QueryWalletAddress(args *types.QueryWalletAddressArgs) (*types.WalletAddressListResult, error)
+	QueryWalletAddressContext(ctx context.Context, args *types.QueryWalletAddressArgs) (*types.WalletAddressListResult, error)
+	GetWalletAssets(walletID int64, symbol string) (*types.WalletAssetsResult, error)
+	GetWalletAssetsContext(ctx context.Context, walletID int64, symbol string) (*types.WalletAssetsResult, error)
+	ChangeWalletShowStatus(walletIDs []int64, showStatus types.AppShowStatus) (bool, error)
+	ChangeWalletShowStatusContext(ctx context.Context, walletIDs []int64, showStatus types.AppShowStatus) (bool, error)
+	WalletAddressInfo(address, memo string) (*types.WalletAddressInfoResult, error)
+	WalletAddressInfoContext(ctx context.Context, address, memo string) (*types.WalletAddressInfoResult, error)
+}
+
+// DepositService is the interface of DepositAPI, for deposit operations.
+type DepositService interface {
+	GetDepositRecords(ids []int64) (*types.DepositRecordResult, error)
+	GetDepositRecordsContext(ctx context.Context, ids []int64) (*types.DepositRecordResult, error)
+	SyncDepositRecords(maxID int64) (*types.DepositRecordResult, error)
+	SyncDepositRecordsContext(ctx context.Context, maxID int64) (*types.DepositRecordResult, error)
+}
+
+// WithdrawService is the interface of WithdrawAPI, for withdrawal operations.
+type WithdrawService interface {
+	Withdraw(req *types.WithdrawRequest, needTransactionSign bool) (*types.WithdrawResponse, error)
+	WithdrawContext(ctx context.Context, req *types.WithdrawRequest, needTransactionSign bool) (*types.WithdrawResponse, error)
+	GetWithdrawRecords(requestIDs []string) (*types.WithdrawRecordResult, error)
+	GetWithdrawRecordsContext(ctx context.Context, requestIDs []string) (*types.WithdrawRecordResult, error)
+	SyncWithdrawRecords(maxID int64) (*types.WithdrawRecordResult, error)
+	SyncWithdrawRecordsContext(ctx context.Context, maxID int64) (*types.WithdrawRecordResult, error)
+}
+
+// Web3Service is the interface of Web3API, for Web3 operations.
+type Web3Service interface {
+	CreateWeb3Trans(req *types.Web3TransRequest, needTransactionSign bool) (*types.Web3TransResponse, error)
+	CreateWeb3TransContext(ctx context.Context, req *types.Web3TransRequest, needTransactionSign bool) (*types.Web3TransResponse, error)
+	AccelerationWeb3Trans(args *types.Web3AccelerationArgs) (bool, error)
+	AccelerationWeb3TransContext(ctx context.Context, args *types.Web3AccelerationArgs) (bool, error)
+	GetWeb3Records(requestIDs []string) (*types.Web3RecordResult, error)
+	GetWeb3RecordsContext(ctx context.Context, requestIDs []string) (*types.Web3RecordResult, error)
+	SyncWeb3Records(maxID int64) (*types.Web3RecordResult, error)
+	SyncWeb3RecordsContext(ctx context.Context, maxID int64) (*types.Web3RecordResult, error)
+}
+
+// AutoSweepService is the interface of AutoSweepAPI, for auto-sweep operations.
+type AutoSweepService interface {
+	AutoCollectSubWallets(walletIDs []int64, symbol string) (*types.AutoCollectResult, error)
+	AutoCollectSubWalletsContext(ctx context.Context, walletIDs []int64, symbol string) (*types.AutoCollectResult, error)
+	SetAutoCollectSymbol(args *types.SetAutoCollectSymbolArgs) (bool, error)
+	SetAutoCollectSymbolContext(ctx context.Context, args *types.SetAutoCollectSymbolArgs) (bool, error)
+	SyncAutoCollectRecords(maxID int64) (*types.AutoCollectRecordResult, error)
+	SyncAutoCollectRecordsContext(ctx context.Context, maxID int64) (*types.AutoCollectRecordResult, error)
+}
+
+// NotifyService is the interface of NotifyAPI, for notification operations.
+type NotifyService interface {
+	NotifyRequest(cipher string) (*types.NotifyData, error)
+}
+
+// WorkSpaceService is the interface of WorkSpaceAPI, for workspace operations.
+type WorkSpaceService interface {
+	GetSupportMainChain() (*types.SupportMainChainResult, error)
+	GetSupportMainChainContext(ctx context.Context) (*types.SupportMainChainResult, error)
+	GetCoinDetails(args *types.GetCoinDetailsArgs) (*types.CoinDetailsResult, error)
+	GetCoinDetailsContext(ctx context.Context, args *types.GetCoinDetailsArgs) (*types.CoinDetailsResult, error)
+	GetLastBlockHeight(symbol string) (*types.BlockHeightResult, error)
+	GetLastBlockHeightContext(ctx context.Context, symbol string) (*types.BlockHeightResult, error)
+}
+
+// TronResourceService is the interface of TronResourceAPI, for TRON resource operations.
+type TronResourceService interface {
+	CreateTronDelegate(args *types.TronBuyResourceArgs) (*types.TronBuyResourceResult, error)
+	CreateTronDelegateContext(ctx context.Context, args *types.TronBuyResourceArgs) (*types.TronBuyResourceResult, error)
+	GetBuyResourceRecords(requestIds []string) (*types.TronBuyResourceRecordResult, error)
+	GetBuyResourceRecordsContext(ctx context.Context, requestIds []string) (*types.TronBuyResourceRecordResult, error)
+	SyncBuyResourceRecords(maxId int) (*types.TronBuyResourceRecordResult, error)
+	SyncBuyResourceRecordsContext(ctx context.Context, maxId int) (*types.TronBuyResourceRecordResult, error)
+}
+
+// Compile-time checks that the facades implement their interfaces.
+var (
+	_ WalletService       = (*WalletAPI)(nil)
+	_ DepositService      = (*DepositAPI)(nil)
+	_ WithdrawService     = (*WithdrawAPI)(nil)
+	_ Web3Service         = (*Web3API)(nil)
+	_ AutoSweepService    = (*AutoSweepAPI)(nil)
+	_ NotifyService       = (*NotifyAPI)(nil)
+	_ WorkSpaceService    = (*WorkSpaceAPI)(nil)
+	_ TronResourceService = (*TronResourceAPI)(nil)
+)
@@ -2,10 +2,11 @@
 package api
 
 import (
+	"context"
 	"errors"
-	"strings"
 
 	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
 )
 
 // TronResourceAPI provides Tron resource operations
@@ -23,20 +24,16 @@ func NewTronResourceAPI(config MpcConfigProvider) *TronResourceAPI {
 // CreateTronDelegate creates a Tron delegate (buy resource)
 // https://custodydocs-zh.chainup.com/api-references/mpc-apis/apis/tron/delegate-create
 func (t *TronResourceAPI) CreateTronDelegate(args *types.TronBuyResourceArgs) (*types.TronBuyResourceResult, error) {
+	return t.CreateTronDelegateContext(context.Background(), args)
+}
+
+// CreateTronDelegateContext is like CreateTronDelegate but carries ctx through to the HTTP request.
+func (t *TronResourceAPI) CreateTronDelegateContext(ctx context.Context, args *types.TronBuyResourceArgs) (*types.TronBuyResourceResult, error) {
 	if args == nil {
 		return nil, errors.New("args cannot be nil")
 	}
-	if args.RequestID == "" {
-		return nil, errors.New("parameter \"request_id\" is required")
-	}
-	if args.AddressFrom == "" {
-		return nil, errors.New("parameter \"address_from\" is required")
-	}
-	if args.ServiceChargeType == "" {
-		return nil, errors.New("parameter \"service_charge_type\" is required")
-	}
 
-	if args.BuyType == 0 || args.BuyType ==2 {
+	if args.BuyType == 0 || args.BuyType == 2 {
 		if len(args.AddressTo) == 0 || len(args.ContractAddress) == 0 {
 			return nil, errors.New("parameter \"address_to and contract_address\" is required")
 		}
@@ -53,84 +50,56 @@ func (t *TronResourceAPI) CreateTronDelegate(args *types.TronBuyResourceArgs) (*
 	if args.EnergyNum > 0 {
 		params["energy_num"] = args.EnergyNum
 	}
+
 	if args.NetNum > 0 {
 		params["net_num"] = args.NetNum
 	}
+
 	if args.AddressTo != "" {
 		params["address_to"] = args.AddressTo
 	}
+
 	if args.ContractAddress != "" {
 		params["contract_address"] = args.ContractAddress
 	}
 
-	response, err := t.Post("/api/mpc/tron/delegate", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := t.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var buyResult types.TronBuyResourceResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &buyResult); err != nil {
-		return nil, err
-	}
-
-	return &buyResult, nil
+	return utils.CallEndpoint[types.TronBuyResourceResult](ctx, t.caller, epCreateTronDelegate, params)
 }
 
-// GetBuyResourceRecords gets Tron resource purchase records
+// GetBuyResourceRecords gets Tron resource purchase records by request IDs.
+// Long lists are split into parallel requests, see Config.LookupBatch, and
+// request IDs without a record are listed in NotFound.
 // https://custodydocs-zh.chainup.com/api-references/mpc-apis/apis/tron/delegate-record-list
 func (t *TronResourceAPI) GetBuyResourceRecords(requestIds []string) (*types.TronBuyResourceRecordResult, error) {
+	return t.GetBuyResourceRecordsContext(context.Background(), requestIds)
+}
+
+// GetBuyResourceRecordsContext is like GetBuyResourceRecords but carries ctx through to the HTTP request.
+func (t *TronResourceAPI) GetBuyResourceRecordsContext(ctx context.Context, requestIds []string) (*types.TronBuyResourceRecordResult, error) {
 	if len(requestIds) == 0 {
 		return nil, errors.New("parameter \"request_ids\" is required")
 	}
 
-	params := map[string]interface{}{
-		"ids": strings.Join(requestIds, ","),
+	result, notFound, err := utils.LookupByIDs(ctx, t.caller, epGetBuyResourceRecords, requestIds,
+		func(r *types.TronBuyResourceRecordResult) *[]*types.TronBuyResourceRecord { return &r.Data },
+		func(r *types.TronBuyResourceRecord) string { return r.RequestID })
+	if result != nil {
+		result.NotFound = notFound
 	}
-
-	response, err := t.Post("/api/mpc/tron/delegate/trans_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := t.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var recordResult types.TronBuyResourceRecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &recordResult); err != nil {
-		return nil, err
-	}
-
-	return &recordResult, nil
+	return result, err
 }
 
 // SyncBuyResourceRecords syncs Tron resource purchase records
 // https://custodydocs-zh.chainup.com/api-references/mpc-apis/apis/tron/delegate-record-sync-list
 func (t *TronResourceAPI) SyncBuyResourceRecords(maxId int) (*types.TronBuyResourceRecordResult, error) {
+	return t.SyncBuyResourceRecordsContext(context.Background(), maxId)
+}
+
+// SyncBuyResourceRecordsContext is like SyncBuyResourceRecords but carries ctx through to the HTTP request.
+func (t *TronResourceAPI) SyncBuyResourceRecordsContext(ctx context.Context, maxId int) (*types.TronBuyResourceRecordResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxId,
 	}
 
-	response, err := t.Post("/api/mpc/tron/delegate/sync_trans_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := t.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var recordResult types.TronBuyResourceRecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &recordResult); err != nil {
-		return nil, err
-	}
-
-	return &recordResult, nil
-}
\ No newline at end of file
+	return utils.CallEndpoint[types.TronBuyResourceRecordResult](ctx, t.caller, epSyncBuyResourceRecords, params)
+}
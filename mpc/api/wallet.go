@@ -2,11 +2,13 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"strings"
 
 	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
 )
 
 // WalletAPI provides MPC wallet management operations
@@ -25,6 +27,11 @@ func NewWalletAPI(config MpcConfigProvider) *WalletAPI {
 // walletName: Wallet name (max 50 characters)
 // showStatus: Display status: 1 (show), 2 (hide, default)
 func (w *WalletAPI) CreateWallet(walletName string, showStatus types.AppShowStatus) (*types.WalletCreateResult, error) {
+	return w.CreateWalletContext(context.Background(), walletName, showStatus)
+}
+
+// CreateWalletContext is like CreateWallet but carries ctx through to the HTTP request.
+func (w *WalletAPI) CreateWalletContext(ctx context.Context, walletName string, showStatus types.AppShowStatus) (*types.WalletCreateResult, error) {
 	if walletName == "" {
 		return nil, errors.New("parameter \"sub_wallet_name\" is required")
 	}
@@ -42,73 +49,37 @@ func (w *WalletAPI) CreateWallet(walletName string, showStatus types.AppShowStat
 		"app_show_status": int(showStatus),
 	}
 
-	response, err := w.Post("/api/mpc/sub_wallet/create", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	// Parse response
-	var walletResult types.WalletCreateResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &walletResult); err != nil {
-		return nil, err
-	}
-
-	return &walletResult, nil
+	return utils.CallEndpoint[types.WalletCreateResult](ctx, w.caller, epCreateWallet, params)
 }
 
 // CreateWalletAddress creates a wallet address
 // walletID: Wallet ID
 // symbol: Unique identifier for the coin (e.g., "ETH")
 func (w *WalletAPI) CreateWalletAddress(walletID int64, symbol string) (*types.WalletAddressResult, error) {
-	if walletID == 0 {
-		return nil, errors.New("parameter \"sub_wallet_id\" is required")
-	}
-
-	if symbol == "" {
-		return nil, errors.New("parameter \"symbol\" is required")
-	}
+	return w.CreateWalletAddressContext(context.Background(), walletID, symbol)
+}
 
+// CreateWalletAddressContext is like CreateWalletAddress but carries ctx through to the HTTP request.
+func (w *WalletAPI) CreateWalletAddressContext(ctx context.Context, walletID int64, symbol string) (*types.WalletAddressResult, error) {
 	params := map[string]interface{}{
 		"sub_wallet_id": walletID,
 		"symbol":        symbol,
 	}
 
-	response, err := w.Post("/api/mpc/sub_wallet/create/address", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var addressResult types.WalletAddressResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &addressResult); err != nil {
-		return nil, err
-	}
-
-	return &addressResult, nil
+	return utils.CallEndpoint[types.WalletAddressResult](ctx, w.caller, epCreateWalletAddress, params)
 }
 
 // QueryWalletAddress queries wallet addresses
 // args: Query arguments (walletID, symbol, maxID)
 func (w *WalletAPI) QueryWalletAddress(args *types.QueryWalletAddressArgs) (*types.WalletAddressListResult, error) {
+	return w.QueryWalletAddressContext(context.Background(), args)
+}
+
+// QueryWalletAddressContext is like QueryWalletAddress but carries ctx through to the HTTP request.
+func (w *WalletAPI) QueryWalletAddressContext(ctx context.Context, args *types.QueryWalletAddressArgs) (*types.WalletAddressListResult, error) {
 	if args == nil {
 		return nil, errors.New("args cannot be nil")
 	}
-	if args.WalletID == 0 {
-		return nil, errors.New("parameter \"sub_wallet_id\" is required")
-	}
-
-	if args.Symbol == "" {
-		return nil, errors.New("parameter \"symbol\" is required")
-	}
 
 	params := map[string]interface{}{
 		"sub_wallet_id": args.WalletID,
@@ -116,67 +87,35 @@ func (w *WalletAPI) QueryWalletAddress(args *types.QueryWalletAddressArgs) (*typ
 		"max_id":        args.MaxID,
 	}
 
-	response, err := w.Post("/api/mpc/sub_wallet/get/address/list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var listResult types.WalletAddressListResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &listResult); err != nil {
-		return nil, err
-	}
-
-	return &listResult, nil
+	return utils.CallEndpoint[types.WalletAddressListResult](ctx, w.caller, epQueryWalletAddress, params)
 }
 
 // GetWalletAssets gets wallet assets
 // walletID: Wallet ID
 // symbol: Unique identifier for the coin (e.g., "ETH")
 func (w *WalletAPI) GetWalletAssets(walletID int64, symbol string) (*types.WalletAssetsResult, error) {
-	if walletID == 0 {
-		return nil, errors.New("parameter \"sub_wallet_id\" is required")
-	}
-
-	if symbol == "" {
-		return nil, errors.New("parameter \"symbol\" is required")
-	}
+	return w.GetWalletAssetsContext(context.Background(), walletID, symbol)
+}
 
+// GetWalletAssetsContext is like GetWalletAssets but carries ctx through to the HTTP request.
+func (w *WalletAPI) GetWalletAssetsContext(ctx context.Context, walletID int64, symbol string) (*types.WalletAssetsResult, error) {
 	params := map[string]interface{}{
 		"sub_wallet_id": walletID,
 		"symbol":        symbol,
 	}
 
-	response, err := w.Get("/api/mpc/sub_wallet/assets", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var assetsResult types.WalletAssetsResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &assetsResult); err != nil {
-		return nil, err
-	}
-
-	return &assetsResult, nil
+	return utils.CallEndpoint[types.WalletAssetsResult](ctx, w.caller, epGetWalletAssets, params)
 }
 
 // ChangeWalletShowStatus modifies the wallet display status
 // walletIDs: List of wallet IDs
 // showStatus: Display status: 1 (show), 2 (hide)
 func (w *WalletAPI) ChangeWalletShowStatus(walletIDs []int64, showStatus types.AppShowStatus) (bool, error) {
-	if len(walletIDs) == 0 {
-		return false, errors.New("parameter \"sub_wallet_ids\" is required")
-	}
+	return w.ChangeWalletShowStatusContext(context.Background(), walletIDs, showStatus)
+}
 
+// ChangeWalletShowStatusContext is like ChangeWalletShowStatus but carries ctx through to the HTTP request.
+func (w *WalletAPI) ChangeWalletShowStatusContext(ctx context.Context, walletIDs []int64, showStatus types.AppShowStatus) (bool, error) {
 	if showStatus != types.AppShowStatusShow && showStatus != types.AppShowStatusHidden {
 		return false, errors.New("parameter \"app_show_status\" must be 1 or 2")
 	}
@@ -192,55 +131,29 @@ func (w *WalletAPI) ChangeWalletShowStatus(walletIDs []int64, showStatus types.A
 		"app_show_status": int(showStatus),
 	}
 
-	response, err := w.Post("/api/mpc/sub_wallet/change_show_status", params)
-	if err != nil {
+	if _, err := utils.CallEndpoint[utils.ResponseStatus](ctx, w.caller, epChangeWalletShowStatus, params); err != nil {
 		return false, err
 	}
 
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return false, err
-	}
-
-	// Check if code is "0"
-	if resultMap, ok := result.(map[string]interface{}); ok {
-		if code, ok := resultMap["code"].(string); ok && code == "0" {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return true, nil
 }
 
 // WalletAddressInfo gets wallet address info
 // address: Any address
 // memo: If it's a Memo type, input the memo
 func (w *WalletAPI) WalletAddressInfo(address, memo string) (*types.WalletAddressInfoResult, error) {
-	if address == "" {
-		return nil, errors.New("parameter \"address\" is required")
-	}
+	return w.WalletAddressInfoContext(context.Background(), address, memo)
+}
 
+// WalletAddressInfoContext is like WalletAddressInfo but carries ctx through to the HTTP request.
+func (w *WalletAPI) WalletAddressInfoContext(ctx context.Context, address, memo string) (*types.WalletAddressInfoResult, error) {
 	params := map[string]interface{}{
 		"address": address,
 	}
+
 	if memo != "" {
 		params["memo"] = memo
 	}
 
-	response, err := w.Get("/api/mpc/sub_wallet/address/info", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var infoResult types.WalletAddressInfoResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &infoResult); err != nil {
-		return nil, err
-	}
-
-	return &infoResult, nil
+	return utils.CallEndpoint[types.WalletAddressInfoResult](ctx, w.caller, epWalletAddressInfo, params)
 }
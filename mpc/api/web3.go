@@ -2,11 +2,12 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
 	"chainup.com/go-sdk/utils/mpcsign"
 )
 
@@ -26,6 +27,11 @@ func NewWeb3API(config MpcConfigProvider) *Web3API {
 // req: Web3 transaction request parameters
 // needTransactionSign: Whether to sign the transaction (requires signPrivateKey in config)
 func (w *Web3API) CreateWeb3Trans(req *types.Web3TransRequest, needTransactionSign bool) (*types.Web3TransResponse, error) {
+	return w.CreateWeb3TransContext(context.Background(), req, needTransactionSign)
+}
+
+// CreateWeb3TransContext is like CreateWeb3Trans but carries ctx through to the HTTP request.
+func (w *Web3API) CreateWeb3TransContext(ctx context.Context, req *types.Web3TransRequest, needTransactionSign bool) (*types.Web3TransResponse, error) {
 	if req == nil {
 		return nil, errors.New("web3 transaction request is required")
 	}
@@ -45,12 +51,15 @@ func (w *Web3API) CreateWeb3Trans(req *types.Web3TransRequest, needTransactionSi
 	if req.From != "" {
 		params["from"] = req.From
 	}
+
 	if req.DappName != "" {
 		params["dapp_name"] = req.DappName
 	}
+
 	if req.DappURL != "" {
 		params["dapp_url"] = req.DappURL
 	}
+
 	if req.DappImg != "" {
 		params["dapp_img"] = req.DappImg
 	}
@@ -80,28 +89,18 @@ func (w *Web3API) CreateWeb3Trans(req *types.Web3TransRequest, needTransactionSi
 		params["sign"] = signature
 	}
 
-	response, err := w.Post("/api/mpc/web3/trans/create", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var web3Resp types.Web3TransResponse
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &web3Resp); err != nil {
-		return nil, err
-	}
-
-	return &web3Resp, nil
+	return utils.CallEndpoint[types.Web3TransResponse](ctx, w.caller, epCreateWeb3Trans, params)
 }
 
 // AccelerationWeb3Trans accelerates a Web3 transaction
 // args: Acceleration arguments (trans_id, gas_price, gas_limit)
 // See: https://custodydocs-en.chainup.com/api-references/mpc-apis/apis/web3/web3-pending
 func (w *Web3API) AccelerationWeb3Trans(args *types.Web3AccelerationArgs) (bool, error) {
+	return w.AccelerationWeb3TransContext(context.Background(), args)
+}
+
+// AccelerationWeb3TransContext is like AccelerationWeb3Trans but carries ctx through to the HTTP request.
+func (w *Web3API) AccelerationWeb3TransContext(ctx context.Context, args *types.Web3AccelerationArgs) (bool, error) {
 	if args == nil {
 		return false, errors.New("acceleration args is required")
 	}
@@ -112,76 +111,46 @@ func (w *Web3API) AccelerationWeb3Trans(args *types.Web3AccelerationArgs) (bool,
 		"gas_limit": args.GasLimit,
 	}
 
-	response, err := w.Post("/api/mpc/web3/pending", params)
-	if err != nil {
+	if _, err := utils.CallEndpoint[utils.ResponseStatus](ctx, w.caller, epAccelerationWeb3Trans, params); err != nil {
 		return false, err
 	}
 
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return false, err
-	}
-
-	// Check if code is "0"
-	if resultMap, ok := result.(map[string]interface{}); ok {
-		if code, ok := resultMap["code"].(string); ok && code == "0" {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return true, nil
 }
 
 // GetWeb3Records gets Web3 transaction records by request IDs
-// requestIDs: List of request IDs
+// requestIDs: List of request IDs; long lists are split into parallel requests,
+// see Config.LookupBatch. Request IDs without a record are listed in NotFound.
 func (w *Web3API) GetWeb3Records(requestIDs []string) (*types.Web3RecordResult, error) {
+	return w.GetWeb3RecordsContext(context.Background(), requestIDs)
+}
+
+// GetWeb3RecordsContext is like GetWeb3Records but carries ctx through to the HTTP request.
+func (w *Web3API) GetWeb3RecordsContext(ctx context.Context, requestIDs []string) (*types.Web3RecordResult, error) {
 	if len(requestIDs) == 0 {
 		return nil, errors.New("parameter \"request_ids\" is required and must be a non-empty array")
 	}
 
-	params := map[string]interface{}{
-		"ids": strings.Join(requestIDs, ","),
-	}
-
-	response, err := w.Get("/api/mpc/web3/trans_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var web3Result types.Web3RecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &web3Result); err != nil {
-		return nil, err
+	result, notFound, err := utils.LookupByIDs(ctx, w.caller, epGetWeb3Records, requestIDs,
+		func(r *types.Web3RecordResult) *[]*types.Web3TransRecord { return &r.Data },
+		func(r *types.Web3TransRecord) string { return r.RequestID })
+	if result != nil {
+		result.NotFound = notFound
 	}
-
-	return &web3Result, nil
+	return result, err
 }
 
 // SyncWeb3Records syncs Web3 transaction records by max ID
 // maxID: Web3 record initial ID, default is 0
 func (w *Web3API) SyncWeb3Records(maxID int64) (*types.Web3RecordResult, error) {
+	return w.SyncWeb3RecordsContext(context.Background(), maxID)
+}
+
+// SyncWeb3RecordsContext is like SyncWeb3Records but carries ctx through to the HTTP request.
+func (w *Web3API) SyncWeb3RecordsContext(ctx context.Context, maxID int64) (*types.Web3RecordResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := w.Get("/api/mpc/web3/sync_trans_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var web3Result types.Web3RecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &web3Result); err != nil {
-		return nil, err
-	}
-
-	return &web3Result, nil
+	return utils.CallEndpoint[types.Web3RecordResult](ctx, w.caller, epSyncWeb3Records, params)
 }
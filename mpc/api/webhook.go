@@ -0,0 +1,185 @@
+// Package api provides MPC API implementations
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"chainup.com/go-sdk/mpc/types"
+)
+
+// DefaultWebhookAck is the response body that acknowledges a notification.
+// ChainUp keeps retrying a notification until it receives it.
+const DefaultWebhookAck = "SUCCESS"
+
+// MaxWebhookBodySize is the largest notification body a WebhookHandler reads.
+const MaxWebhookBodySize = 16 << 10
+
+// WebhookHandler is an http.Handler receiving MPC notifications. It decrypts
+// the data form field of the POST body with NotifyRequest and dispatches the
+// notification by side to the typed callback, e.g. OnDeposit:
+//
+//	handler := api.NewWebhookHandler(client.GetNotifyAPI()).
+//		OnDeposit(func(ctx context.Context, n *types.DepositNotification) error {
+//			return credit(ctx, n)
+//		}).
+//		OnWithdraw(handleWithdraw)
+//	http.Handle("/chainup/notify", handler)
+//
+// The acknowledgement is written only when the callbacks return nil. A failed
+// callback is answered with 503 Service Unavailable, so ChainUp retries the
+// notification later; callbacks must therefore tolerate repeats. A request
+// that cannot be decrypted is answered with 400 Bad Request, and one whose
+// body exceeds MaxWebhookBodySize with 413 Request Entity Too Large.
+// Notifications of a side without callback are acknowledged and dropped.
+type WebhookHandler struct {
+	notify        NotifyService
+	ack           string
+	onNotify      func(context.Context, *types.NotifyData) error
+	onDeposit     func(context.Context, *types.DepositNotification) error
+	onWithdraw    func(context.Context, *types.WithdrawNotification) error
+	onWeb3        func(context.Context, *types.Web3Notification) error
+	onAutoCollect func(context.Context, *types.AutoCollectNotification) error
+	onDelegate    func(context.Context, *types.TronDelegateNotification) error
+	onError       func(side string, err error)
+}
+
+// NewWebhookHandler creates a WebhookHandler decrypting notifications with
+// notify, usually client.GetNotifyAPI().
+func NewWebhookHandler(notify NotifyService) *WebhookHandler {
+	return &WebhookHandler{notify: notify, ack: DefaultWebhookAck}
+}
+
+// SetAck sets the acknowledgement body (default: DefaultWebhookAck).
+func (h *WebhookHandler) SetAck(ack string) *WebhookHandler {
+	h.ack = ack
+	return h
+}
+
+// OnNotify sets the callback receiving every notification as decrypted,
+// before the typed callback of its side, e.g. to feed a utils.Reconciler.
+func (h *WebhookHandler) OnNotify(fn func(context.Context, *types.NotifyData) error) *WebhookHandler {
+	h.onNotify = fn
+	return h
+}
+
+// OnDeposit sets the callback of deposit notifications.
+func (h *WebhookHandler) OnDeposit(fn func(context.Context, *types.DepositNotification) error) *WebhookHandler {
+	h.onDeposit = fn
+	return h
+}
+
+// OnWithdraw sets the callback of withdrawal notifications.
+func (h *WebhookHandler) OnWithdraw(fn func(context.Context, *types.WithdrawNotification) error) *WebhookHandler {
+	h.onWithdraw = fn
+	return h
+}
+
+// OnWeb3 sets the callback of Web3 transaction notifications.
+func (h *WebhookHandler) OnWeb3(fn func(context.Context, *types.Web3Notification) error) *WebhookHandler {
+	h.onWeb3 = fn
+	return h
+}
+
+// OnAutoCollect sets the callback of auto-collect notifications.
+func (h *WebhookHandler) OnAutoCollect(fn func(context.Context, *types.AutoCollectNotification) error) *WebhookHandler {
+	h.onAutoCollect = fn
+	return h
+}
+
+// OnTronDelegate sets the callback of TRON resource delegation notifications.
+func (h *WebhookHandler) OnTronDelegate(fn func(context.Context, *types.TronDelegateNotification) error) *WebhookHandler {
+	h.onDelegate = fn
+	return h
+}
+
+// OnError sets the callback receiving the errors of rejected requests and
+// failed callbacks, with the notification side when it is known.
+func (h *WebhookHandler) OnError(fn func(side string, err error)) *WebhookHandler {
+	h.onError = fn
+	return h
+}
+
+// ServeHTTP handles one notification.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxWebhookBodySize)
+	data, err := h.decode(r)
+	if err != nil {
+		h.reportError("", err)
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), data); err != nil {
+		h.reportError(data.Side, err)
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(h.ack))
+}
+
+// decode reads and decrypts the notification of r.
+func (h *WebhookHandler) decode(r *http.Request) (*types.NotifyData, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("failed to read notification: %w", err)
+	}
+	cipher := r.PostForm.Get("data")
+	if cipher == "" {
+		return nil, errors.New("notification has no data field")
+	}
+	return h.notify.NotifyRequest(cipher)
+}
+
+// dispatch passes data to the callbacks.
+func (h *WebhookHandler) dispatch(ctx context.Context, data *types.NotifyData) error {
+	if h.onNotify != nil {
+		if err := h.onNotify(ctx, data); err != nil {
+			return err
+		}
+	}
+
+	switch data.Side {
+	case types.NotifySideDeposit:
+		if h.onDeposit != nil {
+			return h.onDeposit(ctx, data.Deposit())
+		}
+	case types.NotifySideWithdraw:
+		if h.onWithdraw != nil {
+			return h.onWithdraw(ctx, data.Withdraw())
+		}
+	case types.NotifySideWeb3:
+		if h.onWeb3 != nil {
+			return h.onWeb3(ctx, data.Web3())
+		}
+	case types.NotifySideAutoCollect:
+		if h.onAutoCollect != nil {
+			return h.onAutoCollect(ctx, data.AutoCollect())
+		}
+	case types.NotifySideTronDelegate:
+		if h.onDelegate != nil {
+			return h.onDelegate(ctx, data.TronDelegate())
+		}
+	}
+	return nil
+}
+
+func (h *WebhookHandler) reportError(side string, err error) {
+	if h.onError != nil {
+		h.onError(side, err)
+	}
+}
@@ -0,0 +1,110 @@
+// Package api provides MPC API implementations
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"chainup.com/go-sdk/mpc/types"
+)
+
+// plainNotify is a NotifyService reading notifications as plain JSON.
+type plainNotify struct{}
+
+func (plainNotify) NotifyRequest(cipher string) (*types.NotifyData, error) {
+	var data types.NotifyData
+	if err := json.Unmarshal([]byte(cipher), &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func TestWebhookHandler(t *testing.T) {
+	errCallback := errors.New("database unavailable")
+	tests := []struct {
+		name       string
+		method     string
+		data       string
+		notifyErr  error
+		typedErr   error
+		wantStatus int
+		wantCalled string
+		// wantError is the side passed to OnError, "-" when it is not called.
+		wantError string
+	}{
+		{name: "deposit", data: `{"side":"deposit","id":1}`, wantStatus: http.StatusOK, wantCalled: "deposit", wantError: "-"},
+		{name: "withdraw", data: `{"side":"withdraw","id":1}`, wantStatus: http.StatusOK, wantCalled: "withdraw", wantError: "-"},
+		{name: "web3", data: `{"side":"web3","id":1}`, wantStatus: http.StatusOK, wantCalled: "web3", wantError: "-"},
+		{name: "auto collect", data: `{"side":"auto_collect","id":1}`, wantStatus: http.StatusOK, wantCalled: "auto_collect", wantError: "-"},
+		{name: "tron delegate", data: `{"side":"tron_delegate","id":1}`, wantStatus: http.StatusOK, wantCalled: "tron_delegate", wantError: "-"},
+		{name: "unknown side acknowledged", data: `{"side":"airdrop","id":1}`, wantStatus: http.StatusOK, wantError: "-"},
+		{name: "wrong method", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed, wantError: "-"},
+		{name: "missing data", data: "", wantStatus: http.StatusBadRequest, wantError: ""},
+		{name: "undecryptable data", data: "forged", wantStatus: http.StatusBadRequest, wantError: ""},
+		{name: "body too large", data: strings.Repeat("a", MaxWebhookBodySize), wantStatus: http.StatusRequestEntityTooLarge, wantError: ""},
+		{name: "OnNotify fails", data: `{"side":"deposit","id":1}`, notifyErr: errCallback, wantStatus: http.StatusServiceUnavailable, wantError: "deposit"},
+		{name: "typed callback fails", data: `{"side":"withdraw","id":1}`, typedErr: errCallback, wantStatus: http.StatusServiceUnavailable, wantCalled: "withdraw", wantError: "withdraw"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called, errorSide := "", "-"
+			typed := func(side string, id int64) error {
+				if id != 1 {
+					t.Fatalf("%s callback got id %d, want 1", side, id)
+				}
+				called = side
+				return tt.typedErr
+			}
+			handler := NewWebhookHandler(plainNotify{}).
+				OnNotify(func(ctx context.Context, data *types.NotifyData) error { return tt.notifyErr }).
+				OnDeposit(func(ctx context.Context, n *types.DepositNotification) error {
+					return typed(types.NotifySideDeposit, n.ID.Int64())
+				}).
+				OnWithdraw(func(ctx context.Context, n *types.WithdrawNotification) error {
+					return typed(types.NotifySideWithdraw, n.ID.Int64())
+				}).
+				OnWeb3(func(ctx context.Context, n *types.Web3Notification) error {
+					return typed(types.NotifySideWeb3, n.ID.Int64())
+				}).
+				OnAutoCollect(func(ctx context.Context, n *types.AutoCollectNotification) error {
+					return typed(types.NotifySideAutoCollect, n.ID.Int64())
+				}).
+				OnTronDelegate(func(ctx context.Context, n *types.TronDelegateNotification) error {
+					return typed(types.NotifySideTronDelegate, n.ID.Int64())
+				}).
+				OnError(func(side string, err error) { errorSide = side })
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, "/notify", strings.NewReader(url.Values{"data": {tt.data}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if called != tt.wantCalled {
+				t.Fatalf("typed callback called for %q, want %q", called, tt.wantCalled)
+			}
+			if errorSide != tt.wantError {
+				t.Fatalf("OnError side = %q, want %q", errorSide, tt.wantError)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != DefaultWebhookAck {
+				t.Fatalf("body = %q, want %q", rec.Body.String(), DefaultWebhookAck)
+			}
+			if tt.wantStatus == http.StatusMethodNotAllowed && rec.Header().Get("Allow") != http.MethodPost {
+				t.Fatalf("Allow = %q, want POST", rec.Header().Get("Allow"))
+			}
+		})
+	}
+}
@@ -2,11 +2,12 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
 	"chainup.com/go-sdk/utils/mpcsign"
 )
 
@@ -26,6 +27,11 @@ func NewWithdrawAPI(config MpcConfigProvider) *WithdrawAPI {
 // req: Withdrawal request parameters
 // needTransactionSign: Whether to sign the transaction (requires signPrivateKey in config)
 func (w *WithdrawAPI) Withdraw(req *types.WithdrawRequest, needTransactionSign bool) (*types.WithdrawResponse, error) {
+	return w.WithdrawContext(context.Background(), req, needTransactionSign)
+}
+
+// WithdrawContext is like Withdraw but carries ctx through to the HTTP request.
+func (w *WithdrawAPI) WithdrawContext(ctx context.Context, req *types.WithdrawRequest, needTransactionSign bool) (*types.WithdrawResponse, error) {
 	if req == nil {
 		return nil, errors.New("withdraw request is required")
 	}
@@ -41,12 +47,15 @@ func (w *WithdrawAPI) Withdraw(req *types.WithdrawRequest, needTransactionSign b
 	if req.From != "" {
 		params["from"] = req.From
 	}
+
 	if req.Memo != "" {
 		params["memo"] = req.Memo
 	}
+
 	if req.Remark != "" {
 		params["remark"] = req.Remark
 	}
+
 	if req.Outputs != "" {
 		params["outputs"] = req.Outputs
 	}
@@ -82,74 +91,42 @@ func (w *WithdrawAPI) Withdraw(req *types.WithdrawRequest, needTransactionSign b
 		params["sign"] = signature
 	}
 
-	response, err := w.Post("/api/mpc/billing/withdraw", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var withdrawResp types.WithdrawResponse
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &withdrawResp); err != nil {
-		return nil, err
-	}
-
-	return &withdrawResp, nil
+	return utils.CallEndpoint[types.WithdrawResponse](ctx, w.caller, epWithdraw, params)
 }
 
 // GetWithdrawRecords gets withdrawal records by request IDs
-// requestIDs: List of request IDs
+// requestIDs: List of request IDs; long lists are split into parallel requests,
+// see Config.LookupBatch. Request IDs without a record are listed in NotFound.
 func (w *WithdrawAPI) GetWithdrawRecords(requestIDs []string) (*types.WithdrawRecordResult, error) {
+	return w.GetWithdrawRecordsContext(context.Background(), requestIDs)
+}
+
+// GetWithdrawRecordsContext is like GetWithdrawRecords but carries ctx through to the HTTP request.
+func (w *WithdrawAPI) GetWithdrawRecordsContext(ctx context.Context, requestIDs []string) (*types.WithdrawRecordResult, error) {
 	if len(requestIDs) == 0 {
 		return nil, errors.New("parameter \"request_ids\" is required and must be a non-empty array")
 	}
 
-	params := map[string]interface{}{
-		"ids": strings.Join(requestIDs, ","),
+	result, notFound, err := utils.LookupByIDs(ctx, w.caller, epGetWithdrawRecords, requestIDs,
+		func(r *types.WithdrawRecordResult) *[]*types.WithdrawRecord { return &r.Data },
+		func(r *types.WithdrawRecord) string { return r.RequestID })
+	if result != nil {
+		result.NotFound = notFound
 	}
-
-	response, err := w.Get("/api/mpc/billing/withdraw_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var withdrawResult types.WithdrawRecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &withdrawResult); err != nil {
-		return nil, err
-	}
-
-	return &withdrawResult, nil
+	return result, err
 }
 
 // SyncWithdrawRecords syncs withdrawal records by max ID
 // maxID: Withdrawal record initial ID, default is 0
 func (w *WithdrawAPI) SyncWithdrawRecords(maxID int64) (*types.WithdrawRecordResult, error) {
+	return w.SyncWithdrawRecordsContext(context.Background(), maxID)
+}
+
+// SyncWithdrawRecordsContext is like SyncWithdrawRecords but carries ctx through to the HTTP request.
+func (w *WithdrawAPI) SyncWithdrawRecordsContext(ctx context.Context, maxID int64) (*types.WithdrawRecordResult, error) {
 	params := map[string]interface{}{
 		"max_id": maxID,
 	}
 
-	response, err := w.Get("/api/mpc/billing/sync_withdraw_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var withdrawResult types.WithdrawRecordResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &withdrawResult); err != nil {
-		return nil, err
-	}
-
-	return &withdrawResult, nil
+	return utils.CallEndpoint[types.WithdrawRecordResult](ctx, w.caller, epSyncWithdrawRecords, params)
 }
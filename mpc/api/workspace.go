@@ -2,9 +2,11 @@
 package api
 
 import (
+	"context"
 	"errors"
 
 	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
 )
 
 // WorkSpaceAPI provides workspace operations
@@ -21,27 +23,22 @@ func NewWorkSpaceAPI(config MpcConfigProvider) *WorkSpaceAPI {
 
 // GetSupportMainChain gets supported main chains
 func (w *WorkSpaceAPI) GetSupportMainChain() (*types.SupportMainChainResult, error) {
-	response, err := w.Get("/api/mpc/wallet/open_coin", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var chainResult types.SupportMainChainResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &chainResult); err != nil {
-		return nil, err
-	}
+	return w.GetSupportMainChainContext(context.Background())
+}
 
-	return &chainResult, nil
+// GetSupportMainChainContext is like GetSupportMainChain but carries ctx through to the HTTP request.
+func (w *WorkSpaceAPI) GetSupportMainChainContext(ctx context.Context) (*types.SupportMainChainResult, error) {
+	return utils.CallEndpoint[types.SupportMainChainResult](ctx, w.caller, epGetSupportMainChain, nil)
 }
 
 // GetCoinDetails gets coin details
 // args: Coin details query arguments (symbol, contractAddress, showBalance, maxId, limit)
 func (w *WorkSpaceAPI) GetCoinDetails(args *types.GetCoinDetailsArgs) (*types.CoinDetailsResult, error) {
+	return w.GetCoinDetailsContext(context.Background(), args)
+}
+
+// GetCoinDetailsContext is like GetCoinDetails but carries ctx through to the HTTP request.
+func (w *WorkSpaceAPI) GetCoinDetailsContext(ctx context.Context, args *types.GetCoinDetailsArgs) (*types.CoinDetailsResult, error) {
 	params := map[string]interface{}{}
 
 	if args != nil {
@@ -62,27 +59,17 @@ func (w *WorkSpaceAPI) GetCoinDetails(args *types.GetCoinDetailsArgs) (*types.Co
 		}
 	}
 
-	response, err := w.Get("/api/mpc/coin_list", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var coinResult types.CoinDetailsResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &coinResult); err != nil {
-		return nil, err
-	}
-
-	return &coinResult, nil
+	return utils.CallEndpoint[types.CoinDetailsResult](ctx, w.caller, epGetCoinDetails, params)
 }
 
 // GetLastBlockHeight gets the latest block height
 // symbol: Main chain symbol (e.g., "ETH")
 func (w *WorkSpaceAPI) GetLastBlockHeight(symbol string) (*types.BlockHeightResult, error) {
+	return w.GetLastBlockHeightContext(context.Background(), symbol)
+}
+
+// GetLastBlockHeightContext is like GetLastBlockHeight but carries ctx through to the HTTP request.
+func (w *WorkSpaceAPI) GetLastBlockHeightContext(ctx context.Context, symbol string) (*types.BlockHeightResult, error) {
 	if symbol == "" {
 		return nil, errors.New("parameter \"symbol\" is required")
 	}
@@ -91,20 +78,5 @@ func (w *WorkSpaceAPI) GetLastBlockHeight(symbol string) (*types.BlockHeightResu
 		"base_symbol": symbol,
 	}
 
-	response, err := w.Get("/api/mpc/chain_height", params)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := w.ValidateResponse(response)
-	if err != nil {
-		return nil, err
-	}
-
-	var heightResult types.BlockHeightResult
-	if err := SafeUnmarshalResponse(result.(map[string]interface{}), &heightResult); err != nil {
-		return nil, err
-	}
-
-	return &heightResult, nil
+	return utils.CallEndpoint[types.BlockHeightResult](ctx, w.caller, epGetLastBlockHeight, params)
 }
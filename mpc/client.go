@@ -2,15 +2,49 @@
 package mpc
 
 import (
+	"context"
+	"log/slog"
+	"net/http"
+
 	"chainup.com/go-sdk/mpc/api"
+	"chainup.com/go-sdk/utils"
 )
 
 // Client is the main entry point for MPC API operations.
-// It provides factory methods for creating API instances.
+// All API instances returned by a Client share one HTTP transport and are
+// created once, so the getters are cheap and the Client is safe for
+// concurrent use.
 type Client struct {
 	config *Config
+	base   *api.MpcBaseAPI
+
+	walletAPI       *api.WalletAPI
+	depositAPI      *api.DepositAPI
+	withdrawAPI     *api.WithdrawAPI
+	web3API         *api.Web3API
+	autoSweepAPI    *api.AutoSweepAPI
+	notifyAPI       *api.NotifyAPI
+	workSpaceAPI    *api.WorkSpaceAPI
+	tronResourceAPI *api.TronResourceAPI
+}
+
+// Interface is the interface of Client. Code depending on it can be tested
+// with the mocks of package mpcmock.
+type Interface interface {
+	GetWalletAPI() api.WalletService
+	GetDepositAPI() api.DepositService
+	GetWithdrawAPI() api.WithdrawService
+	GetWeb3API() api.Web3Service
+	GetAutoSweepAPI() api.AutoSweepService
+	GetNotifyAPI() api.NotifyService
+	GetWorkSpaceAPI() api.WorkSpaceService
+	GetTronResourceAPI() api.TronResourceService
+	RawCall(ctx context.Context, method, path string, params map[string]interface{}) (*utils.RawResponse, error)
+	HostStats() []utils.HostStats
 }
 
+var _ Interface = (*Client)(nil)
+
 // MpcClient is an alias for Client for backward compatibility.
 type MpcClient = Client
 
@@ -20,49 +54,76 @@ func NewMpcClient(config *Config) (*Client, error) {
 		return nil, err
 	}
 
+	base := api.NewMpcBaseAPI(config)
+
 	return &Client{
-		config: config,
+		config:          config,
+		base:            base,
+		walletAPI:       &api.WalletAPI{MpcBaseAPI: base},
+		depositAPI:      &api.DepositAPI{MpcBaseAPI: base},
+		withdrawAPI:     &api.WithdrawAPI{MpcBaseAPI: base},
+		web3API:         &api.Web3API{MpcBaseAPI: base},
+		autoSweepAPI:    &api.AutoSweepAPI{MpcBaseAPI: base},
+		notifyAPI:       &api.NotifyAPI{MpcBaseAPI: base},
+		workSpaceAPI:    &api.WorkSpaceAPI{MpcBaseAPI: base},
+		tronResourceAPI: &api.TronResourceAPI{MpcBaseAPI: base},
 	}, nil
 }
 
 // GetWalletAPI returns WalletAPI instance for wallet operations.
-func (c *Client) GetWalletAPI() *api.WalletAPI {
-	return api.NewWalletAPI(c.config)
+func (c *Client) GetWalletAPI() api.WalletService {
+	return c.walletAPI
 }
 
 // GetDepositAPI returns DepositAPI instance for deposit operations.
-func (c *Client) GetDepositAPI() *api.DepositAPI {
-	return api.NewDepositAPI(c.config)
+func (c *Client) GetDepositAPI() api.DepositService {
+	return c.depositAPI
 }
 
 // GetWithdrawAPI returns WithdrawAPI instance for withdrawal operations.
-func (c *Client) GetWithdrawAPI() *api.WithdrawAPI {
-	return api.NewWithdrawAPI(c.config)
+func (c *Client) GetWithdrawAPI() api.WithdrawService {
+	return c.withdrawAPI
 }
 
 // GetWeb3API returns Web3API instance for Web3 operations.
-func (c *Client) GetWeb3API() *api.Web3API {
-	return api.NewWeb3API(c.config)
+func (c *Client) GetWeb3API() api.Web3Service {
+	return c.web3API
 }
 
 // GetAutoSweepAPI returns AutoSweepAPI instance for auto-sweep operations.
-func (c *Client) GetAutoSweepAPI() *api.AutoSweepAPI {
-	return api.NewAutoSweepAPI(c.config)
+func (c *Client) GetAutoSweepAPI() api.AutoSweepService {
+	return c.autoSweepAPI
 }
 
 // GetNotifyAPI returns NotifyAPI instance for notification operations.
-func (c *Client) GetNotifyAPI() *api.NotifyAPI {
-	return api.NewNotifyAPI(c.config)
+func (c *Client) GetNotifyAPI() api.NotifyService {
+	return c.notifyAPI
 }
 
 // GetWorkSpaceAPI returns WorkSpaceAPI instance for workspace operations.
-func (c *Client) GetWorkSpaceAPI() *api.WorkSpaceAPI {
-	return api.NewWorkSpaceAPI(c.config)
+func (c *Client) GetWorkSpaceAPI() api.WorkSpaceService {
+	return c.workSpaceAPI
 }
 
 // GetTronResourceAPI returns TronResourceAPI instance for TRON resource operations.
-func (c *Client) GetTronResourceAPI() *api.TronResourceAPI {
-	return api.NewTronResourceAPI(c.config)
+func (c *Client) GetTronResourceAPI() api.TronResourceService {
+	return c.tronResourceAPI
+}
+
+// RawCall executes an endpoint the SDK does not wrap yet, with the same
+// encryption, logging and error handling as the built-in methods. Decode the
+// result with utils.DecodeRaw or the RawResponse helpers:
+//
+//	resp, err := client.RawCall(ctx, utils.HTTPMethodPost, "/api/mpc/new_endpoint", params)
+//	result, err := utils.DecodeRaw[MyResult](resp)
+func (c *Client) RawCall(ctx context.Context, method, path string, params map[string]interface{}) (*utils.RawResponse, error) {
+	return c.base.RawCall(ctx, method, path, params)
+}
+
+// HostStats returns the health of every domain the client sends requests to,
+// including how often calls failed over from it.
+func (c *Client) HostStats() []utils.HostStats {
+	return c.base.HostStats()
 }
 
 // ClientBuilder helps build Client with a fluent interface.
@@ -128,6 +189,91 @@ func (b *ClientBuilder) SetTimeout(timeout int) *ClientBuilder {
 	return b
 }
 
+// SetRetryPolicy sets the retry policy for transient failures.
+func (b *ClientBuilder) SetRetryPolicy(policy *utils.RetryPolicy) *ClientBuilder {
+	b.configBuilder.SetRetryPolicy(policy)
+	return b
+}
+
+// SetHTTPClient sets a custom HTTP client for all requests.
+func (b *ClientBuilder) SetHTTPClient(client *http.Client) *ClientBuilder {
+	b.configBuilder.SetHTTPClient(client)
+	return b
+}
+
+// SetTransport sets a custom HTTP transport.
+func (b *ClientBuilder) SetTransport(transport http.RoundTripper) *ClientBuilder {
+	b.configBuilder.SetTransport(transport)
+	return b
+}
+
+// AddMiddleware appends middleware to the HTTP middleware chain.
+func (b *ClientBuilder) AddMiddleware(middlewares ...utils.Middleware) *ClientBuilder {
+	b.configBuilder.AddMiddleware(middlewares...)
+	return b
+}
+
+// SetLogger sets the structured logger.
+func (b *ClientBuilder) SetLogger(logger *slog.Logger) *ClientBuilder {
+	b.configBuilder.SetLogger(logger)
+	return b
+}
+
+// SetRedactor sets the redactor applied to logged payloads.
+func (b *ClientBuilder) SetRedactor(redactor *utils.Redactor) *ClientBuilder {
+	b.configBuilder.SetRedactor(redactor)
+	return b
+}
+
+// SetRateLimiter sets the client-side rate limiter, which may be shared between clients.
+func (b *ClientBuilder) SetRateLimiter(limiter *utils.RateLimiter) *ClientBuilder {
+	b.configBuilder.SetRateLimiter(limiter)
+	return b
+}
+
+// SetCircuitBreaker sets the circuit breaker guarding calls per host and endpoint group.
+func (b *ClientBuilder) SetCircuitBreaker(breaker *utils.CircuitBreaker) *ClientBuilder {
+	b.configBuilder.SetCircuitBreaker(breaker)
+	return b
+}
+
+// SetFailoverDomains sets the domains tried in order when Domain is unavailable.
+func (b *ClientBuilder) SetFailoverDomains(domains ...string) *ClientBuilder {
+	b.configBuilder.SetFailoverDomains(domains...)
+	return b
+}
+
+// SetTracer sets the tracer receiving one span per API call.
+func (b *ClientBuilder) SetTracer(tracer utils.Tracer) *ClientBuilder {
+	b.configBuilder.SetTracer(tracer)
+	return b
+}
+
+// SetMetrics sets the collector receiving per-call metrics.
+func (b *ClientBuilder) SetMetrics(metrics utils.Collector) *ClientBuilder {
+	b.configBuilder.SetMetrics(metrics)
+	return b
+}
+
+// SetCassette sets the cassette recording or replaying every call.
+func (b *ClientBuilder) SetCassette(cassette *utils.Cassette) *ClientBuilder {
+	b.configBuilder.SetCassette(cassette)
+	return b
+}
+
+// SetAllowUnverifiedResponses disables strict authenticity mode, accepting
+// success responses that were not decrypted with the ChainUp public key.
+func (b *ClientBuilder) SetAllowUnverifiedResponses(allow bool) *ClientBuilder {
+	b.configBuilder.SetAllowUnverifiedResponses(allow)
+	return b
+}
+
+// SetLookupBatch sets how lookups by ID lists are split into parallel requests.
+func (b *ClientBuilder) SetLookupBatch(settings utils.BatchSettings) *ClientBuilder {
+	b.configBuilder.SetLookupBatch(settings)
+	return b
+}
+
 // Build creates and returns a configured Client instance.
 func (b *ClientBuilder) Build() (*Client, error) {
 	config, err := b.configBuilder.Build()
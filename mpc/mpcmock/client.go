@@ -0,0 +1,123 @@
+// Package mpcmock provides mocks of the mpc client and its API facades,
+// for testing code that depends on mpc.Interface or the api.*Service
+// interfaces without a server.
+//
+// All facades of a Client share one Mock, which records every call in
+// order and serves the programmed responses. Methods are named like the
+// endpoints, "<Facade>.<Method>", and the plain and Context variants of a
+// method share one name:
+//
+//	client := mpcmock.NewClient()
+//	client.Return("WithdrawAPI.Withdraw", &types.WithdrawResponse{Code: "0"}, nil)
+//
+//	err := payouts.Run(ctx, client) // accepts an mpc.Interface
+//	calls := client.CallsTo("WithdrawAPI.Withdraw")
+package mpcmock
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/internal/mocktest"
+	"chainup.com/go-sdk/mpc"
+	"chainup.com/go-sdk/mpc/api"
+	"chainup.com/go-sdk/utils"
+)
+
+// Mock records the calls of the mocked methods and serves their programmed
+// responses, see Mock.Return and Mock.Handle.
+type Mock = mocktest.Mock
+
+// Call is a call recorded by a Mock.
+type Call = mocktest.Call
+
+// Handler computes the response of a mocked method from its arguments.
+type Handler = mocktest.Handler
+
+// ErrNotProgrammed is returned by a mocked method that has no programmed
+// response.
+var ErrNotProgrammed = mocktest.ErrNotProgrammed
+
+// Client is a mock mpc.Interface. Program and inspect it through the
+// embedded Mock; the facade fields share it.
+type Client struct {
+	*Mock
+
+	Wallet       *WalletAPI
+	Deposit      *DepositAPI
+	Withdraw     *WithdrawAPI
+	Web3         *Web3API
+	AutoSweep    *AutoSweepAPI
+	Notify       *NotifyAPI
+	WorkSpace    *WorkSpaceAPI
+	TronResource *TronResourceAPI
+}
+
+// NewClient creates a mock client with no programmed responses.
+func NewClient() *Client {
+	mock := &Mock{}
+	return &Client{
+		Mock:         mock,
+		Wallet:       &WalletAPI{Mock: mock},
+		Deposit:      &DepositAPI{Mock: mock},
+		Withdraw:     &WithdrawAPI{Mock: mock},
+		Web3:         &Web3API{Mock: mock},
+		AutoSweep:    &AutoSweepAPI{Mock: mock},
+		Notify:       &NotifyAPI{Mock: mock},
+		WorkSpace:    &WorkSpaceAPI{Mock: mock},
+		TronResource: &TronResourceAPI{Mock: mock},
+	}
+}
+
+// GetWalletAPI returns the WalletAPI mock.
+func (c *Client) GetWalletAPI() api.WalletService {
+	return c.Wallet
+}
+
+// GetDepositAPI returns the DepositAPI mock.
+func (c *Client) GetDepositAPI() api.DepositService {
+	return c.Deposit
+}
+
+// GetWithdrawAPI returns the WithdrawAPI mock.
+func (c *Client) GetWithdrawAPI() api.WithdrawService {
+	return c.Withdraw
+}
+
+// GetWeb3API returns the Web3API mock.
+func (c *Client) GetWeb3API() api.Web3Service {
+	return c.Web3
+}
+
+// GetAutoSweepAPI returns the AutoSweepAPI mock.
+func (c *Client) GetAutoSweepAPI() api.AutoSweepService {
+	return c.AutoSweep
+}
+
+// GetNotifyAPI returns the NotifyAPI mock.
+func (c *Client) GetNotifyAPI() api.NotifyService {
+	return c.Notify
+}
+
+// GetWorkSpaceAPI returns the WorkSpaceAPI mock.
+func (c *Client) GetWorkSpaceAPI() api.WorkSpaceService {
+	return c.WorkSpace
+}
+
+// GetTronResourceAPI returns the TronResourceAPI mock.
+func (c *Client) GetTronResourceAPI() api.TronResourceService {
+	return c.TronResource
+}
+
+// RawCall records a call to Client.RawCall.
+func (c *Client) RawCall(_ context.Context, method, path string, params map[string]interface{}) (*utils.RawResponse, error) {
+	return mocktest.Called[*utils.RawResponse](c.Mock, "Client.RawCall", method, path, params)
+}
+
+// HostStats records a call to Client.HostStats. It returns nil unless a
+// response is programmed.
+func (c *Client) HostStats() []utils.HostStats {
+	stats, _ := mocktest.Called[[]utils.HostStats](c.Mock, "Client.HostStats")
+	return stats
+}
+
+var _ mpc.Interface = (*Client)(nil)
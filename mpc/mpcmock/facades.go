@@ -0,0 +1,294 @@
+// Package mpcmock provides mocks of the mpc client and its API facades.
+package mpcmock
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/internal/mocktest"
+	"chainup.com/go-sdk/mpc/types"
+)
+
+// WalletAPI is a mock api.WalletService.
+type WalletAPI struct {
+	*Mock
+}
+
+// CreateWallet records a call to WalletAPI.CreateWallet.
+func (m *WalletAPI) CreateWallet(walletName string, showStatus types.AppShowStatus) (*types.WalletCreateResult, error) {
+	return m.CreateWalletContext(context.Background(), walletName, showStatus)
+}
+
+// CreateWalletContext records a call to WalletAPI.CreateWallet.
+func (m *WalletAPI) CreateWalletContext(_ context.Context, walletName string, showStatus types.AppShowStatus) (*types.WalletCreateResult, error) {
+	return mocktest.Called[*types.WalletCreateResult](m.Mock, "WalletAPI.CreateWallet", walletName, showStatus)
+}
+
+// CreateWalletAddress records a call to WalletAPI.CreateWalletAddress.
+func (m *WalletAPI) CreateWalletAddress(walletID int64, symbol string) (*types.WalletAddressResult, error) {
+	return m.CreateWalletAddressContext(context.Background(), walletID, symbol)
+}
+
+// CreateWalletAddressContext records a call to WalletAPI.CreateWalletAddress.
+func (m *WalletAPI) CreateWalletAddressContext(_ context.Context, walletID int64, symbol string) (*types.WalletAddressResult, error) {
+	return mocktest.Called[*types.WalletAddressResult](m.Mock, "WalletAPI.CreateWalletAddress", walletID, symbol)
+}
+
+// QueryWalletAddress records a call to WalletAPI.QueryWalletAddress.
+func (m *WalletAPI) QueryWalletAddress(args *types.QueryWalletAddressArgs) (*types.WalletAddressListResult, error) {
+	return m.QueryWalletAddressContext(context.Background(), args)
+}
+
+// QueryWalletAddressContext records a call to WalletAPI.QueryWalletAddress.
+func (m *WalletAPI) QueryWalletAddressContext(_ context.Context, args *types.QueryWalletAddressArgs) (*types.WalletAddressListResult, error) {
+	return mocktest.Called[*types.WalletAddressListResult](m.Mock, "WalletAPI.QueryWalletAddress", args)
+}
+
+// GetWalletAssets records a call to WalletAPI.GetWalletAssets.
+func (m *WalletAPI) GetWalletAssets(walletID int64, symbol string) (*types.WalletAssetsResult, error) {
+	return m.GetWalletAssetsContext(context.Background(), walletID, symbol)
+}
+
+// GetWalletAssetsContext records a call to WalletAPI.GetWalletAssets.
+func (m *WalletAPI) GetWalletAssetsContext(_ context.Context, walletID int64, symbol string) (*types.WalletAssetsResult, error) {
+	return mocktest.Called[*types.WalletAssetsResult](m.Mock, "WalletAPI.GetWalletAssets", walletID, symbol)
+}
+
+// ChangeWalletShowStatus records a call to WalletAPI.ChangeWalletShowStatus.
+func (m *WalletAPI) ChangeWalletShowStatus(walletIDs []int64, showStatus types.AppShowStatus) (bool, error) {
+	return m.ChangeWalletShowStatusContext(context.Background(), walletIDs, showStatus)
+}
+
+// ChangeWalletShowStatusContext records a call to WalletAPI.ChangeWalletShowStatus.
+func (m *WalletAPI) ChangeWalletShowStatusContext(_ context.Context, walletIDs []int64, showStatus types.AppShowStatus) (bool, error) {
+	return mocktest.Called[bool](m.Mock, "WalletAPI.ChangeWalletShowStatus", walletIDs, showStatus)
+}
+
+// WalletAddressInfo records a call to WalletAPI.WalletAddressInfo.
+func (m *WalletAPI) WalletAddressInfo(address, memo string) (*types.WalletAddressInfoResult, error) {
+	return m.WalletAddressInfoContext(context.Background(), address, memo)
+}
+
+// WalletAddressInfoContext records a call to WalletAPI.WalletAddressInfo.
+func (m *WalletAPI) WalletAddressInfoContext(_ context.Context, address, memo string) (*types.WalletAddressInfoResult, error) {
+	return mocktest.Called[*types.WalletAddressInfoResult](m.Mock, "WalletAPI.WalletAddressInfo", address, memo)
+}
+
+// DepositAPI is a mock api.DepositService.
+type DepositAPI struct {
+	*Mock
+}
+
+// GetDepositRecords records a call to DepositAPI.GetDepositRecords.
+func (m *DepositAPI) GetDepositRecords(ids []int64) (*types.DepositRecordResult, error) {
+	return m.GetDepositRecordsContext(context.Background(), ids)
+}
+
+// GetDepositRecordsContext records a call to DepositAPI.GetDepositRecords.
+func (m *DepositAPI) GetDepositRecordsContext(_ context.Context, ids []int64) (*types.DepositRecordResult, error) {
+	return mocktest.Called[*types.DepositRecordResult](m.Mock, "DepositAPI.GetDepositRecords", ids)
+}
+
+// SyncDepositRecords records a call to DepositAPI.SyncDepositRecords.
+func (m *DepositAPI) SyncDepositRecords(maxID int64) (*types.DepositRecordResult, error) {
+	return m.SyncDepositRecordsContext(context.Background(), maxID)
+}
+
+// SyncDepositRecordsContext records a call to DepositAPI.SyncDepositRecords.
+func (m *DepositAPI) SyncDepositRecordsContext(_ context.Context, maxID int64) (*types.DepositRecordResult, error) {
+	return mocktest.Called[*types.DepositRecordResult](m.Mock, "DepositAPI.SyncDepositRecords", maxID)
+}
+
+// WithdrawAPI is a mock api.WithdrawService.
+type WithdrawAPI struct {
+	*Mock
+}
+
+// Withdraw records a call to WithdrawAPI.Withdraw.
+func (m *WithdrawAPI) Withdraw(req *types.WithdrawRequest, needTransactionSign bool) (*types.WithdrawResponse, error) {
+	return m.WithdrawContext(context.Background(), req, needTransactionSign)
+}
+
+// WithdrawContext records a call to WithdrawAPI.Withdraw.
+func (m *WithdrawAPI) WithdrawContext(_ context.Context, req *types.WithdrawRequest, needTransactionSign bool) (*types.WithdrawResponse, error) {
+	return mocktest.Called[*types.WithdrawResponse](m.Mock, "WithdrawAPI.Withdraw", req, needTransactionSign)
+}
+
+// GetWithdrawRecords records a call to WithdrawAPI.GetWithdrawRecords.
+func (m *WithdrawAPI) GetWithdrawRecords(requestIDs []string) (*types.WithdrawRecordResult, error) {
+	return m.GetWithdrawRecordsContext(context.Background(), requestIDs)
+}
+
+// GetWithdrawRecordsContext records a call to WithdrawAPI.GetWithdrawRecords.
+func (m *WithdrawAPI) GetWithdrawRecordsContext(_ context.Context, requestIDs []string) (*types.WithdrawRecordResult, error) {
+	return mocktest.Called[*types.WithdrawRecordResult](m.Mock, "WithdrawAPI.GetWithdrawRecords", requestIDs)
+}
+
+// SyncWithdrawRecords records a call to WithdrawAPI.SyncWithdrawRecords.
+func (m *WithdrawAPI) SyncWithdrawRecords(maxID int64) (*types.WithdrawRecordResult, error) {
+	return m.SyncWithdrawRecordsContext(context.Background(), maxID)
+}
+
+// SyncWithdrawRecordsContext records a call to WithdrawAPI.SyncWithdrawRecords.
+func (m *WithdrawAPI) SyncWithdrawRecordsContext(_ context.Context, maxID int64) (*types.WithdrawRecordResult, error) {
+	return mocktest.Called[*types.WithdrawRecordResult](m.Mock, "WithdrawAPI.SyncWithdrawRecords", maxID)
+}
+
+// Web3API is a mock api.Web3Service.
+type Web3API struct {
+	*Mock
+}
+
+// CreateWeb3Trans records a call to Web3API.CreateWeb3Trans.
+func (m *Web3API) CreateWeb3Trans(req *types.Web3TransRequest, needTransactionSign bool) (*types.Web3TransResponse, error) {
+	return m.CreateWeb3TransContext(context.Background(), req, needTransactionSign)
+}
+
+// CreateWeb3TransContext records a call to Web3API.CreateWeb3Trans.
+func (m *Web3API) CreateWeb3TransContext(_ context.Context, req *types.Web3TransRequest, needTransactionSign bool) (*types.Web3TransResponse, error) {
+	return mocktest.Called[*types.Web3TransResponse](m.Mock, "Web3API.CreateWeb3Trans", req, needTransactionSign)
+}
+
+// AccelerationWeb3Trans records a call to Web3API.AccelerationWeb3Trans.
+func (m *Web3API) AccelerationWeb3Trans(args *types.Web3AccelerationArgs) (bool, error) {
+	return m.AccelerationWeb3TransContext(context.Background(), args)
+}
+
+// AccelerationWeb3TransContext records a call to Web3API.AccelerationWeb3Trans.
+func (m *Web3API) AccelerationWeb3TransContext(_ context.Context, args *types.Web3AccelerationArgs) (bool, error) {
+	return mocktest.Called[bool](m.Mock, "Web3API.AccelerationWeb3Trans", args)
+}
+
+// GetWeb3Records records a call to Web3API.GetWeb3Records.
+func (m *Web3API) GetWeb3Records(requestIDs []string) (*types.Web3RecordResult, error) {
+	return m.GetWeb3RecordsContext(context.Background(), requestIDs)
+}
+
+// GetWeb3RecordsContext records a call to Web3API.GetWeb3Records.
+func (m *Web3API) GetWeb3RecordsContext(_ context.Context, requestIDs []string) (*types.Web3RecordResult, error) {
+	return mocktest.Called[*types.Web3RecordResult](m.Mock, "Web3API.GetWeb3Records", requestIDs)
+}
+
+// SyncWeb3Records records a call to Web3API.SyncWeb3Records.
+func (m *Web3API) SyncWeb3Records(maxID int64) (*types.Web3RecordResult, error) {
+	return m.SyncWeb3RecordsContext(context.Background(), maxID)
+}
+
+// SyncWeb3RecordsContext records a call to Web3API.SyncWeb3Records.
+func (m *Web3API) SyncWeb3RecordsContext(_ context.Context, maxID int64) (*types.Web3RecordResult, error) {
+	return mocktest.Called[*types.Web3RecordResult](m.Mock, "Web3API.SyncWeb3Records", maxID)
+}
+
+// AutoSweepAPI is a mock api.AutoSweepService.
+type AutoSweepAPI struct {
+	*Mock
+}
+
+// AutoCollectSubWallets records a call to AutoSweepAPI.AutoCollectSubWallets.
+func (m *AutoSweepAPI) AutoCollectSubWallets(walletIDs []int64, symbol string) (*types.AutoCollectResult, error) {
+	return m.AutoCollectSubWalletsContext(context.Background(), walletIDs, symbol)
+}
+
+// AutoCollectSubWalletsContext records a call to AutoSweepAPI.AutoCollectSubWallets.
+func (m *AutoSweepAPI) AutoCollectSubWalletsContext(_ context.Context, walletIDs []int64, symbol string) (*types.AutoCollectResult, error) {
+	return mocktest.Called[*types.AutoCollectResult](m.Mock, "AutoSweepAPI.AutoCollectSubWallets", walletIDs, symbol)
+}
+
+// SetAutoCollectSymbol records a call to AutoSweepAPI.SetAutoCollectSymbol.
+func (m *AutoSweepAPI) SetAutoCollectSymbol(args *types.SetAutoCollectSymbolArgs) (bool, error) {
+	return m.SetAutoCollectSymbolContext(context.Background(), args)
+}
+
+// SetAutoCollectSymbolContext records a call to AutoSweepAPI.SetAutoCollectSymbol.
+func (m *AutoSweepAPI) SetAutoCollectSymbolContext(_ context.Context, args *types.SetAutoCollectSymbolArgs) (bool, error) {
+	return mocktest.Called[bool](m.Mock, "AutoSweepAPI.SetAutoCollectSymbol", args)
+}
+
+// SyncAutoCollectRecords records a call to AutoSweepAPI.SyncAutoCollectRecords.
+func (m *AutoSweepAPI) SyncAutoCollectRecords(maxID int64) (*types.AutoCollectRecordResult, error) {
+	return m.SyncAutoCollectRecordsContext(context.Background(), maxID)
+}
+
+// SyncAutoCollectRecordsContext records a call to AutoSweepAPI.SyncAutoCollectRecords.
+func (m *AutoSweepAPI) SyncAutoCollectRecordsContext(_ context.Context, maxID int64) (*types.AutoCollectRecordResult, error) {
+	return mocktest.Called[*types.AutoCollectRecordResult](m.Mock, "AutoSweepAPI.SyncAutoCollectRecords", maxID)
+}
+
+// NotifyAPI is a mock api.NotifyService.
+type NotifyAPI struct {
+	*Mock
+}
+
+// NotifyRequest records a call to NotifyAPI.NotifyRequest.
+func (m *NotifyAPI) NotifyRequest(cipher string) (*types.NotifyData, error) {
+	return mocktest.Called[*types.NotifyData](m.Mock, "NotifyAPI.NotifyRequest", cipher)
+}
+
+// WorkSpaceAPI is a mock api.WorkSpaceService.
+type WorkSpaceAPI struct {
+	*Mock
+}
+
+// GetSupportMainChain records a call to WorkSpaceAPI.GetSupportMainChain.
+func (m *WorkSpaceAPI) GetSupportMainChain() (*types.SupportMainChainResult, error) {
+	return m.GetSupportMainChainContext(context.Background())
+}
+
+// GetSupportMainChainContext records a call to WorkSpaceAPI.GetSupportMainChain.
+func (m *WorkSpaceAPI) GetSupportMainChainContext(_ context.Context) (*types.SupportMainChainResult, error) {
+	return mocktest.Called[*types.SupportMainChainResult](m.Mock, "WorkSpaceAPI.GetSupportMainChain")
+}
+
+// GetCoinDetails records a call to WorkSpaceAPI.GetCoinDetails.
+func (m *WorkSpaceAPI) GetCoinDetails(args *types.GetCoinDetailsArgs) (*types.CoinDetailsResult, error) {
+	return m.GetCoinDetailsContext(context.Background(), args)
+}
+
+// GetCoinDetailsContext records a call to WorkSpaceAPI.GetCoinDetails.
+func (m *WorkSpaceAPI) GetCoinDetailsContext(_ context.Context, args *types.GetCoinDetailsArgs) (*types.CoinDetailsResult, error) {
+	return mocktest.Called[*types.CoinDetailsResult](m.Mock, "WorkSpaceAPI.GetCoinDetails", args)
+}
+
+// GetLastBlockHeight records a call to WorkSpaceAPI.GetLastBlockHeight.
+func (m *WorkSpaceAPI) GetLastBlockHeight(symbol string) (*types.BlockHeightResult, error) {
+	return m.GetLastBlockHeightContext(context.Background(), symbol)
+}
+
+// GetLastBlockHeightContext records a call to WorkSpaceAPI.GetLastBlockHeight.
+func (m *WorkSpaceAPI) GetLastBlockHeightContext(_ context.Context, symbol string) (*types.BlockHeightResult, error) {
+	return mocktest.Called[*types.BlockHeightResult](m.Mock, "WorkSpaceAPI.GetLastBlockHeight", symbol)
+}
+
+// TronResourceAPI is a mock api.TronResourceService.
+type TronResourceAPI struct {
+	*Mock
+}
+
+// CreateTronDelegate records a call to TronResourceAPI.CreateTronDelegate.
+func (m *TronResourceAPI) CreateTronDelegate(args *types.TronBuyResourceArgs) (*types.TronBuyResourceResult, error) {
+	return m.CreateTronDelegateContext(context.Background(), args)
+}
+
+// CreateTronDelegateContext records a call to TronResourceAPI.CreateTronDelegate.
+func (m *TronResourceAPI) CreateTronDelegateContext(_ context.Context, args *types.TronBuyResourceArgs) (*types.TronBuyResourceResult, error) {
+	return mocktest.Called[*types.TronBuyResourceResult](m.Mock, "TronResourceAPI.CreateTronDelegate", args)
+}
+
+// GetBuyResourceRecords records a call to TronResourceAPI.GetBuyResourceRecords.
+func (m *TronResourceAPI) GetBuyResourceRecords(requestIds []string) (*types.TronBuyResourceRecordResult, error) {
+	return m.GetBuyResourceRecordsContext(context.Background(), requestIds)
+}
+
+// GetBuyResourceRecordsContext records a call to TronResourceAPI.GetBuyResourceRecords.
+func (m *TronResourceAPI) GetBuyResourceRecordsContext(_ context.Context, requestIds []string) (*types.TronBuyResourceRecordResult, error) {
+	return mocktest.Called[*types.TronBuyResourceRecordResult](m.Mock, "TronResourceAPI.GetBuyResourceRecords", requestIds)
+}
+
+// SyncBuyResourceRecords records a call to TronResourceAPI.SyncBuyResourceRecords.
+func (m *TronResourceAPI) SyncBuyResourceRecords(maxId int) (*types.TronBuyResourceRecordResult, error) {
+	return m.SyncBuyResourceRecordsContext(context.Background(), maxId)
+}
+
+// SyncBuyResourceRecordsContext records a call to TronResourceAPI.SyncBuyResourceRecords.
+func (m *TronResourceAPI) SyncBuyResourceRecordsContext(_ context.Context, maxId int) (*types.TronBuyResourceRecordResult, error) {
+	return mocktest.Called[*types.TronBuyResourceRecordResult](m.Mock, "TronResourceAPI.SyncBuyResourceRecords", maxId)
+}
@@ -0,0 +1,566 @@
+// Package mpctest provides an in-process fake MPC server for tests.
+package mpctest
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
+	"github.com/shopspring/decimal"
+)
+
+// handler serves one endpoint. s.mu is held while it runs.
+type handler func(s *Server, args map[string]interface{}) (interface{}, error)
+
+// routes maps endpoint paths to their handlers.
+var routes = map[string]handler{
+	"/api/mpc/sub_wallet/create":              (*Server).createWallet,
+	"/api/mpc/sub_wallet/create/address":      (*Server).createWalletAddress,
+	"/api/mpc/sub_wallet/get/address/list":    (*Server).walletAddressList,
+	"/api/mpc/sub_wallet/assets":              (*Server).walletAssets,
+	"/api/mpc/sub_wallet/change_show_status":  (*Server).changeShowStatus,
+	"/api/mpc/sub_wallet/address/info":        (*Server).walletAddressInfo,
+	"/api/mpc/wallet/open_coin":               (*Server).supportMainChain,
+	"/api/mpc/coin_list":                      (*Server).coinList,
+	"/api/mpc/chain_height":                   (*Server).chainHeight,
+	"/api/mpc/billing/deposit_list":           (*Server).depositList,
+	"/api/mpc/billing/sync_deposit_list":      (*Server).syncDepositList,
+	"/api/mpc/billing/withdraw":               (*Server).createWithdraw,
+	"/api/mpc/billing/withdraw_list":          (*Server).withdrawList,
+	"/api/mpc/billing/sync_withdraw_list":     (*Server).syncWithdrawList,
+	"/api/mpc/web3/trans/create":              (*Server).createWeb3Trans,
+	"/api/mpc/web3/pending":                   (*Server).accelerateWeb3Trans,
+	"/api/mpc/web3/trans_list":                (*Server).web3TransList,
+	"/api/mpc/web3/sync_trans_list":           (*Server).syncWeb3TransList,
+	"/api/mpc/auto_collect/sub_wallets":       (*Server).autoCollectWallets,
+	"/api/mpc/auto_collect/symbol/set":        (*Server).setAutoCollectSymbol,
+	"/api/mpc/billing/sync_auto_collect_list": (*Server).syncAutoCollectList,
+	"/api/mpc/tron/delegate":                  (*Server).createTronDelegate,
+	"/api/mpc/tron/delegate/trans_list":       (*Server).tronDelegateList,
+	"/api/mpc/tron/delegate/sync_trans_list":  (*Server).syncTronDelegateList,
+}
+
+var errInvalidArgs = &failure{code: CodeInvalidArgs, msg: "invalid parameters"}
+
+func (s *Server) createWallet(args map[string]interface{}) (interface{}, error) {
+	name := utils.StringField(args, "sub_wallet_name")
+	if name == "" {
+		return nil, errInvalidArgs
+	}
+	showStatus, ok := intArg(args, "app_show_status")
+	if !ok {
+		showStatus = int64(types.AppShowStatusShow)
+	}
+	return types.Wallet{WalletID: s.addWallet(name, showStatus).id}, nil
+}
+
+func (s *Server) createWalletAddress(args map[string]interface{}) (interface{}, error) {
+	walletID, coin, err := s.walletAndCoin(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.addAddress(walletID, coin.BaseSymbol).WalletAddress, nil
+}
+
+func (s *Server) walletAddressList(args map[string]interface{}) (interface{}, error) {
+	walletID, coin, err := s.walletAndCoin(args)
+	if err != nil {
+		return nil, err
+	}
+	maxID, _ := intArg(args, "max_id")
+	list := make([]types.WalletAddress, 0)
+	for _, a := range s.addresses {
+		if a.walletID == walletID && a.baseSymbol == coin.BaseSymbol {
+			list = append(list, a.WalletAddress)
+		}
+	}
+	return syncPage(list, func(a types.WalletAddress) int64 { return a.ID }, maxID), nil
+}
+
+func (s *Server) walletAssets(args map[string]interface{}) (interface{}, error) {
+	walletID, coin, err := s.walletAndCoin(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.asset(walletID, coin.Symbol), nil
+}
+
+func (s *Server) changeShowStatus(args map[string]interface{}) (interface{}, error) {
+	showStatus, ok := intArg(args, "app_show_status")
+	ids := stringSet(utils.StringField(args, "sub_wallet_ids"))
+	if !ok || len(ids) == 0 {
+		return nil, errInvalidArgs
+	}
+	wallets := make([]*wallet, 0, len(ids))
+	for id := range ids {
+		walletID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, errInvalidArgs
+		}
+		w, err := s.wallet(walletID)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, w)
+	}
+	for _, w := range wallets {
+		w.showStatus = showStatus
+	}
+	return true, nil
+}
+
+func (s *Server) walletAddressInfo(args map[string]interface{}) (interface{}, error) {
+	addr := utils.StringField(args, "address")
+	for _, a := range s.addresses {
+		if a.Address == addr {
+			return types.WalletAddressInfo{
+				WalletID:           a.walletID,
+				AddrType:           a.AddrType,
+				MergeAddressSymbol: a.baseSymbol,
+				Memo:               a.Memo,
+			}, nil
+		}
+	}
+	return nil, &failure{code: CodeAddressNotFound, msg: "address not found"}
+}
+
+func (s *Server) supportMainChain(map[string]interface{}) (interface{}, error) {
+	chains := make([]*types.SupportMainChain, 0)
+	for _, coin := range s.coins {
+		if coin.Symbol != coin.BaseSymbol {
+			continue
+		}
+		chains = append(chains, &types.SupportMainChain{
+			CoinNet:             coin.CoinNet,
+			Symbol:              coin.Symbol,
+			EnableWithdraw:      true,
+			EnableDeposit:       true,
+			SupportAcceleration: coin.SupportAcceleration,
+			IfOpenChain:         coin.IfOpenChain,
+			RealSymbol:          coin.RealSymbol,
+			SymbolAlias:         coin.SymbolAlias,
+			DisplayOrder:        len(chains) + 1,
+		})
+	}
+	return types.SupportMainChainData{OpenMainChain: chains, SupportMainChain: chains}, nil
+}
+
+func (s *Server) coinList(args map[string]interface{}) (interface{}, error) {
+	symbol, contract := utils.StringField(args, "symbol"), utils.StringField(args, "contract_address")
+	maxID, _ := intArg(args, "max_id")
+	limit, ok := intArg(args, "limit")
+	if !ok || limit <= 0 {
+		limit = SyncPageSize
+	}
+	list := make([]*types.CoinDetails, 0)
+	for _, coin := range s.coins {
+		if symbol != "" && coin.Symbol != symbol || contract != "" && !strings.EqualFold(coin.ContractAddress, contract) {
+			continue
+		}
+		if coin.ID.Int64() > maxID && int64(len(list)) < limit {
+			list = append(list, coin)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) chainHeight(args map[string]interface{}) (interface{}, error) {
+	height, ok := s.heights[utils.StringField(args, "base_symbol")]
+	if !ok {
+		return nil, &failure{code: CodeSymbolNotSupported, msg: "symbol not supported"}
+	}
+	return types.BlockHeight{BlockHeight: height}, nil
+}
+
+func (s *Server) depositList(args map[string]interface{}) (interface{}, error) {
+	ids := stringSet(utils.StringField(args, "ids"))
+	list := make([]*types.DepositRecord, 0)
+	for _, deposit := range s.deposits {
+		if ids[strconv.FormatInt(deposit.ID, 10)] {
+			list = append(list, deposit)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) syncDepositList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.deposits, func(d *types.DepositRecord) int64 { return d.ID }, maxID), nil
+}
+
+func (s *Server) createWithdraw(args map[string]interface{}) (interface{}, error) {
+	requestID := utils.StringField(args, "request_id")
+	amount, amountOK := decimalArg(args, "amount")
+	if requestID == "" || !amountOK || !amount.IsPositive() {
+		return nil, errInvalidArgs
+	}
+	walletID, coin, err := s.walletAndCoin(args)
+	if err != nil {
+		return nil, err
+	}
+	addressTo, memo, outputs := utils.StringField(args, "address_to"), utils.StringField(args, "memo"), utils.StringField(args, "outputs")
+	if err := s.verifySign(args, map[string]string{
+		"request_id":    requestID,
+		"sub_wallet_id": strconv.FormatInt(walletID, 10),
+		"symbol":        coin.Symbol,
+		"address_to":    addressTo,
+		"amount":        utils.StringField(args, "amount"),
+		"memo":          memo,
+		"outputs":       outputs,
+	}); err != nil {
+		return nil, err
+	}
+	if s.requestIDs[requestID] {
+		return nil, &failure{code: CodeDuplicateRequestID, msg: "request_id already exists"}
+	}
+	if !validAddress(coin, addressTo) {
+		return nil, &failure{code: CodeInvalidAddress, msg: "address invalid"}
+	}
+	if err := s.lock(walletID, coin.Symbol, amount); err != nil {
+		return nil, err
+	}
+	s.requestIDs[requestID] = true
+
+	addressFrom := utils.StringField(args, "from")
+	if addressFrom == "" {
+		addressFrom = s.walletAddress(walletID, coin.BaseSymbol).Address
+	}
+	now := utils.Timestamp{Time: time.Now()}
+	id := s.newID()
+	s.withdraws = append(s.withdraws, &types.WithdrawRecord{
+		ID:              id,
+		RequestID:       requestID,
+		WalletID:        walletID,
+		Symbol:          coin.Symbol,
+		ContractAddress: coin.ContractAddress,
+		BaseSymbol:      coin.BaseSymbol,
+		AddressFrom:     addressFrom,
+		AddressTo:       addressTo,
+		Memo:            memo,
+		Amount:          amount,
+		FeeSymbol:       coin.BaseSymbol,
+		Status:          StatusPending,
+		WithdrawSource:  2,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	return map[string]int64{"withdraw_id": id}, nil
+}
+
+func (s *Server) withdrawList(args map[string]interface{}) (interface{}, error) {
+	ids := stringSet(utils.StringField(args, "ids"))
+	list := make([]*types.WithdrawRecord, 0)
+	for _, withdraw := range s.withdraws {
+		if ids[withdraw.RequestID] {
+			list = append(list, withdraw)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) syncWithdrawList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.withdraws, func(w *types.WithdrawRecord) int64 { return w.ID }, maxID), nil
+}
+
+func (s *Server) createWeb3Trans(args map[string]interface{}) (interface{}, error) {
+	requestID := utils.StringField(args, "request_id")
+	amount, amountOK := decimalArg(args, "amount")
+	gasPrice, gasPriceOK := decimalArg(args, "gas_price")
+	gasLimit, gasLimitOK := intArg(args, "gas_limit")
+	contract, inputData := utils.StringField(args, "interactive_contract"), utils.StringField(args, "input_data")
+	if requestID == "" || !amountOK || amount.IsNegative() || !gasPriceOK || !gasLimitOK || contract == "" {
+		return nil, errInvalidArgs
+	}
+	walletID, ok := intArg(args, "sub_wallet_id")
+	if !ok {
+		return nil, errInvalidArgs
+	}
+	if _, err := s.wallet(walletID); err != nil {
+		return nil, err
+	}
+	coin, err := s.coin(utils.StringField(args, "main_chain_symbol"))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifySign(args, map[string]string{
+		"request_id":           requestID,
+		"sub_wallet_id":        strconv.FormatInt(walletID, 10),
+		"main_chain_symbol":    coin.Symbol,
+		"interactive_contract": contract,
+		"amount":               utils.StringField(args, "amount"),
+		"input_data":           inputData,
+	}); err != nil {
+		return nil, err
+	}
+	if s.requestIDs[requestID] {
+		return nil, &failure{code: CodeDuplicateRequestID, msg: "request_id already exists"}
+	}
+	if !validAddress(coin, contract) {
+		return nil, &failure{code: CodeInvalidAddress, msg: "address invalid"}
+	}
+	if err := s.lock(walletID, coin.Symbol, amount); err != nil {
+		return nil, err
+	}
+	s.requestIDs[requestID] = true
+
+	from := utils.StringField(args, "from")
+	if from == "" {
+		from = s.walletAddress(walletID, coin.BaseSymbol).Address
+	}
+	transType, _ := intArg(args, "trans_type")
+	now := utils.Timestamp{Time: time.Now()}
+	id := s.newID()
+	s.web3Trans = append(s.web3Trans, &types.Web3TransRecord{
+		ID:                  id,
+		RequestID:           requestID,
+		WalletID:            walletID,
+		Symbol:              coin.Symbol,
+		MainChainSymbol:     coin.BaseSymbol,
+		Amount:              amount,
+		FeeSymbol:           coin.BaseSymbol,
+		From:                from,
+		InteractiveContract: contract,
+		GasPrice:            gasPrice,
+		GasLimit:            utils.FlexInt(gasLimit),
+		InputData:           inputData,
+		TransType:           utils.FlexInt(transType),
+		DappName:            utils.StringField(args, "dapp_name"),
+		DappURL:             utils.StringField(args, "dapp_url"),
+		DappImg:             utils.StringField(args, "dapp_img"),
+		Status:              StatusPending,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	})
+	return map[string]int64{"trans_id": id}, nil
+}
+
+func (s *Server) accelerateWeb3Trans(args map[string]interface{}) (interface{}, error) {
+	id, idOK := intArg(args, "trans_id")
+	gasPrice, gasPriceOK := decimalArg(args, "gas_price")
+	gasLimit, gasLimitOK := intArg(args, "gas_limit")
+	if !idOK || !gasPriceOK || !gasLimitOK {
+		return nil, errInvalidArgs
+	}
+	for _, trans := range s.web3Trans {
+		if trans.ID != id {
+			continue
+		}
+		if trans.Status.Int64() != StatusPending {
+			return nil, &failure{code: CodeInvalidArgs, msg: "transaction is not pending"}
+		}
+		trans.GasPrice, trans.GasLimit = gasPrice, utils.FlexInt(gasLimit)
+		trans.UpdatedAt = utils.Timestamp{Time: time.Now()}
+		return true, nil
+	}
+	return nil, &failure{code: CodeRecordNotFound, msg: "transaction not found"}
+}
+
+func (s *Server) web3TransList(args map[string]interface{}) (interface{}, error) {
+	ids := stringSet(utils.StringField(args, "ids"))
+	list := make([]*types.Web3TransRecord, 0)
+	for _, trans := range s.web3Trans {
+		if ids[trans.RequestID] {
+			list = append(list, trans)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) syncWeb3TransList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.web3Trans, func(t *types.Web3TransRecord) int64 { return t.ID }, maxID), nil
+}
+
+func (s *Server) autoCollectWallets(args map[string]interface{}) (interface{}, error) {
+	coin, err := s.coin(utils.StringField(args, "symbol"))
+	if err != nil {
+		return nil, err
+	}
+	ids := stringSet(utils.StringField(args, "sub_wallet_ids"))
+	if len(ids) == 0 {
+		return nil, errInvalidArgs
+	}
+	walletIDs := make([]int64, 0, len(ids))
+	for id := range ids {
+		walletID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, errInvalidArgs
+		}
+		if _, err := s.wallet(walletID); err != nil {
+			return nil, err
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	if s.collectWallet == 0 {
+		s.collectWallet = s.addWallet("collect", int64(types.AppShowStatusHidden)).id
+		s.fuelWallet = s.addWallet("fueling", int64(types.AppShowStatusHidden)).id
+	}
+	for _, walletID := range walletIDs {
+		s.collectFrom[assetKey{walletID: walletID, symbol: coin.Symbol}] = true
+	}
+	return types.AutoCollectWallet{
+		CollectWalletId: s.collectWallet,
+		FuelingWalletId: s.fuelWallet,
+		Symbol:          coin.Symbol,
+	}, nil
+}
+
+func (s *Server) setAutoCollectSymbol(args map[string]interface{}) (interface{}, error) {
+	coin, err := s.coin(utils.StringField(args, "symbol"))
+	if err != nil {
+		return nil, err
+	}
+	collectMin, minOK := decimalArg(args, "collect_min")
+	fuelingLimit, limitOK := decimalArg(args, "fueling_limit")
+	if !minOK || !limitOK {
+		return nil, errInvalidArgs
+	}
+	s.collectConfig[coin.Symbol] = types.SetAutoCollectSymbolArgs{
+		Symbol:       coin.Symbol,
+		CollectMin:   collectMin,
+		FuelingLimit: fuelingLimit,
+	}
+	return true, nil
+}
+
+func (s *Server) syncAutoCollectList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.collects, func(c *types.AutoCollectRecord) int64 { return c.ID }, maxID), nil
+}
+
+func (s *Server) createTronDelegate(args map[string]interface{}) (interface{}, error) {
+	requestID, addressFrom := utils.StringField(args, "request_id"), utils.StringField(args, "address_from")
+	if requestID == "" || addressFrom == "" || utils.StringField(args, "service_charge_type") == "" {
+		return nil, errInvalidArgs
+	}
+	if s.requestIDs[requestID] {
+		return nil, &failure{code: CodeDuplicateRequestID, msg: "request_id already exists"}
+	}
+	coin, err := s.coin("TRX")
+	if err != nil {
+		return nil, err
+	}
+	addressTo := utils.StringField(args, "address_to")
+	for _, a := range []string{addressFrom, addressTo} {
+		if a != "" && !validAddress(coin, a) {
+			return nil, &failure{code: CodeInvalidAddress, msg: "address invalid"}
+		}
+	}
+	s.requestIDs[requestID] = true
+
+	buyType, _ := intArg(args, "buy_type")
+	resourceType, _ := intArg(args, "resource_type")
+	energyNum, _ := intArg(args, "energy_num")
+	netNum, _ := intArg(args, "net_num")
+	id := s.newID()
+	s.delegates = append(s.delegates, &types.TronBuyResourceRecord{
+		ID:              int(id),
+		RequestID:       requestID,
+		AddressFrom:     addressFrom,
+		ContractAddress: utils.StringField(args, "contract_address"),
+		AddressTo:       addressTo,
+		ResourceType:    utils.FlexInt(resourceType),
+		BuyType:         utils.FlexInt(buyType),
+		NetNum:          utils.FlexInt(netNum),
+		EnergyNum:       utils.FlexInt(energyNum),
+		Status:          StatusPending,
+	})
+	return types.TronBuyResource{TransID: id}, nil
+}
+
+func (s *Server) tronDelegateList(args map[string]interface{}) (interface{}, error) {
+	ids := stringSet(utils.StringField(args, "ids"))
+	list := make([]*types.TronBuyResourceRecord, 0)
+	for _, delegate := range s.delegates {
+		if ids[delegate.RequestID] {
+			list = append(list, delegate)
+		}
+	}
+	return list, nil
+}
+
+func (s *Server) syncTronDelegateList(args map[string]interface{}) (interface{}, error) {
+	maxID, _ := intArg(args, "max_id")
+	return syncPage(s.delegates, func(d *types.TronBuyResourceRecord) int64 { return int64(d.ID) }, maxID), nil
+}
+
+// walletAndCoin returns the existing sub-wallet and coin named by the
+// sub_wallet_id and symbol args.
+func (s *Server) walletAndCoin(args map[string]interface{}) (int64, *types.CoinDetails, error) {
+	walletID, ok := intArg(args, "sub_wallet_id")
+	if !ok {
+		return 0, nil, errInvalidArgs
+	}
+	if _, err := s.wallet(walletID); err != nil {
+		return 0, nil, err
+	}
+	coin, err := s.coin(utils.StringField(args, "symbol"))
+	if err != nil {
+		return 0, nil, err
+	}
+	return walletID, coin, nil
+}
+
+// lock moves amount from the normal to the locked balance of a sub-wallet.
+func (s *Server) lock(walletID int64, symbol string, amount decimal.Decimal) error {
+	asset := s.asset(walletID, symbol)
+	if asset.NormalBalance.LessThan(amount) {
+		return &failure{code: CodeInsufficientBalance, msg: "insufficient balance"}
+	}
+	asset.NormalBalance = asset.NormalBalance.Sub(amount)
+	asset.LockBalance = asset.LockBalance.Add(amount)
+	return nil
+}
+
+// validAddress reports whether address matches the address format of coin.
+func validAddress(coin *types.CoinDetails, address string) bool {
+	if address == "" {
+		return false
+	}
+	if coin.AddressRegex == "" {
+		return true
+	}
+	re, err := regexp.Compile(coin.AddressRegex)
+	return err == nil && re.MatchString(address)
+}
+
+// intArg returns an integer arg, sent either as a number or as a string.
+func intArg(args map[string]interface{}, key string) (int64, bool) {
+	switch v := args[key].(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// decimalArg returns a decimal arg, sent either as a number or as a string.
+func decimalArg(args map[string]interface{}, key string) (decimal.Decimal, bool) {
+	value := utils.StringField(args, key)
+	if value == "" {
+		return decimal.Zero, false
+	}
+	d, err := decimal.NewFromString(value)
+	return d, err == nil
+}
+
+// stringSet splits a comma separated ID list.
+func stringSet(ids string) map[string]bool {
+	set := make(map[string]bool)
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			set[id] = true
+		}
+	}
+	return set
+}
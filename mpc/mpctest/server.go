@@ -0,0 +1,970 @@
+// Package mpctest provides an in-process fake MPC server for tests.
+//
+// The server speaks the real MPC protocol: requests carry the app_id, the
+// API-KEY header and the request args encrypted with the client private key,
+// and responses carry the code/msg/data document encrypted with the server
+// private key. Withdrawals and web3 transactions are checked against the
+// transaction signing key. It keeps an in-memory model of sub-wallets,
+// addresses, assets, chains and transactions, so every mpc/api method works
+// end to end.
+//
+// Transactions move on the simulated chains only when a test advances them:
+// AdvanceBlocks mines pending transactions and adds confirmations, and
+// every status change is posted as an encrypted notification to the callback
+// URL:
+//
+//	server := mpctest.NewServer()
+//	defer server.Close()
+//	server.SetCallbackURL(webhook.URL)
+//
+//	client, err := server.NewClient()
+//	wallet, err := client.GetWalletAPI().CreateWallet("alice", types.AppShowStatusShow)
+//	deposit, err := server.InjectDeposit(wallet.Data.WalletID, "ETH", decimal.RequireFromString("1.5"))
+//	server.AdvanceBlocks("ETH", 12)
+package mpctest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"chainup.com/go-sdk/mpc"
+	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils"
+	"chainup.com/go-sdk/utils/mpcsign"
+	"chainup.com/go-sdk/utils/sdkerrors"
+	"github.com/shopspring/decimal"
+)
+
+// AppID is the app ID the server accepts.
+const AppID = "mpctest-app"
+
+// APIKey is the API key the server expects in the API-KEY header.
+const APIKey = "mpctest-api-key"
+
+// SyncPageSize is the number of records returned by the sync endpoints.
+const SyncPageSize = 100
+
+// NotifyAck is the callback response body that acknowledges a notification.
+const NotifyAck = "SUCCESS"
+
+// Response codes returned by the server. The codes of the sdkerrors
+// categories are the ChainUp codes registered by default in sdkerrors, so
+// errors returned by the server match errors.Is.
+const (
+	CodeSuccess             = "0"
+	CodeInvalidArgs         = "1001"
+	CodeInvalidAppID        = "1002"
+	CodeInvalidAPIKey       = "1003"
+	CodeInvalidSign         = "1004"
+	CodeRateLimited         = sdkerrors.CodeRateLimited
+	CodeWalletNotFound      = "2001"
+	CodeSymbolNotSupported  = "2003"
+	CodeAddressNotFound     = "2004"
+	CodeRecordNotFound      = "2005"
+	CodeInsufficientBalance = sdkerrors.CodeInsufficientBalance
+	CodeDuplicateRequestID  = sdkerrors.CodeDuplicateRequestID
+	CodeInvalidAddress      = sdkerrors.CodeInvalidAddress
+)
+
+// Statuses of deposits, withdrawals, web3 transactions, auto-collect and
+// TRON delegate records on the server. A transaction is pending until it is
+// mined, then confirming until it has the confirmations of its coin.
+const (
+	StatusPending    = 1000
+	StatusConfirming = 1900
+	StatusSuccess    = 2000
+	StatusFailed     = 2400
+)
+
+// InitialBlockHeight is the height of every chain when the server starts.
+const InitialBlockHeight = 1000
+
+// Request is a call received by the server, with its decrypted args.
+type Request struct {
+	Path string
+	Args map[string]interface{}
+}
+
+// Notification is a notification posted to the callback URL.
+type Notification struct {
+	// Data is the notification, before encryption.
+	Data types.NotifyData
+	// StatusCode and Body are the callback response; StatusCode is 0 when
+	// the callback could not be reached.
+	StatusCode int
+	Body       string
+	// Err is the error posting the notification, if any.
+	Err error
+}
+
+// Acked reports whether the callback acknowledged the notification.
+func (n Notification) Acked() bool {
+	return n.Err == nil && n.StatusCode == http.StatusOK && strings.TrimSpace(n.Body) == NotifyAck
+}
+
+// Server is a fake MPC server. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server, to be used as the client domain.
+	URL string
+
+	server           *httptest.Server
+	crypto           *utils.RSACryptoProvider
+	signVerifier     *utils.RSACryptoProvider
+	clientPrivateKey string
+	serverPublicKey  string
+	signPrivateKey   string
+	notifyClient     *http.Client
+
+	mu             sync.Mutex
+	nextID         int64
+	requireSign    bool
+	callbackURL    string
+	wallets        []*wallet
+	coins          []*types.CoinDetails
+	heights        map[string]int64
+	assets         map[assetKey]*types.WalletAssets
+	addresses      []*address
+	deposits       []*types.DepositRecord
+	withdraws      []*types.WithdrawRecord
+	web3Trans      []*types.Web3TransRecord
+	collects       []*types.AutoCollectRecord
+	delegates      []*types.TronBuyResourceRecord
+	collectConfig  map[string]types.SetAutoCollectSymbolArgs
+	collectFrom    map[assetKey]bool
+	collectHeights map[int64]utils.FlexInt
+	collectWallet  int64
+	fuelWallet     int64
+	requestIDs     map[string]bool
+	errors         map[string][]failure
+	requests       []Request
+	outbox         []types.NotifyData
+	notifications  []Notification
+}
+
+// wallet is a sub-wallet.
+type wallet struct {
+	id         int64
+	name       string
+	showStatus int64
+}
+
+// address is a sub-wallet address on one chain.
+type address struct {
+	types.WalletAddress
+	walletID   int64
+	baseSymbol string
+}
+
+// assetKey identifies the assets of a sub-wallet in one coin.
+type assetKey struct {
+	walletID int64
+	symbol   string
+}
+
+// failure is an error response.
+type failure struct {
+	code string
+	msg  string
+}
+
+func (f *failure) Error() string {
+	return f.code + ": " + f.msg
+}
+
+// NewServer starts a fake MPC server with fresh key pairs and the ETH,
+// USDT-ERC20 and TRX coins. It panics when the keys cannot be generated, like
+// httptest.NewServer does when it cannot listen. The caller should Close it
+// when done.
+func NewServer() *Server {
+	clientPrivateKey, clientPublicKey := generateKeyPair()
+	serverPrivateKey, serverPublicKey := generateKeyPair()
+	signPrivateKey, signPublicKey := generateKeyPair()
+	crypto, err := utils.NewRSACryptoProvider(serverPrivateKey, clientPublicKey, utils.DefaultCharset)
+	if err != nil {
+		panic("mpctest: " + err.Error())
+	}
+	signVerifier, err := utils.NewRSACryptoProvider("", signPublicKey, utils.DefaultCharset)
+	if err != nil {
+		panic("mpctest: " + err.Error())
+	}
+
+	s := &Server{
+		crypto:           crypto,
+		signVerifier:     signVerifier,
+		clientPrivateKey: clientPrivateKey,
+		serverPublicKey:  serverPublicKey,
+		signPrivateKey:   signPrivateKey,
+		notifyClient:     &http.Client{Timeout: 10 * time.Second},
+		heights:          make(map[string]int64),
+		assets:           make(map[assetKey]*types.WalletAssets),
+		collectConfig:    make(map[string]types.SetAutoCollectSymbolArgs),
+		collectFrom:      make(map[assetKey]bool),
+		collectHeights:   make(map[int64]utils.FlexInt),
+		requestIDs:       make(map[string]bool),
+		errors:           make(map[string][]failure),
+	}
+	s.AddCoin(types.CoinDetails{
+		Symbol: "ETH", BaseSymbol: "ETH", RealSymbol: "ETH", CoinNet: "ETH", SymbolAlias: "ETH",
+		Decimals: 18, AddressRegex: `^0x[0-9a-fA-F]{40}$`, MergeAddressSymbol: "ETH", IfOpenChain: true,
+		SupportAcceleration: true, DepositConfirmation: 12, WithdrawConfirmation: 12,
+	})
+	s.AddCoin(types.CoinDetails{
+		Symbol: "USDT-ERC20", BaseSymbol: "ETH", RealSymbol: "USDT", CoinNet: "ETH", SymbolAlias: "USDT",
+		ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", Decimals: 6,
+		AddressRegex: `^0x[0-9a-fA-F]{40}$`, MergeAddressSymbol: "ETH", IfOpenChain: true,
+		DepositConfirmation: 12, WithdrawConfirmation: 12,
+	})
+	s.AddCoin(types.CoinDetails{
+		Symbol: "TRX", BaseSymbol: "TRX", RealSymbol: "TRX", CoinNet: "TRX", SymbolAlias: "TRX",
+		Decimals: 6, AddressRegex: `^T[1-9A-HJ-NP-Za-km-z]{33}$`, MergeAddressSymbol: "TRX", IfOpenChain: true,
+		DepositConfirmation: 1, WithdrawConfirmation: 1,
+	})
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// generateKeyPair generates a key pair for NewServer.
+func generateKeyPair() (privateKeyPEM, publicKeyPEM string) {
+	privateKeyPEM, publicKeyPEM, err := utils.GenerateRSAKeyPair(2048)
+	if err != nil {
+		panic("mpctest: " + err.Error())
+	}
+	return privateKeyPEM, publicKeyPEM
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Config returns a client config pointing at the server, including the
+// transaction signing key.
+func (s *Server) Config() *mpc.Config {
+	return &mpc.Config{
+		Domain:         s.URL,
+		AppID:          AppID,
+		RsaPrivateKey:  s.clientPrivateKey,
+		WaasPublicKey:  s.serverPublicKey,
+		ApiKey:         APIKey,
+		SignPrivateKey: s.signPrivateKey,
+	}
+}
+
+// NewClient creates a client talking to the server.
+func (s *Server) NewClient() (*mpc.Client, error) {
+	return mpc.NewMpcClient(s.Config())
+}
+
+// SetRequireSign makes withdrawals and web3 transactions without a sign
+// fail. A sign that is present is always verified.
+func (s *Server) SetRequireSign(require bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requireSign = require
+}
+
+// SetCallbackURL sets the URL notifications are posted to. Notifications are
+// posted as a form with the encrypted NotifyData in the data field. An empty
+// URL turns notifications off.
+func (s *Server) SetCallbackURL(callbackURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbackURL = callbackURL
+}
+
+// Notifications returns the notifications posted so far, in order.
+func (s *Server) Notifications() []Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Notification(nil), s.notifications...)
+}
+
+// RetryNotifications posts the notifications that were not acknowledged
+// again, as the real service does, and returns how many were retried.
+func (s *Server) RetryNotifications() int {
+	s.mu.Lock()
+	var retry []types.NotifyData
+	for _, n := range s.notifications {
+		if !n.Acked() {
+			retry = append(retry, n.Data)
+		}
+	}
+	s.outbox = append(s.outbox, retry...)
+	s.mu.Unlock()
+
+	s.flush()
+	return len(retry)
+}
+
+// InjectError makes the next call to path, e.g. "/api/mpc/billing/withdraw",
+// fail with code and msg. Errors injected for the same path are returned in
+// order.
+func (s *Server) InjectError(path, code, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[path] = append(s.errors[path], failure{code: code, msg: msg})
+}
+
+// Requests returns the calls received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// serveHTTP decrypts a request, dispatches it and encrypts the response.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, ok := routes[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Form.Get("app_id") != AppID {
+		s.reply(w, nil, &failure{code: CodeInvalidAppID, msg: "invalid app_id"})
+		return
+	}
+	if r.Header.Get("API-KEY") != APIKey {
+		s.reply(w, nil, &failure{code: CodeInvalidAPIKey, msg: "invalid api key"})
+		return
+	}
+	plain, err := s.crypto.DecryptWithPublicKey(r.Form.Get("data"))
+	if err != nil {
+		s.reply(w, nil, &failure{code: CodeInvalidArgs, msg: "failed to decrypt data"})
+		return
+	}
+	var args map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(plain))
+	decoder.UseNumber()
+	if err := decoder.Decode(&args); err != nil {
+		s.reply(w, nil, &failure{code: CodeInvalidArgs, msg: "invalid data"})
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: r.URL.Path, Args: args})
+	var data interface{}
+	if queued := s.errors[r.URL.Path]; len(queued) > 0 {
+		s.errors[r.URL.Path] = queued[1:]
+		err = &queued[0]
+	} else {
+		data, err = handler(s, args)
+	}
+	// Encode while the lock is held: data points into the model.
+	document, encodeErr := encodeDocument(data, err)
+	s.mu.Unlock()
+
+	if encodeErr != nil {
+		http.Error(w, encodeErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.write(w, document)
+}
+
+// reply writes the encrypted response document of data or err.
+func (s *Server) reply(w http.ResponseWriter, data interface{}, err error) {
+	document, err := encodeDocument(data, err)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.write(w, document)
+}
+
+// encodeDocument encodes the code/msg/data response document.
+func encodeDocument(data interface{}, err error) ([]byte, error) {
+	document := map[string]interface{}{"code": CodeSuccess, "msg": "success", "data": data}
+	var f *failure
+	if errors.As(err, &f) {
+		document = map[string]interface{}{"code": f.code, "msg": f.msg, "data": nil}
+	} else if err != nil {
+		document = map[string]interface{}{"code": CodeInvalidArgs, "msg": err.Error(), "data": nil}
+	}
+	return json.Marshal(document)
+}
+
+// write encrypts document into the response envelope.
+func (s *Server) write(w http.ResponseWriter, document []byte) {
+	cipher, err := s.crypto.EncryptWithPrivateKey(string(document))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var body bytes.Buffer
+	_ = json.NewEncoder(&body).Encode(map[string]string{"data": cipher})
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body.Bytes())
+}
+
+// verifySign checks the sign arg of a withdrawal or web3 transaction against
+// the signed params. s.mu must be held.
+func (s *Server) verifySign(args map[string]interface{}, signed map[string]string) error {
+	sign := utils.StringField(args, "sign")
+	if sign == "" {
+		if s.requireSign {
+			return &failure{code: CodeInvalidSign, msg: "sign is required"}
+		}
+		return nil
+	}
+	digest := fmt.Sprintf("%x", md5.Sum([]byte(strings.ToLower(mpcsign.ParamsSort(signed)))))
+	if ok, err := s.signVerifier.VerifyWithPublicKey(digest, sign); err != nil || !ok {
+		return &failure{code: CodeInvalidSign, msg: "sign verification failed"}
+	}
+	return nil
+}
+
+// AddCoin adds a coin, or replaces the coin with the same symbol. The chain of
+// a new base symbol starts at InitialBlockHeight.
+func (s *Server) AddCoin(coin types.CoinDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.heights[coin.BaseSymbol]; !ok {
+		s.heights[coin.BaseSymbol] = InitialBlockHeight
+	}
+	for i, existing := range s.coins {
+		if existing.Symbol == coin.Symbol {
+			coin.ID = existing.ID
+			s.coins[i] = &coin
+			return
+		}
+	}
+	coin.ID = utils.FlexInt(len(s.coins) + 1)
+	s.coins = append(s.coins, &coin)
+}
+
+// CreateWallet creates a sub-wallet, as CreateWallet does, and returns its ID.
+func (s *Server) CreateWallet(name string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addWallet(name, int64(types.AppShowStatusShow)).id
+}
+
+// SetBalance sets the normal balance of a sub-wallet in symbol.
+func (s *Server) SetBalance(walletID int64, symbol string, balance decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.asset(walletID, symbol).NormalBalance = balance
+}
+
+// Assets returns the assets of a sub-wallet in symbol.
+func (s *Server) Assets(walletID int64, symbol string) types.WalletAssets {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.asset(walletID, symbol)
+}
+
+// BlockHeight returns the height of the chain of baseSymbol.
+func (s *Server) BlockHeight(baseSymbol string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.heights[baseSymbol]
+}
+
+// InjectDeposit records an incoming deposit of amount to the address of a
+// sub-wallet, creating the address. The deposit is pending until
+// AdvanceBlocks mines it.
+func (s *Server) InjectDeposit(walletID int64, symbol string, amount decimal.Decimal) (types.DepositRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.wallet(walletID); err != nil {
+		return types.DepositRecord{}, err
+	}
+	coin, err := s.coin(symbol)
+	if err != nil {
+		return types.DepositRecord{}, err
+	}
+
+	now := utils.Timestamp{Time: time.Now()}
+	id := s.newID()
+	deposit := &types.DepositRecord{
+		ID:              id,
+		WalletID:        walletID,
+		Symbol:          coin.Symbol,
+		BaseSymbol:      coin.BaseSymbol,
+		ContractAddress: coin.ContractAddress,
+		Amount:          amount,
+		AddressFrom:     externalAddress(coin.BaseSymbol, id),
+		AddressTo:       s.walletAddress(walletID, coin.BaseSymbol).Address,
+		Txid:            txid(id),
+		Status:          StatusPending,
+		DepositType:     1,
+		KytStatus:       "PASS",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	s.deposits = append(s.deposits, deposit)
+	return *deposit, nil
+}
+
+// AdvanceBlocks adds n blocks to the chain of baseSymbol. Pending
+// transactions on the chain are mined in the first new block, and
+// transactions reaching the confirmations of their coin succeed: deposits are
+// credited, withdrawals and web3 transactions release their locked amount,
+// and collected funds reach the collection wallet. Collections started by a
+// deposit succeeding are mined by the next call. A notification is posted
+// for every transaction that changed. It returns the new height.
+func (s *Server) AdvanceBlocks(baseSymbol string, n int) (int64, error) {
+	s.mu.Lock()
+	height, ok := s.heights[baseSymbol]
+	if !ok || n <= 0 {
+		s.mu.Unlock()
+		if !ok {
+			return 0, fmt.Errorf("mpctest: no chain %s", baseSymbol)
+		}
+		return height, nil
+	}
+	mined := height + 1
+	height += int64(n)
+	s.heights[baseSymbol] = height
+	// Collect before deposits: a deposit succeeding now starts a collection
+	// that is mined by the next call.
+	s.advanceCollects(baseSymbol, mined, height)
+	s.advanceDeposits(baseSymbol, mined, height)
+	s.advanceWithdraws(baseSymbol, mined, height)
+	s.advanceWeb3Trans(baseSymbol, mined, height)
+	s.advanceDelegates(baseSymbol)
+	s.mu.Unlock()
+
+	s.flush()
+	return height, nil
+}
+
+// FailWithdraw marks a withdrawal in progress as failed and refunds the
+// sub-wallet.
+func (s *Server) FailWithdraw(id int64) error {
+	s.mu.Lock()
+	withdraw, err := s.withdraw(id)
+	if err == nil && withdraw.Status.Int64() != StatusPending && withdraw.Status.Int64() != StatusConfirming {
+		err = fmt.Errorf("mpctest: withdrawal %d is no longer in progress", id)
+	}
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	withdraw.Status = StatusFailed
+	withdraw.UpdatedAt = utils.Timestamp{Time: time.Now()}
+	s.release(withdraw.WalletID, withdraw.Symbol, withdraw.Amount, true)
+	s.notifyWithdraw(withdraw)
+	s.mu.Unlock()
+
+	s.flush()
+	return nil
+}
+
+// advanceDeposits moves the deposits on a chain to a new height. s.mu must be held.
+func (s *Server) advanceDeposits(baseSymbol string, mined, height int64) {
+	for _, deposit := range s.deposits {
+		if deposit.BaseSymbol != baseSymbol {
+			continue
+		}
+		coin, _ := s.coin(deposit.Symbol)
+		changed, succeeded := settle(&deposit.Status, &deposit.Confirmations, &deposit.TxHeight, mined, height, coin.DepositConfirmation.Int64())
+		if !changed {
+			continue
+		}
+		deposit.UpdatedAt = utils.Timestamp{Time: time.Now()}
+		if succeeded {
+			asset := s.asset(deposit.WalletID, deposit.Symbol)
+			asset.NormalBalance = asset.NormalBalance.Add(deposit.Amount)
+			s.startCollect(deposit)
+		}
+		s.notifyDeposit(deposit)
+	}
+}
+
+// advanceWithdraws moves the withdrawals on a chain to a new height. s.mu must be held.
+func (s *Server) advanceWithdraws(baseSymbol string, mined, height int64) {
+	for _, withdraw := range s.withdraws {
+		if withdraw.BaseSymbol != baseSymbol {
+			continue
+		}
+		coin, _ := s.coin(withdraw.Symbol)
+		changed, succeeded := settle(&withdraw.Status, &withdraw.Confirmations, &withdraw.TxHeight, mined, height, coin.WithdrawConfirmation.Int64())
+		if !changed {
+			continue
+		}
+		withdraw.Txid = txid(withdraw.ID)
+		withdraw.UpdatedAt = utils.Timestamp{Time: time.Now()}
+		if succeeded {
+			s.release(withdraw.WalletID, withdraw.Symbol, withdraw.Amount, false)
+		}
+		s.notifyWithdraw(withdraw)
+	}
+}
+
+// advanceWeb3Trans moves the web3 transactions on a chain to a new height. s.mu must be held.
+func (s *Server) advanceWeb3Trans(baseSymbol string, mined, height int64) {
+	for _, trans := range s.web3Trans {
+		if trans.MainChainSymbol != baseSymbol {
+			continue
+		}
+		coin, _ := s.coin(trans.Symbol)
+		changed, succeeded := settle(&trans.Status, &trans.Confirmations, &trans.TxHeight, mined, height, coin.WithdrawConfirmation.Int64())
+		if !changed {
+			continue
+		}
+		trans.Txid = txid(trans.ID)
+		trans.UpdatedAt = utils.Timestamp{Time: time.Now()}
+		if succeeded {
+			s.release(trans.WalletID, trans.Symbol, trans.Amount, false)
+		}
+		s.notifyWeb3(trans)
+	}
+}
+
+// advanceCollects moves the auto-collect transactions on a chain to a new
+// height. s.mu must be held.
+func (s *Server) advanceCollects(baseSymbol string, mined, height int64) {
+	for _, collect := range s.collects {
+		if collect.BaseSymbol != baseSymbol {
+			continue
+		}
+		coin, _ := s.coin(collect.Symbol)
+		// AutoCollectRecord has no tx_height, so the server keeps it aside.
+		txHeight := s.collectHeights[collect.ID]
+		changed, succeeded := settle(&collect.Status, &collect.Confirmations, &txHeight, mined, height, coin.WithdrawConfirmation.Int64())
+		s.collectHeights[collect.ID] = txHeight
+		if !changed {
+			continue
+		}
+		collect.Txid = txid(collect.ID)
+		collect.UpdatedAt = utils.Timestamp{Time: time.Now()}
+		if succeeded {
+			from := s.asset(collect.WalletID, collect.Symbol)
+			from.CollectingBalance = from.CollectingBalance.Sub(collect.Amount)
+			to := s.asset(s.collectWallet, collect.Symbol)
+			to.NormalBalance = to.NormalBalance.Add(collect.Amount)
+		}
+		s.notifyCollect(collect)
+	}
+}
+
+// advanceDelegates completes the pending TRON delegations when a block is
+// mined on the TRX chain. s.mu must be held.
+func (s *Server) advanceDelegates(baseSymbol string) {
+	if baseSymbol != "TRX" {
+		return
+	}
+	now := utils.Timestamp{Time: time.Now()}
+	for _, delegate := range s.delegates {
+		if delegate.Status.Int64() != StatusPending {
+			continue
+		}
+		delegate.Status = StatusSuccess
+		if delegate.EnergyNum.Int64() > 0 {
+			delegate.EnergyTxid = txid(s.newID())
+			delegate.EnergyTime = now
+		}
+		if delegate.NetNum.Int64() > 0 {
+			delegate.NetTxid = txid(s.newID())
+			delegate.NetTime = now
+		}
+		s.notifyDelegate(delegate)
+	}
+}
+
+// settle moves a transaction to a chain height: a pending transaction is
+// mined at mined, and a mined one succeeds once it has required
+// confirmations. It reports whether the transaction changed and whether it
+// just succeeded.
+func settle(status, confirmations, txHeight *utils.FlexInt, mined, height, required int64) (changed, succeeded bool) {
+	switch status.Int64() {
+	case StatusPending:
+		*status = StatusConfirming
+		*txHeight = utils.FlexInt(mined)
+		changed = true
+	case StatusConfirming:
+	default:
+		return false, false
+	}
+
+	if count := height - txHeight.Int64() + 1; count != confirmations.Int64() {
+		*confirmations = utils.FlexInt(count)
+		changed = true
+	}
+	if confirmations.Int64() >= required {
+		*status = StatusSuccess
+		return true, true
+	}
+	return changed, false
+}
+
+// release unlocks an amount locked by a withdrawal or web3 transaction,
+// returning it to the normal balance when refund is set. s.mu must be held.
+func (s *Server) release(walletID int64, symbol string, amount decimal.Decimal, refund bool) {
+	asset := s.asset(walletID, symbol)
+	asset.LockBalance = asset.LockBalance.Sub(amount)
+	if refund {
+		asset.NormalBalance = asset.NormalBalance.Add(amount)
+	}
+}
+
+// startCollect starts collecting a successful deposit when auto-collect is
+// on for its sub-wallet and the amount reaches the collect_min of its coin.
+// s.mu must be held.
+func (s *Server) startCollect(deposit *types.DepositRecord) {
+	if !s.collectFrom[assetKey{walletID: deposit.WalletID, symbol: deposit.Symbol}] {
+		return
+	}
+	if deposit.Amount.LessThan(s.collectConfig[deposit.Symbol].CollectMin) {
+		return
+	}
+
+	asset := s.asset(deposit.WalletID, deposit.Symbol)
+	asset.NormalBalance = asset.NormalBalance.Sub(deposit.Amount)
+	asset.CollectingBalance = asset.CollectingBalance.Add(deposit.Amount)
+
+	now := utils.Timestamp{Time: time.Now()}
+	s.collects = append(s.collects, &types.AutoCollectRecord{
+		ID:              s.newID(),
+		WalletID:        deposit.WalletID,
+		Symbol:          deposit.Symbol,
+		Amount:          deposit.Amount,
+		FeeSymbol:       deposit.BaseSymbol,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		AddressFrom:     deposit.AddressTo,
+		AddressTo:       s.walletAddress(s.collectWallet, deposit.BaseSymbol).Address,
+		Status:          StatusPending,
+		TransType:       10,
+		BaseSymbol:      deposit.BaseSymbol,
+		ContractAddress: deposit.ContractAddress,
+	})
+}
+
+// notifyDeposit queues the notification of a deposit. s.mu must be held.
+func (s *Server) notifyDeposit(d *types.DepositRecord) {
+	s.notify(types.NotifyData{
+		Side: types.NotifySideDeposit, ID: utils.FlexInt(d.ID), WalletID: utils.FlexInt(d.WalletID),
+		Symbol: d.Symbol, ContractAddress: d.ContractAddress, Amount: d.Amount,
+		AddressFrom: d.AddressFrom, AddressTo: d.AddressTo, Memo: d.Memo, Txid: d.Txid,
+		Confirmations: d.Confirmations, Status: d.Status, TxHeight: d.TxHeight,
+		CreatedAt: d.CreatedAt, UpdatedAt: d.UpdatedAt, BaseSymbol: d.BaseSymbol,
+	})
+}
+
+// notifyWithdraw queues the notification of a withdrawal. s.mu must be held.
+func (s *Server) notifyWithdraw(w *types.WithdrawRecord) {
+	s.notify(types.NotifyData{
+		Side: types.NotifySideWithdraw, RequestID: w.RequestID, ID: utils.FlexInt(w.ID), WalletID: utils.FlexInt(w.WalletID),
+		Symbol: w.Symbol, ContractAddress: w.ContractAddress, Amount: w.Amount,
+		FeeSymbol: w.FeeSymbol, RealFee: w.RealFee,
+		AddressFrom: w.AddressFrom, AddressTo: w.AddressTo, Memo: w.Memo, Txid: w.Txid,
+		Confirmations: w.Confirmations, Status: w.Status, TxHeight: w.TxHeight,
+		CreatedAt: w.CreatedAt, UpdatedAt: w.UpdatedAt, BaseSymbol: w.BaseSymbol,
+		WithdrawSource: w.WithdrawSource,
+	})
+}
+
+// notifyWeb3 queues the notification of a web3 transaction. s.mu must be held.
+func (s *Server) notifyWeb3(t *types.Web3TransRecord) {
+	s.notify(types.NotifyData{
+		Side: types.NotifySideWeb3, RequestID: t.RequestID, ID: utils.FlexInt(t.ID), WalletID: utils.FlexInt(t.WalletID),
+		Symbol: t.Symbol, Amount: t.Amount, FeeSymbol: t.FeeSymbol, RealFee: t.RealFee,
+		AddressFrom: t.From, AddressTo: t.InteractiveContract, Txid: t.Txid,
+		Confirmations: t.Confirmations, Status: t.Status, TxHeight: t.TxHeight,
+		CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt, BaseSymbol: t.MainChainSymbol,
+		MainChainSymbol: t.MainChainSymbol, InteractiveContract: t.InteractiveContract, InputData: t.InputData,
+		TransType: t.TransType.String(), DappName: t.DappName, DappURL: t.DappURL, DappImg: t.DappImg,
+	})
+}
+
+// notifyCollect queues the notification of an auto-collect transaction. s.mu must be held.
+func (s *Server) notifyCollect(c *types.AutoCollectRecord) {
+	s.notify(types.NotifyData{
+		Side: types.NotifySideAutoCollect, ID: utils.FlexInt(c.ID), WalletID: utils.FlexInt(c.WalletID),
+		Symbol: c.Symbol, ContractAddress: c.ContractAddress, Amount: c.Amount,
+		FeeSymbol: c.FeeSymbol, RealFee: c.RealFee, AddressFrom: c.AddressFrom, AddressTo: c.AddressTo,
+		Txid: c.Txid, Confirmations: c.Confirmations, Status: c.Status, TxHeight: s.collectHeights[c.ID],
+		CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt, BaseSymbol: c.BaseSymbol, DelegateFee: c.DelegateFee,
+	})
+}
+
+// notifyDelegate queues the notification of a TRON delegation. s.mu must be held.
+func (s *Server) notifyDelegate(d *types.TronBuyResourceRecord) {
+	hash := d.EnergyTxid
+	if hash == "" {
+		hash = d.NetTxid
+	}
+	now := utils.Timestamp{Time: time.Now()}
+	s.notify(types.NotifyData{
+		Side: types.NotifySideTronDelegate, RequestID: d.RequestID, ID: utils.FlexInt(d.ID),
+		Symbol: "TRX", ContractAddress: d.ContractAddress, AddressFrom: d.AddressFrom, AddressTo: d.AddressTo,
+		Txid: hash, Status: d.Status, CreatedAt: now, UpdatedAt: now, BaseSymbol: "TRX",
+	})
+}
+
+// notify queues a notification for the callback URL. s.mu must be held.
+func (s *Server) notify(data types.NotifyData) {
+	if s.callbackURL == "" {
+		return
+	}
+	data.NotifyTime = utils.Timestamp{Time: time.Now()}
+	s.outbox = append(s.outbox, data)
+}
+
+// flush posts the queued notifications, in order. s.mu must not be held, so
+// the callback can call the server.
+func (s *Server) flush() {
+	s.mu.Lock()
+	outbox, callbackURL := s.outbox, s.callbackURL
+	s.outbox = nil
+	s.mu.Unlock()
+
+	for _, data := range outbox {
+		n := s.post(callbackURL, data)
+		s.mu.Lock()
+		s.notifications = append(s.notifications, n)
+		s.mu.Unlock()
+	}
+}
+
+// post encrypts a notification and posts it to callbackURL.
+func (s *Server) post(callbackURL string, data types.NotifyData) Notification {
+	n := Notification{Data: data}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	cipher, err := s.crypto.EncryptWithPrivateKey(string(raw))
+	if err != nil {
+		n.Err = err
+		return n
+	}
+
+	resp, err := s.notifyClient.PostForm(callbackURL, url.Values{"data": {cipher}})
+	if err != nil {
+		n.Err = err
+		return n
+	}
+	defer resp.Body.Close()
+	var body bytes.Buffer
+	_, n.Err = body.ReadFrom(resp.Body)
+	n.StatusCode, n.Body = resp.StatusCode, body.String()
+	return n
+}
+
+// newID returns the next record ID. s.mu must be held.
+func (s *Server) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// addWallet creates a sub-wallet. s.mu must be held.
+func (s *Server) addWallet(name string, showStatus int64) *wallet {
+	w := &wallet{id: s.newID(), name: name, showStatus: showStatus}
+	s.wallets = append(s.wallets, w)
+	return w
+}
+
+// wallet returns the sub-wallet with id. s.mu must be held.
+func (s *Server) wallet(id int64) (*wallet, error) {
+	for _, w := range s.wallets {
+		if w.id == id {
+			return w, nil
+		}
+	}
+	return nil, &failure{code: CodeWalletNotFound, msg: "sub wallet not found"}
+}
+
+// coin returns the coin with symbol. s.mu must be held.
+func (s *Server) coin(symbol string) (*types.CoinDetails, error) {
+	for _, coin := range s.coins {
+		if coin.Symbol == symbol {
+			return coin, nil
+		}
+	}
+	return nil, &failure{code: CodeSymbolNotSupported, msg: "symbol not supported"}
+}
+
+// asset returns the assets of a sub-wallet in symbol, creating them. s.mu must be held.
+func (s *Server) asset(walletID int64, symbol string) *types.WalletAssets {
+	key := assetKey{walletID: walletID, symbol: symbol}
+	asset, ok := s.assets[key]
+	if !ok {
+		asset = &types.WalletAssets{}
+		s.assets[key] = asset
+	}
+	return asset
+}
+
+// walletAddress returns the first address of a sub-wallet on the chain of
+// baseSymbol, creating it. s.mu must be held.
+func (s *Server) walletAddress(walletID int64, baseSymbol string) *address {
+	for _, a := range s.addresses {
+		if a.walletID == walletID && a.baseSymbol == baseSymbol {
+			return a
+		}
+	}
+	return s.addAddress(walletID, baseSymbol)
+}
+
+// addAddress creates an address of a sub-wallet on the chain of baseSymbol. s.mu must be held.
+func (s *Server) addAddress(walletID int64, baseSymbol string) *address {
+	id := s.newID()
+	a := &address{
+		WalletAddress: types.WalletAddress{ID: id, Address: externalAddress(baseSymbol, id), AddrType: 1},
+		walletID:      walletID,
+		baseSymbol:    baseSymbol,
+	}
+	s.addresses = append(s.addresses, a)
+	return a
+}
+
+// withdraw returns the withdrawal with id. s.mu must be held.
+func (s *Server) withdraw(id int64) (*types.WithdrawRecord, error) {
+	for _, withdraw := range s.withdraws {
+		if withdraw.ID == id {
+			return withdraw, nil
+		}
+	}
+	return nil, fmt.Errorf("mpctest: withdrawal %d not found", id)
+}
+
+// externalAddress returns a well-formed address on the chain of baseSymbol.
+func externalAddress(baseSymbol string, id int64) string {
+	if baseSymbol == "TRX" {
+		// Base58 has no 0, so pad with 1s.
+		return "T" + strings.Repeat("1", 33-len(fmt.Sprint(id))) + strings.ReplaceAll(fmt.Sprint(id), "0", "z")
+	}
+	return fmt.Sprintf("0x%040x", id)
+}
+
+// txid returns a fake transaction hash for a record.
+func txid(id int64) string {
+	return fmt.Sprintf("0x%064x", id)
+}
+
+// syncPage returns up to SyncPageSize records with an ID above maxID, in ID order.
+func syncPage[T any](records []T, id func(T) int64, maxID int64) []T {
+	page := make([]T, 0)
+	for _, record := range records {
+		if id(record) > maxID {
+			page = append(page, record)
+		}
+	}
+	sort.Slice(page, func(i, j int) bool { return id(page[i]) < id(page[j]) })
+	if len(page) > SyncPageSize {
+		page = page[:SyncPageSize]
+	}
+	return page
+}
@@ -0,0 +1,335 @@
+// Package mpctest provides tests for the fake MPC server.
+package mpctest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"chainup.com/go-sdk/mpc"
+	"chainup.com/go-sdk/mpc/api"
+	"chainup.com/go-sdk/mpc/types"
+	"chainup.com/go-sdk/utils/sdkerrors"
+	"github.com/shopspring/decimal"
+)
+
+// callback is a notification endpoint decrypting notifications with the client.
+type callback struct {
+	mu       sync.Mutex
+	client   *mpc.Client
+	received []*types.NotifyData
+}
+
+func (c *callback) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := c.client.GetNotifyAPI().NotifyRequest(r.FormValue("data"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.received = append(c.received, data)
+	c.mu.Unlock()
+	_, _ = w.Write([]byte(NotifyAck))
+}
+
+func (c *callback) last() *types.NotifyData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.received) == 0 {
+		return nil
+	}
+	return c.received[len(c.received)-1]
+}
+
+func TestServerDepositAndWithdraw(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client, err := server.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	hook := &callback{client: client}
+	webhook := httptest.NewServer(hook)
+	defer webhook.Close()
+	server.SetCallbackURL(webhook.URL)
+	ctx := context.Background()
+
+	created, err := client.GetWalletAPI().CreateWalletContext(ctx, "alice", types.AppShowStatusShow)
+	if err != nil {
+		t.Fatalf("CreateWalletContext() error = %v", err)
+	}
+	walletID := created.Data.WalletID
+	address, err := client.GetWalletAPI().CreateWalletAddressContext(ctx, walletID, "ETH")
+	if err != nil {
+		t.Fatalf("CreateWalletAddressContext() error = %v", err)
+	}
+	if info, err := client.GetWalletAPI().WalletAddressInfoContext(ctx, address.Data.Address, ""); err != nil || info.Data.WalletID != walletID {
+		t.Fatalf("WalletAddressInfoContext() = %+v, %v", info, err)
+	}
+
+	deposit, err := server.InjectDeposit(walletID, "ETH", decimal.RequireFromString("2"))
+	if err != nil {
+		t.Fatalf("InjectDeposit() error = %v", err)
+	}
+	if deposit.AddressTo != address.Data.Address {
+		t.Fatalf("deposit address = %s, want %s", deposit.AddressTo, address.Data.Address)
+	}
+	if _, err := server.AdvanceBlocks("ETH", 12); err != nil {
+		t.Fatalf("AdvanceBlocks() error = %v", err)
+	}
+	if got := hook.last(); got == nil || got.Side != types.NotifySideDeposit || got.Status.Int64() != StatusSuccess || got.Confirmations.Int64() != 12 {
+		t.Fatalf("deposit notification = %+v", got)
+	}
+	deposits, err := client.GetDepositAPI().SyncDepositRecordsContext(ctx, 0)
+	if err != nil || len(deposits.Data) != 1 || deposits.Data[0].TxHeight.Int64() != InitialBlockHeight+1 {
+		t.Fatalf("SyncDepositRecordsContext() = %+v, %v", deposits, err)
+	}
+
+	withdrawReq := &types.WithdrawRequest{
+		RequestID: "w-1", WalletID: walletID, Symbol: "ETH",
+		Amount: decimal.RequireFromString("1.5"), AddressTo: "0x00000000000000000000000000000000000000ff",
+	}
+	withdrawn, err := client.GetWithdrawAPI().WithdrawContext(ctx, withdrawReq, true)
+	if err != nil {
+		t.Fatalf("WithdrawContext() error = %v", err)
+	}
+	assets, err := client.GetWalletAPI().GetWalletAssetsContext(ctx, walletID, "ETH")
+	if err != nil || !assets.Data.NormalBalance.Equal(decimal.RequireFromString("0.5")) || !assets.Data.LockBalance.Equal(decimal.RequireFromString("1.5")) {
+		t.Fatalf("GetWalletAssetsContext() = %+v, %v", assets, err)
+	}
+	if err := server.FailWithdraw(withdrawn.Data.WithdrawID); err != nil {
+		t.Fatalf("FailWithdraw() error = %v", err)
+	}
+	if got := server.Assets(walletID, "ETH"); !got.NormalBalance.Equal(decimal.RequireFromString("2")) || !got.LockBalance.IsZero() {
+		t.Fatalf("assets after FailWithdraw = %+v", got)
+	}
+	if got := hook.last(); got.Side != types.NotifySideWithdraw || got.RequestID != "w-1" || got.Status.Int64() != StatusFailed {
+		t.Fatalf("withdraw notification = %+v", got)
+	}
+
+	withdrawReq.RequestID = "w-2"
+	if _, err := client.GetWithdrawAPI().WithdrawContext(ctx, withdrawReq, true); err != nil {
+		t.Fatalf("WithdrawContext() error = %v", err)
+	}
+	if _, err := server.AdvanceBlocks("ETH", 12); err != nil {
+		t.Fatalf("AdvanceBlocks() error = %v", err)
+	}
+	withdraws, err := client.GetWithdrawAPI().GetWithdrawRecordsContext(ctx, []string{"w-2"})
+	if err != nil || len(withdraws.Data) != 1 || withdraws.Data[0].Status.Int64() != StatusSuccess || withdraws.Data[0].Txid == "" {
+		t.Fatalf("GetWithdrawRecordsContext() = %+v, %v", withdraws, err)
+	}
+	for _, n := range server.Notifications() {
+		if !n.Acked() {
+			t.Fatalf("notification not acknowledged: %+v", n)
+		}
+	}
+
+	server.SetRequireSign(true)
+	tests := []struct {
+		name    string
+		req     types.WithdrawRequest
+		sign    bool
+		inject  string
+		wantErr error
+		// wantCode is checked instead of wantErr when set.
+		wantCode string
+	}{
+		{name: "missing sign", req: types.WithdrawRequest{
+			RequestID: "w-3", WalletID: walletID, Symbol: "ETH", Amount: decimal.RequireFromString("0.1"), AddressTo: withdrawReq.AddressTo,
+		}, wantCode: CodeInvalidSign},
+		{name: "duplicate request_id", req: *withdrawReq, sign: true, wantErr: sdkerrors.ErrDuplicateRequestID},
+		{name: "insufficient balance", req: types.WithdrawRequest{
+			RequestID: "w-4", WalletID: walletID, Symbol: "ETH", Amount: decimal.RequireFromString("5"), AddressTo: withdrawReq.AddressTo,
+		}, sign: true, wantErr: sdkerrors.ErrInsufficientBalance},
+		{name: "invalid address", req: types.WithdrawRequest{
+			RequestID: "w-5", WalletID: walletID, Symbol: "ETH", Amount: decimal.RequireFromString("0.1"), AddressTo: "not-an-address",
+		}, sign: true, wantErr: sdkerrors.ErrInvalidAddress},
+		{name: "injected error", req: types.WithdrawRequest{
+			RequestID: "w-6", WalletID: walletID, Symbol: "ETH", Amount: decimal.RequireFromString("0.1"), AddressTo: withdrawReq.AddressTo,
+		}, sign: true, inject: "too many requests", wantErr: sdkerrors.ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.inject != "" {
+				server.InjectError("/api/mpc/billing/withdraw", CodeRateLimited, tt.inject)
+			}
+			_, err := client.GetWithdrawAPI().WithdrawContext(ctx, &tt.req, tt.sign)
+			if tt.wantCode != "" {
+				var apiErr *sdkerrors.APIError
+				if !errors.As(err, &apiErr) || apiErr.RawCode != tt.wantCode {
+					t.Fatalf("WithdrawContext() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithdrawContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	config := server.Config()
+	config.ApiKey = "wrong"
+	other, err := mpc.NewMpcClient(config)
+	if err != nil {
+		t.Fatalf("NewMpcClient() error = %v", err)
+	}
+	if _, err := other.GetWorkSpaceAPI().GetLastBlockHeightContext(ctx, "ETH"); err == nil {
+		t.Fatal("GetLastBlockHeightContext() with a wrong API key succeeded")
+	}
+}
+
+func TestServerWeb3AutoCollectAndTron(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client, err := server.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	ctx := context.Background()
+
+	walletID := server.CreateWallet("bob")
+	server.SetBalance(walletID, "ETH", decimal.RequireFromString("1"))
+	trans, err := client.GetWeb3API().CreateWeb3TransContext(ctx, &types.Web3TransRequest{
+		RequestID: "web3-1", WalletID: walletID, MainChainSymbol: "ETH",
+		InteractiveContract: "0x00000000000000000000000000000000000000aa", Amount: decimal.RequireFromString("0.25"),
+		GasPrice: decimal.RequireFromString("20"), GasLimit: 60000, InputData: "0x", TransType: "0",
+	}, true)
+	if err != nil {
+		t.Fatalf("CreateWeb3TransContext() error = %v", err)
+	}
+	if ok, err := client.GetWeb3API().AccelerationWeb3TransContext(ctx, &types.Web3AccelerationArgs{
+		TransID: int(trans.Data.TransID), GasPrice: "30", GasLimit: "60000",
+	}); err != nil || !ok {
+		t.Fatalf("AccelerationWeb3TransContext() = %v, %v", ok, err)
+	}
+	if _, err := server.AdvanceBlocks("ETH", 12); err != nil {
+		t.Fatalf("AdvanceBlocks() error = %v", err)
+	}
+	records, err := client.GetWeb3API().GetWeb3RecordsContext(ctx, []string{"web3-1"})
+	if err != nil || len(records.Data) != 1 || records.Data[0].Status.Int64() != StatusSuccess || !records.Data[0].GasPrice.Equal(decimal.NewFromInt(30)) {
+		t.Fatalf("GetWeb3RecordsContext() = %+v, %v", records, err)
+	}
+
+	collect, err := client.GetAutoSweepAPI().AutoCollectSubWalletsContext(ctx, []int64{walletID}, "USDT-ERC20")
+	if err != nil {
+		t.Fatalf("AutoCollectSubWalletsContext() error = %v", err)
+	}
+	if _, err := client.GetAutoSweepAPI().SetAutoCollectSymbolContext(ctx, &types.SetAutoCollectSymbolArgs{
+		Symbol: "USDT-ERC20", CollectMin: decimal.NewFromInt(10), FuelingLimit: decimal.NewFromInt(1),
+	}); err != nil {
+		t.Fatalf("SetAutoCollectSymbolContext() error = %v", err)
+	}
+	if _, err := server.InjectDeposit(walletID, "USDT-ERC20", decimal.NewFromInt(100)); err != nil {
+		t.Fatalf("InjectDeposit() error = %v", err)
+	}
+	if _, err := server.AdvanceBlocks("ETH", 12); err != nil {
+		t.Fatalf("AdvanceBlocks() error = %v", err)
+	}
+	if got := server.Assets(walletID, "USDT-ERC20"); !got.CollectingBalance.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("assets while collecting = %+v", got)
+	}
+	if _, err := server.AdvanceBlocks("ETH", 12); err != nil {
+		t.Fatalf("AdvanceBlocks() error = %v", err)
+	}
+	collects, err := client.GetAutoSweepAPI().SyncAutoCollectRecordsContext(ctx, 0)
+	if err != nil || len(collects.Data) != 1 || collects.Data[0].Status.Int64() != StatusSuccess {
+		t.Fatalf("SyncAutoCollectRecordsContext() = %+v, %v", collects, err)
+	}
+	if got := server.Assets(collect.Data.CollectWalletId, "USDT-ERC20"); !got.NormalBalance.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("collection wallet assets = %+v", got)
+	}
+
+	from := server.walletAddress(walletID, "TRX").Address
+	if _, err := client.GetTronResourceAPI().CreateTronDelegateContext(ctx, &types.TronBuyResourceArgs{
+		RequestID: "tron-1", BuyType: 1, ResourceType: 0, EnergyNum: 32000, ServiceChargeType: "10010", AddressFrom: from,
+	}); err != nil {
+		t.Fatalf("CreateTronDelegateContext() error = %v", err)
+	}
+	height, err := server.AdvanceBlocks("TRX", 1)
+	if err != nil {
+		t.Fatalf("AdvanceBlocks() error = %v", err)
+	}
+	delegates, err := client.GetTronResourceAPI().GetBuyResourceRecordsContext(ctx, []string{"tron-1"})
+	if err != nil || len(delegates.Data) != 1 || delegates.Data[0].Status.Int64() != StatusSuccess || delegates.Data[0].EnergyTxid == "" {
+		t.Fatalf("GetBuyResourceRecordsContext() = %+v, %v", delegates, err)
+	}
+	got, err := client.GetWorkSpaceAPI().GetLastBlockHeightContext(ctx, "TRX")
+	if err != nil || got.Data.BlockHeight != height {
+		t.Fatalf("GetLastBlockHeightContext() = %+v, %v, want %d", got, err, height)
+	}
+}
+
+func TestServerWebhookHandler(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client, err := server.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		fail     = true
+		deposits []*types.DepositNotification
+	)
+	handler := api.NewWebhookHandler(client.GetNotifyAPI()).
+		OnDeposit(func(ctx context.Context, n *types.DepositNotification) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if fail {
+				return errors.New("database unavailable")
+			}
+			deposits = append(deposits, n)
+			return nil
+		})
+	webhook := httptest.NewServer(handler)
+	defer webhook.Close()
+	server.SetCallbackURL(webhook.URL)
+
+	walletID := server.CreateWallet("alice")
+	if _, err := server.InjectDeposit(walletID, "ETH", decimal.RequireFromString("2")); err != nil {
+		t.Fatalf("InjectDeposit() error = %v", err)
+	}
+	if _, err := server.AdvanceBlocks("ETH", 12); err != nil {
+		t.Fatalf("AdvanceBlocks() error = %v", err)
+	}
+	notifications := server.Notifications()
+	if len(notifications) == 0 || notifications[0].Acked() || notifications[0].StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("notifications with failing callback = %+v", notifications)
+	}
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	if n := server.RetryNotifications(); n != len(notifications) {
+		t.Fatalf("RetryNotifications() = %d, want %d", n, len(notifications))
+	}
+	mu.Lock()
+	received := append([]*types.DepositNotification(nil), deposits...)
+	mu.Unlock()
+	if len(received) == 0 {
+		t.Fatalf("no deposit notification was handled after the retry")
+	}
+	last := received[len(received)-1]
+	if last.WalletID.Int64() != walletID || last.Status.Int64() != StatusSuccess || !last.Amount.Equal(decimal.RequireFromString("2")) {
+		t.Fatalf("deposit notification = %+v", last)
+	}
+	for _, n := range server.Notifications()[len(notifications):] {
+		if !n.Acked() {
+			t.Fatalf("retried notification not acknowledged: %+v", n)
+		}
+	}
+
+	resp, err := http.PostForm(webhook.URL, url.Values{"data": {"forged"}})
+	if err != nil {
+		t.Fatalf("PostForm() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("forged notification status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -120,6 +120,9 @@ type WithdrawRecordResult struct {
 	Code string            `json:"code"`
 	Msg  string            `json:"msg"`
 	Data []*WithdrawRecord `json:"data"`
+
+	// NotFound lists the requested request IDs no record was returned for.
+	NotFound []string `json:"-"`
 }
 
 // -----------------------------------------------------------------------------
@@ -186,6 +189,9 @@ type Web3RecordResult struct {
 	Code string             `json:"code"`
 	Msg  string             `json:"msg"`
 	Data []*Web3TransRecord `json:"data"`
+
+	// NotFound lists the requested request IDs no record was returned for.
+	NotFound []string `json:"-"`
 }
 
 // Web3AccelerationArgs represents Web3 transaction acceleration arguments.
@@ -298,6 +304,9 @@ type DepositRecordResult struct {
 	Code string           `json:"code"`
 	Msg  string           `json:"msg"`
 	Data []*DepositRecord `json:"data"`
+
+	// NotFound lists the requested IDs no record was returned for.
+	NotFound []int64 `json:"-"`
 }
 
 // -----------------------------------------------------------------------------
@@ -435,7 +444,7 @@ type AutoCollectRecordResult struct {
 // https://custodydocs-zh.chainup.com/api-references/mpc-apis/notify
 type NotifyData struct {
 	// Common fields
-	Side       string    `json:"side"`          // deposit or withdraw
+	Side       string    `json:"side"`          // One of the NotifySide values
 	NotifyTime Timestamp `json:"notify_time"`   // Notification timestamp
 	RequestID  string    `json:"request_id"`    // Request ID (withdraw/web3 only)
 	ID         FlexInt   `json:"id"`            // Record ID
@@ -480,6 +489,139 @@ type NotifyData struct {
 	DappURL             string `json:"dapp_url,omitempty"`             // DApp URL
 }
 
+// NotifyData.Side values.
+const (
+	NotifySideDeposit      = "deposit"
+	NotifySideWithdraw     = "withdraw"
+	NotifySideWeb3         = "web3"
+	NotifySideAutoCollect  = "auto_collect"
+	NotifySideTronDelegate = "tron_delegate"
+)
+
+// ChainNotification holds the fields shared by the notifications of on-chain
+// transactions: deposits, withdrawals, Web3 transactions and auto-collects.
+type ChainNotification struct {
+	NotifyTime      Timestamp       `json:"notify_time"`
+	ID              FlexInt         `json:"id"`
+	WalletID        FlexInt         `json:"sub_wallet_id"`
+	Symbol          string          `json:"symbol"`
+	BaseSymbol      string          `json:"base_symbol,omitempty"`
+	ContractAddress string          `json:"contract_address,omitempty"`
+	Amount          decimal.Decimal `json:"amount"`
+	AddressFrom     string          `json:"address_from,omitempty"`
+	AddressTo       string          `json:"address_to,omitempty"`
+	Memo            string          `json:"memo,omitempty"`
+	Txid            string          `json:"txid,omitempty"`
+	Confirmations   FlexInt         `json:"confirmations"`
+	TxHeight        FlexInt         `json:"tx_height,omitempty"`
+	Status          FlexInt         `json:"status"`
+	CreatedAt       Timestamp       `json:"created_at"`
+	UpdatedAt       Timestamp       `json:"updated_at"`
+}
+
+// DepositNotification is a notification of side NotifySideDeposit.
+type DepositNotification struct {
+	ChainNotification
+}
+
+// WithdrawNotification is a notification of side NotifySideWithdraw.
+type WithdrawNotification struct {
+	ChainNotification
+	RequestID      string          `json:"request_id"`
+	FeeSymbol      string          `json:"fee_symbol,omitempty"`
+	RealFee        decimal.Decimal `json:"real_fee,omitempty"`
+	WithdrawSource int             `json:"withdraw_source,omitempty"` // 1: app, 2: openapi, 3: web
+	DelegateFee    decimal.Decimal `json:"delegate_fee,omitempty"`
+}
+
+// Web3Notification is a notification of side NotifySideWeb3.
+type Web3Notification struct {
+	ChainNotification
+	RequestID           string          `json:"request_id"`
+	FeeSymbol           string          `json:"fee_symbol,omitempty"`
+	RealFee             decimal.Decimal `json:"real_fee,omitempty"`
+	MainChainSymbol     string          `json:"main_chain_symbol,omitempty"`
+	InteractiveContract string          `json:"interactive_contract,omitempty"`
+	InputData           string          `json:"input_data,omitempty"`
+	TransType           string          `json:"trans_type,omitempty"`
+	DappName            string          `json:"dapp_name,omitempty"`
+	DappURL             string          `json:"dapp_url,omitempty"`
+	DappImg             string          `json:"dapp_img,omitempty"`
+}
+
+// AutoCollectNotification is a notification of side NotifySideAutoCollect.
+type AutoCollectNotification struct {
+	ChainNotification
+	FeeSymbol   string          `json:"fee_symbol,omitempty"`
+	RealFee     decimal.Decimal `json:"real_fee,omitempty"`
+	DelegateFee decimal.Decimal `json:"delegate_fee,omitempty"`
+	TransType   string          `json:"trans_type,omitempty"` // 10: Consolidation, 11: Consolidation Gas
+}
+
+// TronDelegateNotification is a notification of side NotifySideTronDelegate.
+type TronDelegateNotification struct {
+	NotifyTime      Timestamp `json:"notify_time"`
+	ID              FlexInt   `json:"id"`
+	RequestID       string    `json:"request_id"`
+	ContractAddress string    `json:"contract_address,omitempty"`
+	AddressFrom     string    `json:"address_from,omitempty"`
+	AddressTo       string    `json:"address_to,omitempty"`
+	Txid            string    `json:"txid,omitempty"`
+	Status          FlexInt   `json:"status"`
+	CreatedAt       Timestamp `json:"created_at"`
+	UpdatedAt       Timestamp `json:"updated_at"`
+}
+
+// Chain returns the on-chain transaction fields of the notification.
+func (n *NotifyData) Chain() ChainNotification {
+	return ChainNotification{
+		NotifyTime: n.NotifyTime, ID: n.ID, WalletID: n.WalletID,
+		Symbol: n.Symbol, BaseSymbol: n.BaseSymbol, ContractAddress: n.ContractAddress, Amount: n.Amount,
+		AddressFrom: n.AddressFrom, AddressTo: n.AddressTo, Memo: n.Memo, Txid: n.Txid,
+		Confirmations: n.Confirmations, TxHeight: n.TxHeight, Status: n.Status,
+		CreatedAt: n.CreatedAt, UpdatedAt: n.UpdatedAt,
+	}
+}
+
+// Deposit returns the notification as a deposit notification.
+func (n *NotifyData) Deposit() *DepositNotification {
+	return &DepositNotification{ChainNotification: n.Chain()}
+}
+
+// Withdraw returns the notification as a withdrawal notification.
+func (n *NotifyData) Withdraw() *WithdrawNotification {
+	return &WithdrawNotification{
+		ChainNotification: n.Chain(), RequestID: n.RequestID,
+		FeeSymbol: n.FeeSymbol, RealFee: n.RealFee, WithdrawSource: n.WithdrawSource, DelegateFee: n.DelegateFee,
+	}
+}
+
+// Web3 returns the notification as a Web3 transaction notification.
+func (n *NotifyData) Web3() *Web3Notification {
+	return &Web3Notification{
+		ChainNotification: n.Chain(), RequestID: n.RequestID, FeeSymbol: n.FeeSymbol, RealFee: n.RealFee,
+		MainChainSymbol: n.MainChainSymbol, InteractiveContract: n.InteractiveContract, InputData: n.InputData,
+		TransType: n.TransType, DappName: n.DappName, DappURL: n.DappURL, DappImg: n.DappImg,
+	}
+}
+
+// AutoCollect returns the notification as an auto-collect notification.
+func (n *NotifyData) AutoCollect() *AutoCollectNotification {
+	return &AutoCollectNotification{
+		ChainNotification: n.Chain(), FeeSymbol: n.FeeSymbol, RealFee: n.RealFee,
+		DelegateFee: n.DelegateFee, TransType: n.TransType,
+	}
+}
+
+// TronDelegate returns the notification as a TRON delegation notification.
+func (n *NotifyData) TronDelegate() *TronDelegateNotification {
+	return &TronDelegateNotification{
+		NotifyTime: n.NotifyTime, ID: n.ID, RequestID: n.RequestID, ContractAddress: n.ContractAddress,
+		AddressFrom: n.AddressFrom, AddressTo: n.AddressTo, Txid: n.Txid, Status: n.Status,
+		CreatedAt: n.CreatedAt, UpdatedAt: n.UpdatedAt,
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Tron Resource Types
 // -----------------------------------------------------------------------------
@@ -540,6 +682,9 @@ type TronBuyResourceRecordResult struct {
 	Code string                   `json:"code"`
 	Msg  string                   `json:"msg"`
 	Data []*TronBuyResourceRecord `json:"data"`
+
+	// NotFound lists the requested request IDs no record was returned for.
+	NotFound []string `json:"-"`
 }
 
 // TronResourceOrder represents Tron resource order (legacy).
@@ -0,0 +1,40 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+// AuthenticityCollector is implemented by collectors that also count success
+// responses which were not decrypted with the ChainUp public key. Metrics
+// implements it.
+type AuthenticityCollector interface {
+	// UnverifiedResponse is called for every unverified success response.
+	// rejected reports whether strict mode turned it into an error.
+	UnverifiedResponse(service, endpoint, reason string, rejected bool)
+}
+
+// CheckAuthenticity checks a response body that OpenEnvelope did not decrypt;
+// decryptErr is the decryption error, if any. Error responses and invalid
+// JSON fail the call anyway and pass. A success response is reported to
+// collector and, in strict mode, rejected with an *sdkerrors.AuthenticityError.
+func CheckAuthenticity(ctx context.Context, collector Collector, service, path string, body []byte, decryptErr error, strict bool) error {
+	var status ResponseStatus
+	if err := DecodeJSON(body, &status); err != nil || sdkerrors.FromCode(status.Code, status.Msg) != nil {
+		return nil
+	}
+
+	reason := sdkerrors.ReasonNotEncrypted
+	if decryptErr != nil {
+		reason = sdkerrors.ReasonDecryptFailed
+	}
+	if c, ok := collector.(AuthenticityCollector); ok {
+		c.UnverifiedResponse(service, callEndpoint(ctx, path), reason, strict)
+	}
+	if !strict {
+		return nil
+	}
+	return &sdkerrors.AuthenticityError{Endpoint: path, Reason: reason, Err: decryptErr}
+}
@@ -0,0 +1,57 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+func TestCheckAuthenticity(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		decryptErr error
+		strict     bool
+		wantReason string
+		wantMetric string
+	}{
+		{name: "error response", body: `{"code":"100004","msg":"request_id already exists"}`, strict: true},
+		{name: "invalid JSON", body: `<html>`, strict: true},
+		{name: "strict plaintext", body: `{"code":0,"data":{}}`, strict: true, wantReason: sdkerrors.ReasonNotEncrypted,
+			wantMetric: `reason="not_encrypted",action="rejected"} 1`},
+		{name: "strict decrypt failure", body: `{"data":"x"}`, decryptErr: errors.New("bad cipher"), strict: true,
+			wantReason: sdkerrors.ReasonDecryptFailed, wantMetric: `reason="decrypt_failed",action="rejected"} 1`},
+		{name: "lax plaintext", body: `{"code":"0","data":{}}`, wantMetric: `reason="not_encrypted",action="accepted"} 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := NewMetrics()
+			err := CheckAuthenticity(context.Background(), metrics, "mpc", "/api/mpc/wallet/create", []byte(tt.body), tt.decryptErr, tt.strict)
+
+			var authErr *sdkerrors.AuthenticityError
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("CheckAuthenticity() error = %v, want nil", err)
+				}
+			} else if !errors.As(err, &authErr) || authErr.Reason != tt.wantReason || !errors.Is(err, sdkerrors.ErrUnauthenticResponse) {
+				t.Fatalf("CheckAuthenticity() error = %v, want reason %s", err, tt.wantReason)
+			}
+			if tt.decryptErr != nil && !errors.Is(err, tt.decryptErr) {
+				t.Fatalf("CheckAuthenticity() error = %v does not wrap %v", err, tt.decryptErr)
+			}
+
+			var out strings.Builder
+			if err := metrics.Write(&out); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			got := strings.Contains(out.String(), "chainup_sdk_unverified_responses_total{")
+			if got != (tt.wantMetric != "") || !strings.Contains(out.String(), tt.wantMetric) {
+				t.Fatalf("metrics = %s, want %q", out.String(), tt.wantMetric)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Defaults of BatchSettings.
+const (
+	// DefaultBatchSize is the number of IDs sent per lookup request, the most
+	// the ID-list endpoints accept.
+	DefaultBatchSize = 100
+
+	// DefaultBatchConcurrency is the number of lookup requests run at once.
+	DefaultBatchConcurrency = 4
+)
+
+// BatchSettings configures how lookups by a list of IDs, such as
+// BillingAPI.WithdrawList or DepositAPI.GetDepositRecords, are split into
+// requests.
+type BatchSettings struct {
+	// Size is the maximum number of IDs per request (default: DefaultBatchSize).
+	Size int
+
+	// Concurrency is the number of requests run at once
+	// (default: DefaultBatchConcurrency).
+	Concurrency int
+}
+
+func (s BatchSettings) size() int {
+	if s.Size < 1 {
+		return DefaultBatchSize
+	}
+	return s.Size
+}
+
+func (s BatchSettings) concurrency() int {
+	if s.Concurrency < 1 {
+		return DefaultBatchConcurrency
+	}
+	return s.Concurrency
+}
+
+// LookupBatches looks up the records of keys with fetch, at most
+// settings.Size keys per call and settings.Concurrency calls at once.
+// Duplicate keys are sent once. The records of all batches are merged in
+// batch order, keeping the first record of each key, and the requested keys
+// no record was returned for are reported as missing.
+//
+// The first failed batch cancels the others, and its error is returned with
+// no records.
+func LookupBatches[K comparable, T any](ctx context.Context, settings BatchSettings, keys []K, fetch func(ctx context.Context, batch []K) ([]T, error), key func(T) K) (records []T, missing []K, err error) {
+	unique := make([]K, 0, len(keys))
+	requested := make(map[K]bool, len(keys))
+	for _, k := range keys {
+		if !requested[k] {
+			requested[k] = true
+			unique = append(unique, k)
+		}
+	}
+
+	var batches [][]K
+	for size := settings.size(); len(unique) > 0; unique = unique[min(size, len(unique)):] {
+		batches = append(batches, unique[:min(size, len(unique))])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([][]T, len(batches))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() { firstErr = err; cancel() })
+	}
+	sem := make(chan struct{}, settings.concurrency())
+	for i, batch := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			// A failed batch already set its own error.
+			fail(err)
+			break
+		}
+		wg.Add(1)
+		go func(i int, batch []K) {
+			defer func() { <-sem; wg.Done() }()
+			page, err := fetch(ctx, batch)
+			if err != nil {
+				fail(err)
+				return
+			}
+			results[i] = page
+		}(i, batch)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+
+	found := make(map[K]bool, len(requested))
+	for _, page := range results {
+		for _, record := range page {
+			k := key(record)
+			if found[k] {
+				continue
+			}
+			found[k] = true
+			records = append(records, record)
+		}
+	}
+	for _, batch := range batches {
+		for _, k := range batch {
+			if !found[k] {
+				missing = append(missing, k)
+			}
+		}
+	}
+	return records, missing, nil
+}
+
+// LookupByIDs calls the ID-list endpoint ep through c in batches of IDs, see
+// LookupBatches. The records selected by data are merged into one result,
+// and the IDs no record was returned for are returned as notFound. The
+// callers reject an empty ids list.
+func LookupByIDs[R, T any, K comparable](ctx context.Context, c *Caller, ep *Endpoint, ids []K, data func(*R) *[]T, key func(T) K) (result *R, notFound []K, err error) {
+	var mu sync.Mutex
+	fetch := func(ctx context.Context, batch []K) ([]T, error) {
+		page, err := CallEndpoint[R](ctx, c, ep, map[string]interface{}{"ids": joinIDs(batch)})
+		if err != nil || page == nil {
+			return nil, err
+		}
+		mu.Lock()
+		if result == nil {
+			result = page
+		}
+		mu.Unlock()
+		return *data(page), nil
+	}
+	records, notFound, err := LookupBatches(ctx, c.Batch, ids, fetch, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result != nil {
+		*data(result) = records
+	}
+	return result, notFound, nil
+}
+
+// joinIDs joins ids into the comma-separated "ids" parameter.
+func joinIDs[K comparable](ids []K) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = fmt.Sprint(id)
+	}
+	return strings.Join(strs, ",")
+}
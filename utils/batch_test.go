@@ -0,0 +1,86 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLookupBatches(t *testing.T) {
+	known := map[string]bool{"a": true, "b": true, "c": true, "d": true, "e": true}
+	tests := []struct {
+		name        string
+		keys        []string
+		settings    BatchSettings
+		failBatch   string
+		wantRecords []string
+		wantMissing []string
+		wantBatches [][]string
+		wantErr     bool
+	}{
+		{
+			name:        "single batch",
+			keys:        []string{"a", "b"},
+			wantRecords: []string{"a", "b"},
+			wantBatches: [][]string{{"a", "b"}},
+		},
+		{
+			name:        "split, deduplicated and missing",
+			keys:        []string{"a", "b", "a", "x", "c", "d", "e", "y"},
+			settings:    BatchSettings{Size: 3, Concurrency: 2},
+			wantRecords: []string{"a", "b", "c", "d", "e"},
+			wantMissing: []string{"x", "y"},
+			wantBatches: [][]string{{"a", "b", "x"}, {"c", "d", "e"}, {"y"}},
+		},
+		{
+			name:        "failed batch",
+			keys:        []string{"a", "b", "c"},
+			settings:    BatchSettings{Size: 1, Concurrency: 1},
+			failBatch:   "b",
+			wantErr:     true,
+			wantBatches: [][]string{{"a"}, {"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				batches = map[string][]string{}
+			)
+			fetch := func(ctx context.Context, batch []string) ([]string, error) {
+				mu.Lock()
+				batches[batch[0]] = batch
+				mu.Unlock()
+				if batch[0] == tt.failBatch {
+					return nil, errors.New("batch failed")
+				}
+				var records []string
+				for _, k := range batch {
+					if known[k] {
+						// The server may repeat records; they are merged.
+						records = append(records, k, k)
+					}
+				}
+				return records, nil
+			}
+			records, missing, err := LookupBatches(context.Background(), tt.settings, tt.keys, fetch, func(r string) string { return r })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupBatches() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(records, tt.wantRecords) || !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Fatalf("LookupBatches() = %v, %v, want %v, %v", records, missing, tt.wantRecords, tt.wantMissing)
+			}
+			if len(batches) != len(tt.wantBatches) {
+				t.Fatalf("batches = %v, want %v", batches, tt.wantBatches)
+			}
+			for _, want := range tt.wantBatches {
+				if got := batches[want[0]]; !reflect.DeepEqual(got, want) {
+					t.Fatalf("batch = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
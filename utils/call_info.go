@@ -0,0 +1,56 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import "context"
+
+// callInfoKey is the context key for CallInfo.
+type callInfoKey struct{}
+
+// CallInfo describes the logical API call an HTTP request belongs to.
+// The API layer attaches it to the request context so that the HTTP layer
+// can decide whether the request may be sent more than once and which
+// limits apply to it.
+type CallInfo struct {
+	// Idempotent reports whether sending the request twice is safe.
+	Idempotent bool
+
+	// Endpoint is the endpoint name, e.g. "WithdrawAPI.Withdraw" (may be empty).
+	Endpoint string
+
+	// Group is the endpoint group of the call (may be empty).
+	Group EndpointGroup
+}
+
+// WithCallInfo returns a copy of ctx carrying info.
+func WithCallInfo(ctx context.Context, info CallInfo) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, info)
+}
+
+// CallInfoFromContext returns the CallInfo attached to ctx, if any.
+func CallInfoFromContext(ctx context.Context) (CallInfo, bool) {
+	info, ok := ctx.Value(callInfoKey{}).(CallInfo)
+	return info, ok
+}
+
+// WithIdempotencyKey marks a money-moving call as idempotent only when a
+// request ID is present, since the server de-duplicates calls by request ID.
+func WithIdempotencyKey(ctx context.Context, requestID string) context.Context {
+	info, _ := CallInfoFromContext(ctx)
+	info.Idempotent = requestID != ""
+	return WithCallInfo(ctx, info)
+}
+
+// WithNonIdempotent marks a call that must never be sent more than once.
+func WithNonIdempotent(ctx context.Context) context.Context {
+	info, _ := CallInfoFromContext(ctx)
+	info.Idempotent = false
+	return WithCallInfo(ctx, info)
+}
+
+// isIdempotent reports whether the request bound to ctx may be retried.
+// Requests without CallInfo are treated as idempotent; the API layer attaches
+// CallInfo to every POST, including raw ones.
+func isIdempotent(ctx context.Context) bool {
+	info, ok := CallInfoFromContext(ctx)
+	return !ok || info.Idempotent
+}
@@ -0,0 +1,141 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// callOptionsKey is the context key for CallOptions.
+type callOptionsKey struct{}
+
+// CallOptions tunes a single API call. Every *Context method of the WaaS and
+// MPC APIs honors the options attached to its context with WithCallOptions.
+type CallOptions struct {
+	// Timeout bounds each HTTP attempt of the call, replacing the client
+	// timeout. Zero keeps the client timeout.
+	Timeout time.Duration
+
+	// Headers are added to the HTTP request. Headers set by the SDK itself,
+	// such as Content-Type and API-KEY, cannot be replaced.
+	Headers map[string]string
+
+	// Debug overrides the debug setting of the client when non-nil.
+	Debug *bool
+
+	// NoWait makes the call fail fast instead of waiting for the rate limiter.
+	NoWait bool
+}
+
+// CallOption configures CallOptions.
+type CallOption func(*CallOptions)
+
+// WithCallTimeout sets the timeout of each HTTP attempt of the call.
+// Long sync pulls can use a generous deadline and interactive lookups a short one.
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(o *CallOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithCallHeader adds a header to the HTTP request of the call.
+func WithCallHeader(key, value string) CallOption {
+	return func(o *CallOptions) {
+		if o.Headers == nil {
+			o.Headers = make(map[string]string)
+		}
+		o.Headers[key] = value
+	}
+}
+
+// WithCallHeaders adds headers to the HTTP request of the call.
+func WithCallHeaders(headers map[string]string) CallOption {
+	return func(o *CallOptions) {
+		for key, value := range headers {
+			WithCallHeader(key, value)(o)
+		}
+	}
+}
+
+// WithCallDebug enables or disables debug logging for the call, regardless of
+// the client's debug setting. When the client has no logger, debug output goes to stdout.
+func WithCallDebug(debug bool) CallOption {
+	return func(o *CallOptions) {
+		o.Debug = &debug
+	}
+}
+
+// WithCallNoWait makes the call fail with a *sdkerrors.RateLimitError instead
+// of waiting when the rate limiter has no token available.
+func WithCallNoWait() CallOption {
+	return func(o *CallOptions) {
+		o.NoWait = true
+	}
+}
+
+// WithCallOptions returns a copy of ctx carrying opts, applied on top of any
+// options already attached to ctx:
+//
+//	ctx = utils.WithCallOptions(ctx, utils.WithCallTimeout(5*time.Second))
+//	account, err := client.GetAccountAPI().GetUserAccountContext(ctx, uid, "ETH")
+func WithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	options := CallOptionsFromContext(ctx)
+	if len(options.Headers) > 0 {
+		headers := make(map[string]string, len(options.Headers))
+		for key, value := range options.Headers {
+			headers[key] = value
+		}
+		options.Headers = headers
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, options)
+}
+
+// CallOptionsFromContext returns the CallOptions attached to ctx, or the zero value.
+func CallOptionsFromContext(ctx context.Context) CallOptions {
+	options, _ := ctx.Value(callOptionsKey{}).(CallOptions)
+	return options
+}
+
+// CallLogger returns logger adjusted to the debug override of the call bound
+// to ctx, or logger itself when the call does not override debug.
+func CallLogger(ctx context.Context, logger *slog.Logger) *slog.Logger {
+	debug := CallOptionsFromContext(ctx).Debug
+	if debug == nil {
+		return logger
+	}
+	if !*debug {
+		return slog.New(levelHandler{Handler: logger.Handler(), min: slog.LevelInfo})
+	}
+	if _, ok := logger.Handler().(discardHandler); ok {
+		return ResolveLogger(nil, true)
+	}
+	return slog.New(levelHandler{Handler: logger.Handler(), min: slog.LevelDebug, force: true})
+}
+
+// levelHandler changes the minimum level of the handler it wraps. When force
+// is set, records at or above min are handled even if the wrapped handler
+// would drop them.
+type levelHandler struct {
+	slog.Handler
+	min   slog.Level
+	force bool
+}
+
+func (h levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level < h.min {
+		return false
+	}
+	return h.force || h.Handler.Enabled(ctx, level)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{Handler: h.Handler.WithAttrs(attrs), min: h.min, force: h.force}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{Handler: h.Handler.WithGroup(name), min: h.min, force: h.force}
+}
@@ -0,0 +1,107 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1200 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"code":"0"}`))
+	}))
+	defer server.Close()
+
+	client := NewMpcHTTPClient(server.URL, "app", "key", 1, false)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr bool
+	}{
+		{name: "client timeout", ctx: context.Background(), wantErr: true},
+		{name: "shorter call timeout", ctx: WithCallOptions(context.Background(), WithCallTimeout(50*time.Millisecond)), wantErr: true},
+		{name: "longer call timeout", ctx: WithCallOptions(context.Background(), WithCallTimeout(3*time.Second))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.PostContext(tt.ctx, "/test", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PostContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("PostContext() error = %v, want context.DeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestCallHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"code":"0"}`))
+	}))
+	defer server.Close()
+
+	client := NewMpcHTTPClient(server.URL, "app", "key", DefaultTimeout, false)
+	ctx := WithCallOptions(context.Background(), WithCallHeader("X-Trace-Id", "t-1"))
+	ctx = WithCallOptions(ctx, WithCallHeaders(map[string]string{"API-KEY": "other", "X-Tenant": "a"}))
+
+	if _, err := client.PostContext(ctx, "/test", nil); err != nil {
+		t.Fatalf("PostContext() error = %v", err)
+	}
+	if got.Get("X-Trace-Id") != "t-1" || got.Get("X-Tenant") != "a" {
+		t.Fatalf("extra headers missing: %v", got)
+	}
+	if got.Get("API-KEY") != "key" {
+		t.Fatalf("API-KEY = %q, call headers must not replace it", got.Get("API-KEY"))
+	}
+}
+
+func TestCallLogger(t *testing.T) {
+	var buf bytes.Buffer
+	infoLogger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	tests := []struct {
+		name      string
+		logger    *slog.Logger
+		opts      []CallOption
+		wantDebug bool
+		wantInfo  bool
+	}{
+		{name: "no override", logger: infoLogger, wantInfo: true},
+		{name: "debug on", logger: infoLogger, opts: []CallOption{WithCallDebug(true)}, wantDebug: true, wantInfo: true},
+		{name: "debug off", logger: ResolveLogger(nil, true), opts: []CallOption{WithCallDebug(false)}, wantInfo: true},
+		{name: "debug on without logger", logger: ResolveLogger(nil, false), opts: []CallOption{WithCallDebug(true)}, wantDebug: true, wantInfo: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithCallOptions(context.Background(), tt.opts...)
+			logger := CallLogger(ctx, tt.logger)
+			if got := logger.Enabled(ctx, slog.LevelDebug); got != tt.wantDebug {
+				t.Fatalf("debug enabled = %v, want %v", got, tt.wantDebug)
+			}
+			if got := logger.Enabled(ctx, slog.LevelInfo); got != tt.wantInfo {
+				t.Fatalf("info enabled = %v, want %v", got, tt.wantInfo)
+			}
+		})
+	}
+
+	buf.Reset()
+	ctx := WithCallOptions(context.Background(), WithCallDebug(true))
+	CallLogger(ctx, infoLogger).With("k", "v").DebugContext(ctx, "forced")
+	if !strings.Contains(buf.String(), "forced") || !strings.Contains(buf.String(), "k=v") {
+		t.Fatalf("forced debug record not written: %q", buf.String())
+	}
+}
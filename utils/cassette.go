@@ -0,0 +1,266 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrCassetteMiss is returned in replay mode when a cassette holds no
+// interaction matching a call.
+var ErrCassetteMiss = errors.New("no recorded interaction matches the call")
+
+// CassetteIgnoredFields are the common request args added by the SDK to every
+// call. They change from call to call, so they are neither recorded nor matched.
+var CassetteIgnoredFields = []string{"time", "charset"}
+
+// CassetteMode selects whether a Cassette records or replays calls.
+type CassetteMode int
+
+const (
+	// CassetteRecord sends calls to the server and records them.
+	CassetteRecord CassetteMode = iota
+	// CassetteReplay serves calls from the recorded interactions without any network.
+	CassetteReplay
+)
+
+// Interaction is one recorded API call: the plaintext request args, before
+// encryption, and the decrypted response body.
+type Interaction struct {
+	Service  string                 `json:"service"`
+	Method   string                 `json:"method"`
+	Path     string                 `json:"path"`
+	Request  map[string]interface{} `json:"request"`
+	Response json.RawMessage        `json:"response"`
+}
+
+// Cassette records API calls of the WaaS and MPC clients to a JSON file and
+// replays them in tests. Secret fields (SecretFields and any field added with
+// ScrubFields) are replaced in requests and responses before they are stored.
+//
+// In replay mode, calls are matched on service, method, path and request args.
+// Identical calls are served in the order they were recorded; once they are
+// used up, the last one is served again. A call with no match fails with
+// ErrCassetteMiss. A Cassette is safe for concurrent use.
+type Cassette struct {
+	mu           sync.Mutex
+	path         string
+	mode         CassetteMode
+	scrub        map[string]struct{}
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassetteRecorder creates a Cassette recording calls; Save writes them to path.
+func NewCassetteRecorder(path string) *Cassette {
+	return newCassette(path, CassetteRecord)
+}
+
+// LoadCassette reads the cassette at path for replay.
+func LoadCassette(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var interactions []Interaction
+	if err := decoder.Decode(&interactions); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+
+	c := newCassette(path, CassetteReplay)
+	c.interactions = interactions
+	c.used = make([]bool, len(interactions))
+	return c, nil
+}
+
+func newCassette(path string, mode CassetteMode) *Cassette {
+	c := &Cassette{path: path, mode: mode, scrub: make(map[string]struct{}, len(SecretFields))}
+	for _, field := range SecretFields {
+		c.scrub[strings.ToLower(field)] = struct{}{}
+	}
+	return c
+}
+
+// ScrubFields adds fields whose values are replaced before they are recorded,
+// e.g. customer identifiers. Field names match case-insensitively at any depth.
+func (c *Cassette) ScrubFields(fields ...string) *Cassette {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, field := range fields {
+		c.scrub[strings.ToLower(field)] = struct{}{}
+	}
+	return c
+}
+
+// Mode returns whether the cassette records or replays.
+func (c *Cassette) Mode() CassetteMode {
+	return c.mode
+}
+
+// Interactions returns a copy of the recorded interactions.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Save writes the interactions to the cassette file.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	raw, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.WriteFile(c.path, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Do runs one API call through the cassette. args are the plaintext request
+// args and send performs the call, returning the decrypted response body. In
+// record mode, send is called and a successful call is recorded; in replay
+// mode, the recorded response is returned and send is never called. A nil
+// Cassette just calls send.
+func (c *Cassette) Do(ctx context.Context, service, method, path string, args map[string]interface{}, send func() ([]byte, error)) ([]byte, error) {
+	if c == nil {
+		return send()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.mode == CassetteReplay {
+		return c.replay(service, method, path, args)
+	}
+
+	body, err := send()
+	if err != nil {
+		return nil, err
+	}
+	c.record(service, method, path, args, body)
+	return body, nil
+}
+
+// record appends a call to the cassette.
+func (c *Cassette) record(service, method, path string, args map[string]interface{}, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	interaction := Interaction{
+		Service: service,
+		Method:  method,
+		Path:    path,
+		Request: c.requestArgs(args),
+	}
+	if response, ok := c.scrubValue("", decodeUseNumber(body)).(map[string]interface{}); ok {
+		interaction.Response, _ = json.Marshal(response)
+	} else {
+		// Keep bodies that are not JSON objects verbatim, as a JSON string.
+		interaction.Response, _ = json.Marshal(string(body))
+	}
+	c.interactions = append(c.interactions, interaction)
+	c.used = append(c.used, false)
+}
+
+// replay returns the response of the next interaction matching a call.
+func (c *Cassette) replay(service, method, path string, args map[string]interface{}) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := canonicalJSON(c.requestArgs(args))
+	last := -1
+	for i, interaction := range c.interactions {
+		if interaction.Service != service || interaction.Method != method || interaction.Path != path ||
+			canonicalJSON(interaction.Request) != key {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return interaction.responseBody(), nil
+		}
+		last = i
+	}
+	if last >= 0 {
+		return c.interactions[last].responseBody(), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s %s %s", ErrCassetteMiss, service, method, path, key)
+}
+
+// responseBody returns the response body as it was received.
+func (i Interaction) responseBody() []byte {
+	var verbatim string
+	if err := json.Unmarshal(i.Response, &verbatim); err == nil {
+		return []byte(verbatim)
+	}
+	return i.Response
+}
+
+// requestArgs returns the scrubbed args without CassetteIgnoredFields. c.mu must be held.
+func (c *Cassette) requestArgs(args map[string]interface{}) map[string]interface{} {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	request, ok := c.scrubValue("", decodeUseNumber(raw)).(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	for _, field := range CassetteIgnoredFields {
+		delete(request, field)
+	}
+	return request
+}
+
+// scrubValue replaces the values of scrubbed fields. c.mu must be held.
+func (c *Cassette) scrubValue(key string, value interface{}) interface{} {
+	if _, ok := c.scrub[strings.ToLower(key)]; ok && value != nil {
+		return redactedValue
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = c.scrubValue(k, item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = c.scrubValue("", item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// decodeUseNumber decodes raw keeping numbers as written, or returns nil.
+func decodeUseNumber(raw []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	return value
+}
+
+// canonicalJSON encodes value with sorted keys.
+func canonicalJSON(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
@@ -0,0 +1,134 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// recordCall runs one call through c in record mode, answering with body.
+func recordCall(t *testing.T, c *Cassette, path string, args map[string]interface{}, body string) {
+	t.Helper()
+	_, err := c.Do(context.Background(), "waas", HTTPMethodPost, path, args, func() ([]byte, error) {
+		return []byte(body), nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+}
+
+func TestCassetteScrubsRecordedCalls(t *testing.T) {
+	c := NewCassetteRecorder(filepath.Join(t.TempDir(), "cassette.json")).ScrubFields("Email")
+	args := map[string]interface{}{
+		"time":    1700000000000,
+		"charset": "UTF-8",
+		"symbol":  "ETH",
+		"sign":    "request-signature",
+		"user": map[string]interface{}{
+			"email": "alice@example.com",
+			"keys":  []interface{}{map[string]interface{}{"api_key": "secret-key", "uid": 7}},
+		},
+	}
+	recordCall(t, c, "/user/info", args,
+		`{"code":"0","data":{"items":[{"id":9007199254740993,"EMAIL":"bob@example.com","sign":"server-signature"}]}}`)
+
+	interactions := c.Interactions()
+	if len(interactions) != 1 {
+		t.Fatalf("Interactions() = %d, want 1", len(interactions))
+	}
+
+	gotRequest, err := json.Marshal(interactions[0].Request)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	wantRequest := `{"sign":"[REDACTED]","symbol":"ETH","user":{"email":"[REDACTED]","keys":[{"api_key":"[REDACTED]","uid":7}]}}`
+	if string(gotRequest) != wantRequest {
+		t.Fatalf("request = %s, want %s", gotRequest, wantRequest)
+	}
+
+	wantResponse := `{"code":"0","data":{"items":[{"EMAIL":"[REDACTED]","id":9007199254740993,"sign":"[REDACTED]"}]}}`
+	if string(interactions[0].Response) != wantResponse {
+		t.Fatalf("response = %s, want %s", interactions[0].Response, wantResponse)
+	}
+}
+
+func TestCassetteReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := NewCassetteRecorder(path)
+	withdraw := map[string]interface{}{"request_id": "r-1", "time": 1}
+	recordCall(t, recorder, "/billing/withdraw", withdraw, `{"code":"0","data":{"status":0}}`)
+	recordCall(t, recorder, "/billing/withdraw", withdraw, `{"code":"0","data":{"status":1}}`)
+	recordCall(t, recorder, "/billing/syncDepositList", map[string]interface{}{"max_id": 0}, "not json")
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+	if cassette.Mode() != CassetteReplay {
+		t.Fatalf("Mode() = %v, want CassetteReplay", cassette.Mode())
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		args    map[string]interface{}
+		want    string
+		wantErr error
+	}{
+		{name: "first of repeated calls", path: "/billing/withdraw",
+			args: map[string]interface{}{"request_id": "r-1", "time": 2, "charset": "UTF-8"}, want: `{"code":"0","data":{"status":0}}`},
+		{name: "second of repeated calls", path: "/billing/withdraw",
+			args: map[string]interface{}{"request_id": "r-1", "time": 3}, want: `{"code":"0","data":{"status":1}}`},
+		{name: "repeated calls used up", path: "/billing/withdraw",
+			args: map[string]interface{}{"request_id": "r-1", "time": 4}, want: `{"code":"0","data":{"status":1}}`},
+		{name: "verbatim body", path: "/billing/syncDepositList",
+			args: map[string]interface{}{"max_id": 0}, want: "not json"},
+		{name: "other args", path: "/billing/withdraw",
+			args: map[string]interface{}{"request_id": "r-2"}, wantErr: ErrCassetteMiss},
+		{name: "other path", path: "/billing/transfer",
+			args: map[string]interface{}{"request_id": "r-1"}, wantErr: ErrCassetteMiss},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := cassette.Do(context.Background(), "waas", HTTPMethodPost, tt.path, tt.args, func() ([]byte, error) {
+				t.Fatal("send called in replay mode")
+				return nil, nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			// Saved responses are indented, so compare JSON bodies compacted.
+			var compact bytes.Buffer
+			if json.Compact(&compact, body) == nil {
+				body = compact.Bytes()
+			}
+			if string(body) != tt.want {
+				t.Fatalf("Do() = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestCassetteIgnoredFields(t *testing.T) {
+	c := NewCassetteRecorder(filepath.Join(t.TempDir(), "cassette.json"))
+	args := map[string]interface{}{"uid": 1}
+	for _, field := range CassetteIgnoredFields {
+		args[field] = "varies"
+	}
+	recordCall(t, c, "/account/getByUidAndSymbol", args, `{"code":"0"}`)
+
+	if got := c.Interactions()[0].Request; !reflect.DeepEqual(got, map[string]interface{}{"uid": json.Number("1")}) {
+		t.Fatalf("request = %v, want only uid", got)
+	}
+}
@@ -0,0 +1,145 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CheckpointStore persists the max_id each feed of a Syncer has processed up
+// to. Implementations must be safe for concurrent use.
+type CheckpointStore interface {
+	// Load returns the saved max_id of feed, or 0 when none was saved yet.
+	Load(ctx context.Context, feed string) (int64, error)
+
+	// Save records that feed has processed every record up to maxID.
+	Save(ctx context.Context, feed string, maxID int64) error
+}
+
+// MemoryCheckpointStore keeps checkpoints in memory, e.g. for tests or for
+// processes that resync from scratch on start.
+type MemoryCheckpointStore struct {
+	mu  sync.Mutex
+	ids map[string]int64
+}
+
+// NewMemoryCheckpointStore creates an empty MemoryCheckpointStore.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{ids: make(map[string]int64)}
+}
+
+// Load implements CheckpointStore.
+func (s *MemoryCheckpointStore) Load(ctx context.Context, feed string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ids[feed], nil
+}
+
+// Save implements CheckpointStore.
+func (s *MemoryCheckpointStore) Save(ctx context.Context, feed string, maxID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[feed] = maxID
+	return nil
+}
+
+// FileCheckpointStore keeps the checkpoints of all feeds in one JSON file. Every
+// Save writes a temporary file next to it and renames it into place, so a
+// crash leaves either the old or the new checkpoints, never a torn file.
+type FileCheckpointStore struct {
+	mu   sync.Mutex
+	path string
+	ids  map[string]int64
+}
+
+// NewFileCheckpointStore creates a FileCheckpointStore backed by path. The
+// file is read on first use; a missing file means no checkpoints yet.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+// Load implements CheckpointStore.
+func (s *FileCheckpointStore) Load(ctx context.Context, feed string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.read(); err != nil {
+		return 0, err
+	}
+	return s.ids[feed], nil
+}
+
+// Save implements CheckpointStore.
+func (s *FileCheckpointStore) Save(ctx context.Context, feed string, maxID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.read(); err != nil {
+		return err
+	}
+
+	previous, existed := s.ids[feed]
+	s.ids[feed] = maxID
+	if err := s.write(); err != nil {
+		if existed {
+			s.ids[feed] = previous
+		} else {
+			delete(s.ids, feed)
+		}
+		return err
+	}
+	return nil
+}
+
+// read loads the file once. s.mu must be held.
+func (s *FileCheckpointStore) read() error {
+	if s.ids != nil {
+		return nil
+	}
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.ids = make(map[string]int64)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+	ids := make(map[string]int64)
+	if err := json.Unmarshal(raw, &ids); err != nil {
+		return fmt.Errorf("failed to decode checkpoints %s: %w", s.path, err)
+	}
+	s.ids = ids
+	return nil
+}
+
+// write replaces the file with the current checkpoints. s.mu must be held.
+func (s *FileCheckpointStore) write() error {
+	raw, err := json.MarshalIndent(s.ids, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoints: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(raw, '\n'))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointStores(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checkpoints.json")
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		store  CheckpointStore
+		reopen func() CheckpointStore
+	}{
+		{name: "memory", store: NewMemoryCheckpointStore()},
+		{name: "file", store: NewFileCheckpointStore(path), reopen: func() CheckpointStore { return NewFileCheckpointStore(path) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.store.Load(ctx, "waas.deposits"); err != nil || got != 0 {
+				t.Fatalf("Load() before Save = %d, %v", got, err)
+			}
+			for _, save := range []struct {
+				feed  string
+				maxID int64
+			}{{"waas.deposits", 10}, {"mpc.web3", 3}, {"waas.deposits", 12}} {
+				if err := tt.store.Save(ctx, save.feed, save.maxID); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+
+			store := tt.store
+			if tt.reopen != nil {
+				store = tt.reopen()
+			}
+			if got, err := store.Load(ctx, "waas.deposits"); err != nil || got != 12 {
+				t.Fatalf("Load(waas.deposits) = %d, %v, want 12", got, err)
+			}
+			if got, err := store.Load(ctx, "mpc.web3"); err != nil || got != 3 {
+				t.Fatalf("Load(mpc.web3) = %d, %v, want 3", got, err)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("directory holds %v, %v, want only the checkpoint file", entries, err)
+	}
+
+	if err := os.WriteFile(path, []byte("{torn"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := NewFileCheckpointStore(path).Load(ctx, "waas.deposits"); err == nil {
+		t.Fatal("Load() of a corrupt file succeeded")
+	}
+}
@@ -0,0 +1,344 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+// Default circuit breaker settings.
+const (
+	// DefaultCircuitFailureThreshold is the number of consecutive failures that opens a circuit.
+	DefaultCircuitFailureThreshold = 5
+
+	// DefaultCircuitOpenTimeout is how long a circuit stays open before it lets a probe through.
+	DefaultCircuitOpenTimeout = 30 * time.Second
+)
+
+// CircuitState is the state of one circuit.
+type CircuitState int
+
+// Circuit states.
+const (
+	// CircuitClosed lets every call through.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen rejects every call with *sdkerrors.CircuitOpenError.
+	CircuitOpen
+
+	// CircuitHalfOpen lets a limited number of probe calls through.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CallOutcome is the outcome of a call guarded by a CircuitBreaker.
+type CallOutcome int
+
+// Call outcomes.
+const (
+	// CallSucceeded proves the host healthy: it closes a half-open circuit and
+	// resets the consecutive failures.
+	CallSucceeded CallOutcome = iota
+
+	// CallFailed counts against the circuit.
+	CallFailed
+
+	// CallIgnored proves nothing, e.g. a call cancelled by the caller. It only
+	// releases its probe slot.
+	CallIgnored
+)
+
+// CircuitSettings configures the circuits of a CircuitBreaker.
+type CircuitSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit (default: DefaultCircuitFailureThreshold).
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before it turns half-open
+	// (default: DefaultCircuitOpenTimeout).
+	OpenTimeout time.Duration
+
+	// HalfOpenMaxCalls is the number of probe calls allowed at once while the
+	// circuit is half-open (default: 1).
+	HalfOpenMaxCalls int
+}
+
+// CircuitStateChange describes a transition of one circuit.
+type CircuitStateChange struct {
+	Host  string
+	Group EndpointGroup
+	From  CircuitState
+	To    CircuitState
+}
+
+// CircuitBreaker keeps one circuit per host and endpoint group. A circuit opens
+// after FailureThreshold consecutive failures, rejects calls immediately while
+// open, and lets probe calls through once OpenTimeout has passed: a successful
+// probe closes it again and a failed one reopens it.
+//
+// Transport errors, timeouts and 5xx responses count as failures. Rate-limit
+// responses, API error codes and calls cancelled by the caller do not.
+// The breaker is safe for concurrent use.
+type CircuitBreaker struct {
+	mu            sync.Mutex
+	settings      CircuitSettings
+	groupSettings map[EndpointGroup]CircuitSettings
+	circuits      map[circuitKey]*circuit
+	onStateChange func(CircuitStateChange)
+}
+
+// circuitKey identifies a circuit.
+type circuitKey struct {
+	host  string
+	group EndpointGroup
+}
+
+// circuit holds the state of one circuit.
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+// NewCircuitBreaker creates a CircuitBreaker applying settings to every
+// endpoint group that has no settings of its own.
+func NewCircuitBreaker(settings CircuitSettings) *CircuitBreaker {
+	return &CircuitBreaker{
+		settings:      settings,
+		groupSettings: make(map[EndpointGroup]CircuitSettings),
+		circuits:      make(map[circuitKey]*circuit),
+	}
+}
+
+// SetGroupSettings sets the settings of the circuits of an endpoint group.
+func (cb *CircuitBreaker) SetGroupSettings(group EndpointGroup, settings CircuitSettings) *CircuitBreaker {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.groupSettings[group] = settings
+	return cb
+}
+
+// OnStateChange sets the callback invoked after every state transition, e.g.
+// to alert when a circuit opens. The callback must not block.
+func (cb *CircuitBreaker) OnStateChange(fn func(CircuitStateChange)) *CircuitBreaker {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onStateChange = fn
+	return cb
+}
+
+// State returns the current state of the circuit of host and group.
+func (cb *CircuitBreaker) State(host string, group EndpointGroup) CircuitState {
+	if cb == nil {
+		return CircuitClosed
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if c, ok := cb.circuits[circuitKey{host: host, group: group}]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+// CircuitStatus is a snapshot of one circuit of a CircuitBreaker.
+type CircuitStatus struct {
+	Host  string
+	Group EndpointGroup
+	State CircuitState
+}
+
+// Snapshot returns the state of every circuit in use.
+func (cb *CircuitBreaker) Snapshot() []CircuitStatus {
+	if cb == nil {
+		return nil
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	statuses := make([]CircuitStatus, 0, len(cb.circuits))
+	for key, c := range cb.circuits {
+		statuses = append(statuses, CircuitStatus{Host: key.host, Group: key.group, State: c.state})
+	}
+	return statuses
+}
+
+// Allow reports whether a call to host in group may be sent. When it may, the
+// returned done function must be called with the outcome of the call. When
+// the circuit is open, a *sdkerrors.CircuitOpenError is returned.
+// A nil CircuitBreaker allows every call.
+func (cb *CircuitBreaker) Allow(host string, group EndpointGroup) (done func(CallOutcome), err error) {
+	if cb == nil {
+		return func(CallOutcome) {}, nil
+	}
+
+	key := circuitKey{host: host, group: group}
+	now := time.Now()
+
+	cb.mu.Lock()
+	settings := cb.settingsFor(group)
+	c := cb.circuit(key)
+
+	var changes []CircuitStateChange
+	if c.state == CircuitOpen {
+		if wait := c.openedAt.Add(settings.openTimeout()).Sub(now); wait > 0 {
+			cb.mu.Unlock()
+			return nil, &sdkerrors.CircuitOpenError{Host: host, Group: string(group), RetryAfter: wait}
+		}
+		changes = append(changes, cb.transition(key, c, CircuitHalfOpen, now))
+	}
+	if c.state == CircuitHalfOpen {
+		if c.probes >= settings.halfOpenMaxCalls() {
+			cb.mu.Unlock()
+			cb.notify(changes)
+			return nil, &sdkerrors.CircuitOpenError{Host: host, Group: string(group)}
+		}
+		c.probes++
+	}
+	probe := c.state == CircuitHalfOpen
+	cb.mu.Unlock()
+	cb.notify(changes)
+
+	var once sync.Once
+	return func(outcome CallOutcome) {
+		once.Do(func() { cb.record(key, probe, outcome) })
+	}, nil
+}
+
+// record updates the circuit of key with the outcome of a call.
+func (cb *CircuitBreaker) record(key circuitKey, probe bool, outcome CallOutcome) {
+	now := time.Now()
+
+	cb.mu.Lock()
+	settings := cb.settingsFor(key.group)
+	c := cb.circuit(key)
+	if probe && c.probes > 0 {
+		c.probes--
+	}
+
+	var changes []CircuitStateChange
+	switch {
+	case outcome == CallIgnored:
+		// Neither healthy nor failing: leave failures and state alone.
+	case outcome == CallSucceeded:
+		c.failures = 0
+		if c.state == CircuitHalfOpen {
+			changes = append(changes, cb.transition(key, c, CircuitClosed, now))
+		}
+	case c.state == CircuitHalfOpen:
+		changes = append(changes, cb.transition(key, c, CircuitOpen, now))
+	case c.state == CircuitClosed:
+		c.failures++
+		if c.failures >= settings.failureThreshold() {
+			changes = append(changes, cb.transition(key, c, CircuitOpen, now))
+		}
+	}
+	cb.mu.Unlock()
+	cb.notify(changes)
+}
+
+// transition moves c to state and returns the change. cb.mu must be held.
+func (cb *CircuitBreaker) transition(key circuitKey, c *circuit, state CircuitState, now time.Time) CircuitStateChange {
+	change := CircuitStateChange{Host: key.host, Group: key.group, From: c.state, To: state}
+	c.state = state
+	switch state {
+	case CircuitOpen:
+		c.openedAt = now
+		c.probes = 0
+	case CircuitClosed:
+		c.failures = 0
+		c.probes = 0
+	}
+	return change
+}
+
+// notify invokes the state change callback. cb.mu must not be held.
+func (cb *CircuitBreaker) notify(changes []CircuitStateChange) {
+	if len(changes) == 0 {
+		return
+	}
+	cb.mu.Lock()
+	fn := cb.onStateChange
+	cb.mu.Unlock()
+
+	if fn == nil {
+		return
+	}
+	for _, change := range changes {
+		fn(change)
+	}
+}
+
+// settingsFor returns the settings of group. cb.mu must be held.
+func (cb *CircuitBreaker) settingsFor(group EndpointGroup) CircuitSettings {
+	if settings, ok := cb.groupSettings[group]; ok {
+		return settings
+	}
+	return cb.settings
+}
+
+// circuit returns the circuit of key, creating it closed. cb.mu must be held.
+func (cb *CircuitBreaker) circuit(key circuitKey) *circuit {
+	c, ok := cb.circuits[key]
+	if !ok {
+		c = &circuit{}
+		cb.circuits[key] = c
+	}
+	return c
+}
+
+func (s CircuitSettings) failureThreshold() int {
+	if s.FailureThreshold < 1 {
+		return DefaultCircuitFailureThreshold
+	}
+	return s.FailureThreshold
+}
+
+func (s CircuitSettings) openTimeout() time.Duration {
+	if s.OpenTimeout <= 0 {
+		return DefaultCircuitOpenTimeout
+	}
+	return s.OpenTimeout
+}
+
+func (s CircuitSettings) halfOpenMaxCalls() int {
+	if s.HalfOpenMaxCalls < 1 {
+		return 1
+	}
+	return s.HalfOpenMaxCalls
+}
+
+// circuitOutcome classifies an attempt for the circuit breaker. Calls
+// cancelled by the caller are ignored, and responses proving the host is
+// healthy, such as 4xx statuses, count as successes.
+func circuitOutcome(ctx context.Context, err error) CallOutcome {
+	if err == nil {
+		return CallSucceeded
+	}
+	if ctx.Err() != nil {
+		return CallIgnored
+	}
+
+	var statusErr *sdkerrors.HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode < http.StatusInternalServerError {
+		return CallSucceeded
+	}
+	return CallFailed
+}
@@ -0,0 +1,178 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	var changes []CircuitStateChange
+	breaker := NewCircuitBreaker(CircuitSettings{FailureThreshold: 2, OpenTimeout: 30 * time.Millisecond}).
+		OnStateChange(func(change CircuitStateChange) { changes = append(changes, change) })
+
+	call := func(outcome CallOutcome) error {
+		done, err := breaker.Allow("host", GroupSync)
+		if err != nil {
+			return err
+		}
+		done(outcome)
+		return nil
+	}
+
+	_ = call(CallFailed)
+	_ = call(CallSucceeded)
+	_ = call(CallFailed)
+	if got := breaker.State("host", GroupSync); got != CircuitClosed {
+		t.Fatalf("State() after non-consecutive failures = %s, want closed", got)
+	}
+
+	_ = call(CallFailed)
+	if got := breaker.State("host", GroupSync); got != CircuitOpen {
+		t.Fatalf("State() after threshold = %s, want open", got)
+	}
+	err := call(CallSucceeded)
+	var openErr *sdkerrors.CircuitOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, sdkerrors.ErrCircuitOpen) || openErr.RetryAfter <= 0 {
+		t.Fatalf("Allow() while open error = %v, want *sdkerrors.CircuitOpenError", err)
+	}
+	if done, err := breaker.Allow("host", GroupMoneyMoving); err != nil {
+		t.Fatalf("Allow() for another group error = %v", err)
+	} else {
+		done(CallSucceeded)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	probe, err := breaker.Allow("host", GroupSync)
+	if err != nil {
+		t.Fatalf("Allow() after OpenTimeout error = %v", err)
+	}
+	if _, err := breaker.Allow("host", GroupSync); !errors.Is(err, sdkerrors.ErrCircuitOpen) {
+		t.Fatalf("second probe error = %v, want ErrCircuitOpen", err)
+	}
+	probe(CallFailed)
+	if got := breaker.State("host", GroupSync); got != CircuitOpen {
+		t.Fatalf("State() after failed probe = %s, want open", got)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if err := call(CallSucceeded); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(changes) != len(want) {
+		t.Fatalf("state changes = %+v, want %v", changes, want)
+	}
+	for i, change := range changes {
+		if change.To != want[i] || change.Host != "host" || change.Group != GroupSync {
+			t.Fatalf("change %d = %+v, want transition to %s", i, change, want[i])
+		}
+	}
+}
+
+func TestCircuitBreakerHTTP(t *testing.T) {
+	var hits, healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":"0"}`))
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(CircuitSettings{FailureThreshold: 2, OpenTimeout: 30 * time.Millisecond})
+	client := NewMpcHTTPClient(server.URL, "app", "key", DefaultTimeout, false, WithCircuitBreaker(breaker))
+	ctx := WithCallInfo(context.Background(), CallInfo{Idempotent: true, Group: GroupMetadata})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetContext(ctx, "/test", nil); err == nil {
+			t.Fatalf("GetContext() should fail while the host is unavailable")
+		}
+	}
+	if _, err := client.GetContext(ctx, "/test", nil); !errors.Is(err, sdkerrors.ErrCircuitOpen) {
+		t.Fatalf("GetContext() error = %v, want ErrCircuitOpen", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("server hits = %d, want 2", got)
+	}
+
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(40 * time.Millisecond)
+	if _, err := client.GetContext(ctx, "/test", nil); err != nil {
+		t.Fatalf("GetContext() after recovery error = %v", err)
+	}
+	if got := breaker.State(hostOf(server.URL), GroupMetadata); got != CircuitClosed {
+		t.Fatalf("State() = %s, want closed", got)
+	}
+}
+
+func TestCircuitBreakerIgnoredCalls(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitSettings{FailureThreshold: 2, OpenTimeout: 30 * time.Millisecond})
+	call := func(outcome CallOutcome) error {
+		done, err := breaker.Allow("host", GroupSync)
+		if err != nil {
+			return err
+		}
+		done(outcome)
+		return nil
+	}
+
+	_ = call(CallFailed)
+	_ = call(CallIgnored)
+	_ = call(CallFailed)
+	if got := breaker.State("host", GroupSync); got != CircuitOpen {
+		t.Fatalf("State() = %s, want open: an ignored call must not reset the failures", got)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	probe, err := breaker.Allow("host", GroupSync)
+	if err != nil {
+		t.Fatalf("Allow() after OpenTimeout error = %v", err)
+	}
+	probe(circuitOutcome(ctx, context.Canceled))
+	if got := breaker.State("host", GroupSync); got != CircuitHalfOpen {
+		t.Fatalf("State() after cancelled probe = %s, want half-open", got)
+	}
+	if err := call(CallSucceeded); err != nil {
+		t.Fatalf("Allow() after cancelled probe error = %v, want the probe slot released", err)
+	}
+	if got := breaker.State("host", GroupSync); got != CircuitClosed {
+		t.Fatalf("State() after successful probe = %s, want closed", got)
+	}
+}
+
+func TestCircuitOutcome(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want CallOutcome
+	}{
+		{name: "success", ctx: context.Background(), want: CallSucceeded},
+		{name: "client error", ctx: context.Background(), err: &sdkerrors.HTTPStatusError{StatusCode: http.StatusBadRequest}, want: CallSucceeded},
+		{name: "server error", ctx: context.Background(), err: &sdkerrors.HTTPStatusError{StatusCode: http.StatusBadGateway}, want: CallFailed},
+		{name: "transport error", ctx: context.Background(), err: errors.New("connection refused"), want: CallFailed},
+		{name: "cancelled by caller", ctx: cancelled, err: context.Canceled, want: CallIgnored},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := circuitOutcome(tt.ctx, tt.err); got != tt.want {
+				t.Fatalf("circuitOutcome() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
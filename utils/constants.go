@@ -13,6 +13,12 @@ const (
 	DefaultTimeout = 30
 
 	DefaultDomain = "https://openapi.chainup.com"
+
+	// DefaultMaxIdleConns is the default size of the idle connection pool.
+	DefaultMaxIdleConns = 100
+
+	// DefaultMaxIdleConnsPerHost is the default number of idle connections kept per host.
+	DefaultMaxIdleConnsPerHost = 32
 )
 
 // HTTP method constants.
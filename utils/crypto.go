@@ -264,7 +264,6 @@ func (r *RSACryptoProvider) GetSigningKey() *rsa.PrivateKey {
 // Used for MPC withdraw and web3 transaction signatures.
 // If signPrivateKey is set, it will be used for signing; otherwise privateKey is used.
 func (r *RSACryptoProvider) SignWithPrivateKey(data string) (string, error) {
-	fmt.Println("==========> ", data)
 	// Use signPrivateKey if set, otherwise use privateKey
 	signingKey := r.GetSigningKey()
 	if signingKey == nil {
@@ -385,3 +384,22 @@ func ParsePublicKey(keyStr string) (*rsa.PublicKey, error) {
 	// Try PKCS1 format
 	return x509.ParsePKCS1PublicKey(keyBytes)
 }
+
+// GenerateRSAKeyPair generates an RSA key pair and returns it PEM-encoded, the
+// private key in PKCS1 and the public key in PKIX format. It is meant for tests
+// and fake servers.
+func GenerateRSAKeyPair(bits int) (privateKeyPEM, publicKeyPEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	privateKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	publicKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	return privateKeyPEM, publicKeyPEM, nil
+}
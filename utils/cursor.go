@@ -0,0 +1,148 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"fmt"
+)
+
+// PageFunc fetches the page of records after maxID from a max_id sync
+// endpoint, such as SyncDepositList.
+type PageFunc[T any] func(ctx context.Context, maxID int64) ([]T, error)
+
+// PageOf adapts a Sync*Context method to a PageFunc returning the records
+// selected by data.
+func PageOf[R, T any](sync func(context.Context, int64) (*R, error), data func(*R) []T) PageFunc[T] {
+	return func(ctx context.Context, maxID int64) ([]T, error) {
+		result, err := sync(ctx, maxID)
+		if err != nil || result == nil {
+			return nil, err
+		}
+		return data(result), nil
+	}
+}
+
+// CursorOption configures a Cursor.
+type CursorOption func(*cursorOptions)
+
+type cursorOptions struct {
+	startID  int64
+	pageSize int
+}
+
+// WithStartID starts the cursor after maxID instead of at the first record,
+// e.g. to resume from a saved position.
+func WithStartID(maxID int64) CursorOption {
+	return func(o *cursorOptions) {
+		o.startID = maxID
+	}
+}
+
+// WithPageSizeHint tells the cursor how many records the endpoint returns per
+// full page. A shorter page is then known to be the last one, which saves the
+// request that would otherwise return the empty page.
+func WithPageSizeHint(size int) CursorOption {
+	return func(o *cursorOptions) {
+		o.pageSize = size
+	}
+}
+
+// Cursor walks a max_id sync endpoint page by page, advancing max_id to the ID
+// of the last returned record. It stops on an empty page, on an error and when
+// its context is canceled:
+//
+//	cursor := api.NewDepositCursor(ctx, client.GetBillingAPI(), utils.WithStartID(saved))
+//	for cursor.Next() {
+//		deposit := cursor.Item()
+//		// ...
+//	}
+//	if err := cursor.Err(); err != nil {
+//		return err
+//	}
+//	saved = cursor.MaxID()
+//
+// A Cursor is not safe for concurrent use.
+type Cursor[T any] struct {
+	ctx      context.Context
+	fetch    PageFunc[T]
+	id       func(T) int64
+	maxID    int64
+	pageSize int
+	page     []T
+	pos      int
+	item     T
+	err      error
+	last     bool
+}
+
+// NewCursor creates a Cursor fetching pages with fetch and reading the ID of
+// each record with id.
+func NewCursor[T any](ctx context.Context, fetch PageFunc[T], id func(T) int64, opts ...CursorOption) *Cursor[T] {
+	var options cursorOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Cursor[T]{
+		ctx:      ctx,
+		fetch:    fetch,
+		id:       id,
+		maxID:    options.startID,
+		pageSize: options.pageSize,
+	}
+}
+
+// Next advances to the next record, fetching the next page when needed. It
+// returns false once the records are exhausted or an error occurred.
+func (c *Cursor[T]) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	if err := c.ctx.Err(); err != nil {
+		c.err = err
+		return false
+	}
+
+	for c.pos >= len(c.page) {
+		if c.last {
+			return false
+		}
+		page, err := c.fetch(c.ctx, c.maxID)
+		if err != nil {
+			c.err = err
+			return false
+		}
+		if len(page) == 0 {
+			c.last = true
+			return false
+		}
+		// A page that does not move past max_id would be fetched forever.
+		if id := c.id(page[len(page)-1]); id <= c.maxID {
+			c.err = fmt.Errorf("sync cursor stalled: page after max_id %d ends at id %d", c.maxID, id)
+			return false
+		}
+		c.page, c.pos = page, 0
+		c.last = c.pageSize > 0 && len(page) < c.pageSize
+	}
+
+	c.item = c.page[c.pos]
+	c.pos++
+	c.maxID = c.id(c.item)
+	return true
+}
+
+// Item returns the current record.
+func (c *Cursor[T]) Item() T {
+	return c.item
+}
+
+// Err returns the error that stopped the cursor, or nil when the records were
+// exhausted.
+func (c *Cursor[T]) Err() error {
+	return c.err
+}
+
+// MaxID returns the ID of the current record, which is where a new cursor
+// resumes with WithStartID.
+func (c *Cursor[T]) MaxID() int64 {
+	return c.maxID
+}
@@ -0,0 +1,29 @@
+//go:build go1.23
+
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import "iter"
+
+// All returns an iterator over the remaining records for use with range. The
+// error that stopped the cursor, if any, is yielded last with a zero record:
+//
+//	for deposit, err := range cursor.All() {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+func (c *Cursor[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for c.Next() {
+			if !yield(c.Item(), nil) {
+				return
+			}
+		}
+		if err := c.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
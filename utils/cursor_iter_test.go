@@ -0,0 +1,42 @@
+//go:build go1.23
+
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCursorAll(t *testing.T) {
+	errBoom := errors.New("boom")
+	fetch := func(ctx context.Context, maxID int64) ([]int64, error) {
+		if maxID >= 4 {
+			return nil, errBoom
+		}
+		return []int64{maxID + 1, maxID + 2}, nil
+	}
+
+	var got []int64
+	var gotErr error
+	for id, err := range NewCursor(context.Background(), fetch, func(id int64) int64 { return id }).All() {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		got = append(got, id)
+	}
+	if !reflect.DeepEqual(got, []int64{1, 2, 3, 4}) || !errors.Is(gotErr, errBoom) {
+		t.Fatalf("All() = %v, %v", got, gotErr)
+	}
+
+	cursor := NewCursor(context.Background(), fetch, func(id int64) int64 { return id })
+	for range cursor.All() {
+		break
+	}
+	if cursor.MaxID() != 1 {
+		t.Fatalf("MaxID() after break = %d, want 1", cursor.MaxID())
+	}
+}
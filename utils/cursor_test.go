@@ -0,0 +1,101 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// pagedIDs serves the IDs above maxID in pages of size and records the max_id
+// of every request.
+func pagedIDs(ids []int64, size int, requests *[]int64) PageFunc[int64] {
+	return func(ctx context.Context, maxID int64) ([]int64, error) {
+		*requests = append(*requests, maxID)
+		var page []int64
+		for _, id := range ids {
+			if id > maxID && len(page) < size {
+				page = append(page, id)
+			}
+		}
+		return page, nil
+	}
+}
+
+func TestCursor(t *testing.T) {
+	ids := []int64{3, 5, 8, 13, 21}
+	identity := func(id int64) int64 { return id }
+
+	tests := []struct {
+		name         string
+		opts         []CursorOption
+		want         []int64
+		wantRequests []int64
+	}{
+		{name: "until empty page", want: ids, wantRequests: []int64{0, 8, 21}},
+		{name: "page size hint", opts: []CursorOption{WithPageSizeHint(3)}, want: ids, wantRequests: []int64{0, 8}},
+		{name: "start id", opts: []CursorOption{WithStartID(8)}, want: []int64{13, 21}, wantRequests: []int64{8, 21}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []int64
+			cursor := NewCursor(context.Background(), pagedIDs(ids, 3, &requests), identity, tt.opts...)
+			var got []int64
+			for cursor.Next() {
+				got = append(got, cursor.Item())
+			}
+			if cursor.Err() != nil || !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Fatalf("items = %v, requests = %v, err = %v, want %v, %v", got, requests, cursor.Err(), tt.want, tt.wantRequests)
+			}
+			if cursor.MaxID() != 21 {
+				t.Fatalf("MaxID() = %d, want 21", cursor.MaxID())
+			}
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var requests []int64
+		cursor := NewCursor(ctx, pagedIDs(ids, 3, &requests), identity)
+		if !cursor.Next() {
+			t.Fatalf("Next() = false, err = %v", cursor.Err())
+		}
+		cancel()
+		if cursor.Next() || !errors.Is(cursor.Err(), context.Canceled) || cursor.MaxID() != 3 {
+			t.Fatalf("Next() after cancel: err = %v, MaxID() = %d", cursor.Err(), cursor.MaxID())
+		}
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		cursor := NewCursor(context.Background(), func(context.Context, int64) ([]int64, error) { return nil, errBoom }, identity)
+		if cursor.Next() || !errors.Is(cursor.Err(), errBoom) {
+			t.Fatalf("Err() = %v, want %v", cursor.Err(), errBoom)
+		}
+	})
+
+	t.Run("stalled", func(t *testing.T) {
+		cursor := NewCursor(context.Background(), func(context.Context, int64) ([]int64, error) { return []int64{1}, nil }, identity, WithStartID(1))
+		if cursor.Next() || cursor.Err() == nil {
+			t.Fatalf("Next() on a page that does not advance: err = %v", cursor.Err())
+		}
+	})
+}
+
+func TestPageOf(t *testing.T) {
+	type result struct{ Data []int64 }
+	data := func(r *result) []int64 { return r.Data }
+
+	fetch := PageOf(func(ctx context.Context, maxID int64) (*result, error) {
+		return &result{Data: []int64{maxID + 1, maxID + 2}}, nil
+	}, data)
+	if page, err := fetch(context.Background(), 4); err != nil || !reflect.DeepEqual(page, []int64{5, 6}) {
+		t.Fatalf("fetch() = %v, %v, want [5 6]", page, err)
+	}
+
+	empty := PageOf(func(ctx context.Context, maxID int64) (*result, error) { return nil, nil }, data)
+	if page, err := empty(context.Background(), 4); err != nil || page != nil {
+		t.Fatalf("fetch() of a nil result = %v, %v, want no records", page, err)
+	}
+}
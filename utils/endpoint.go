@@ -0,0 +1,148 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// EndpointGroup classifies endpoints that share server-side limits.
+type EndpointGroup string
+
+// Endpoint groups used by the rate limiter and circuit breaker.
+const (
+	// GroupSync covers the cursor-based Sync* list endpoints.
+	GroupSync EndpointGroup = "sync"
+
+	// GroupMoneyMoving covers withdrawals, transfers and other calls that move funds.
+	GroupMoneyMoving EndpointGroup = "money_moving"
+
+	// GroupMetadata covers lookups, wallet management and everything else.
+	GroupMetadata EndpointGroup = "metadata"
+)
+
+// Endpoint describes one API endpoint wrapped by the SDK. The WaaS and MPC
+// API packages keep a table of endpoints and execute every typed method
+// through it, so adding an endpoint only needs a new table entry.
+type Endpoint struct {
+	// Name identifies the endpoint as "<API>.<Method>", e.g. "WithdrawAPI.Withdraw".
+	Name string
+
+	// Method is the HTTP method (HTTPMethodGet or HTTPMethodPost).
+	Method string
+
+	// Path is the endpoint path relative to the API prefix.
+	Path string
+
+	// Group is the endpoint group the call is limited under.
+	Group EndpointGroup
+
+	// Required lists the params that must be present and non-zero.
+	Required []string
+
+	// IdempotencyKey names the param that makes retries safe, e.g. "request_id".
+	IdempotencyKey string
+
+	// NonIdempotent marks endpoints that must never be retried.
+	NonIdempotent bool
+
+	// Result is the type the decrypted response is decoded into.
+	Result reflect.Type
+}
+
+// ResultType returns the reflect.Type of T for use in Endpoint.Result.
+func ResultType[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Validate checks that params holds every required field.
+func (e *Endpoint) Validate(params map[string]interface{}) error {
+	for _, field := range e.Required {
+		if isZeroParam(params[field]) {
+			return fmt.Errorf("parameter %q is required", field)
+		}
+	}
+	return nil
+}
+
+// CheckResult reports a mismatch between the endpoint's result type and T.
+func CheckResult[T any](e *Endpoint) error {
+	if e.Result != nil && e.Result != ResultType[T]() {
+		return fmt.Errorf("%s: result type %s does not match %s", e.Name, ResultType[T](), e.Result)
+	}
+	return nil
+}
+
+// Context returns ctx annotated with the endpoint name, group and retry
+// semantics of the call.
+func (e *Endpoint) Context(ctx context.Context, params map[string]interface{}) context.Context {
+	info := CallInfo{Idempotent: true, Endpoint: e.Name, Group: e.Group}
+	switch {
+	case e.NonIdempotent:
+		info.Idempotent = false
+	case e.IdempotencyKey != "":
+		info.Idempotent = StringField(params, e.IdempotencyKey) != ""
+	}
+	return WithCallInfo(ctx, info)
+}
+
+// Caller executes the typed endpoint calls of one API client. The WaaS and
+// MPC API packages build one from their request pipeline.
+type Caller struct {
+	// Invoke sends the request of one call and returns the decrypted response body.
+	Invoke func(ctx context.Context, method, path string, params map[string]interface{}) ([]byte, error)
+
+	// StartSpan starts the span of one call, named after the endpoint.
+	StartSpan func(ctx context.Context, name, path string, params map[string]interface{}) (context.Context, Span)
+
+	// Tracer traces the decoding of the responses.
+	Tracer Tracer
+
+	// Batch splits the lookups of LookupByIDs into parallel requests.
+	Batch BatchSettings
+}
+
+// CallEndpoint executes ep with params through c and decodes the decrypted
+// response straight into Resp, without an intermediate map.
+func CallEndpoint[Resp any](ctx context.Context, c *Caller, ep *Endpoint, params map[string]interface{}) (*Resp, error) {
+	if err := CheckResult[Resp](ep); err != nil {
+		return nil, err
+	}
+	if err := ep.Validate(params); err != nil {
+		return nil, err
+	}
+
+	ctx, span := c.StartSpan(ctx, ep.Name, ep.Path, params)
+	body, err := c.Invoke(ep.Context(ctx, params), ep.Method, ep.Path, params)
+	if err != nil {
+		EndSpan(span, err)
+		return nil, err
+	}
+
+	_, decodeSpan := StartSpan(ctx, c.Tracer, SpanDecode)
+	var result Resp
+	err = DecodeResult(body, &result)
+	EndSpan(decodeSpan, err)
+	if err != nil {
+		err = fmt.Errorf("failed to decode %s response: %w", ep.Name, err)
+		EndSpan(span, err)
+		return nil, err
+	}
+	span.End()
+	return &result, nil
+}
+
+// isZeroParam reports whether a param value is missing or empty.
+func isZeroParam(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
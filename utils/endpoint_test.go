@@ -0,0 +1,88 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEndpointValidate(t *testing.T) {
+	ep := &Endpoint{Name: "Test.Call", Required: []string{"symbol", "sub_wallet_id", "ids"}}
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "all present",
+			params: map[string]interface{}{"symbol": "ETH", "sub_wallet_id": int64(1), "ids": "1,2"},
+		},
+		{
+			name:    "missing",
+			params:  map[string]interface{}{"sub_wallet_id": int64(1), "ids": "1"},
+			wantErr: `parameter "symbol" is required`,
+		},
+		{
+			name:    "zero number",
+			params:  map[string]interface{}{"symbol": "ETH", "sub_wallet_id": int64(0), "ids": "1"},
+			wantErr: `parameter "sub_wallet_id" is required`,
+		},
+		{
+			name:    "empty string",
+			params:  map[string]interface{}{"symbol": "ETH", "sub_wallet_id": 1, "ids": ""},
+			wantErr: `parameter "ids" is required`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ep.Validate(tt.params)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEndpointContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		ep     *Endpoint
+		params map[string]interface{}
+		want   bool
+	}{
+		{name: "read", ep: &Endpoint{Name: "Test.Sync", Group: GroupSync}, want: true},
+		{name: "non-idempotent", ep: &Endpoint{NonIdempotent: true}, want: false},
+		{name: "with request id", ep: &Endpoint{IdempotencyKey: "request_id"}, params: map[string]interface{}{"request_id": "r1"}, want: true},
+		{name: "without request id", ep: &Endpoint{IdempotencyKey: "request_id"}, params: map[string]interface{}{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ep.Context(context.Background(), tt.params)
+			if got := isIdempotent(ctx); got != tt.want {
+				t.Fatalf("isIdempotent() = %v, want %v", got, tt.want)
+			}
+			if info, _ := CallInfoFromContext(ctx); info.Endpoint != tt.ep.Name || info.Group != tt.ep.Group {
+				t.Fatalf("CallInfo = %+v, want endpoint %q in group %q", info, tt.ep.Name, tt.ep.Group)
+			}
+		})
+	}
+}
+
+func TestCheckResult(t *testing.T) {
+	ep := &Endpoint{Name: "Test.Call", Result: ResultType[ResponseStatus]()}
+	if err := CheckResult[ResponseStatus](ep); err != nil {
+		t.Fatalf("CheckResult() error = %v", err)
+	}
+	if err := CheckResult[FlexInt](ep); err == nil {
+		t.Fatalf("CheckResult() should reject a mismatched result type")
+	}
+}
@@ -0,0 +1,162 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+// DefaultHostCooldown is how long a failing host is moved to the back of the
+// host list before it is preferred again.
+const DefaultHostCooldown = 30 * time.Second
+
+// HostStats is a snapshot of the health of one host.
+type HostStats struct {
+	// BaseURL is the base URL requests to the host are sent to.
+	BaseURL string
+
+	// Healthy reports whether the host is preferred in the current order.
+	Healthy bool
+
+	// ConsecutiveFailures counts the failures since the last success.
+	ConsecutiveFailures int
+
+	// Failovers counts the calls that moved on from this host to the next one.
+	Failovers uint64
+}
+
+// HostPool is an ordered list of base URLs with health tracking. Healthy hosts
+// are tried in the configured order; a host that fails is moved behind the
+// healthy ones for the cooldown period. The pool is safe for concurrent use.
+type HostPool struct {
+	mu       sync.Mutex
+	hosts    []*hostState
+	cooldown time.Duration
+}
+
+// hostState holds the health of one host.
+type hostState struct {
+	baseURL        string
+	host           string
+	failures       int
+	unhealthyUntil time.Time
+	failovers      uint64
+}
+
+// NewHostPool creates a HostPool from base URLs in order of preference.
+// Duplicate URLs are skipped. A cooldown of zero uses DefaultHostCooldown.
+func NewHostPool(baseURLs []string, cooldown time.Duration) *HostPool {
+	if cooldown <= 0 {
+		cooldown = DefaultHostCooldown
+	}
+
+	p := &HostPool{cooldown: cooldown}
+	seen := make(map[string]bool, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		if seen[baseURL] {
+			continue
+		}
+		seen[baseURL] = true
+		p.hosts = append(p.hosts, &hostState{baseURL: baseURL, host: hostOf(baseURL)})
+	}
+	return p
+}
+
+// Len returns the number of hosts in the pool.
+func (p *HostPool) Len() int {
+	return len(p.hosts)
+}
+
+// order returns the hosts in the order they should be tried: healthy hosts in
+// configured order, then the unhealthy ones, soonest to recover first.
+func (p *HostPool) order(now time.Time) []*hostState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ordered := make([]*hostState, 0, len(p.hosts))
+	var unhealthy []*hostState
+	for _, h := range p.hosts {
+		if now.Before(h.unhealthyUntil) {
+			unhealthy = append(unhealthy, h)
+			continue
+		}
+		ordered = append(ordered, h)
+	}
+	for len(unhealthy) > 0 {
+		next := 0
+		for i, h := range unhealthy {
+			if h.unhealthyUntil.Before(unhealthy[next].unhealthyUntil) {
+				next = i
+			}
+		}
+		ordered = append(ordered, unhealthy[next])
+		unhealthy = append(unhealthy[:next], unhealthy[next+1:]...)
+	}
+	return ordered
+}
+
+// markSuccess records a successful call to h.
+func (p *HostPool) markSuccess(h *hostState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h.failures = 0
+	h.unhealthyUntil = time.Time{}
+}
+
+// markFailure records a failed call to h. When failover is set, the call moves
+// on to another host.
+func (p *HostPool) markFailure(h *hostState, failover bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h.failures++
+	h.unhealthyUntil = time.Now().Add(p.cooldown)
+	if failover {
+		h.failovers++
+	}
+}
+
+// Stats returns a snapshot of the health of every host in configured order.
+func (p *HostPool) Stats() []HostStats {
+	if p == nil {
+		return nil
+	}
+	now := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]HostStats, len(p.hosts))
+	for i, h := range p.hosts {
+		stats[i] = HostStats{
+			BaseURL:             h.baseURL,
+			Healthy:             !now.Before(h.unhealthyUntil),
+			ConsecutiveFailures: h.failures,
+			Failovers:           h.failovers,
+		}
+	}
+	return stats
+}
+
+// isHostFailure reports whether err means the host itself is unavailable:
+// transport errors, timeouts, 5xx responses and open circuits. Calls cancelled
+// by the caller and answers from a working host, such as 4xx statuses, are not.
+func isHostFailure(ctx context.Context, err error) bool {
+	if errors.Is(err, sdkerrors.ErrCircuitOpen) {
+		return true
+	}
+	return circuitOutcome(ctx, err) == CallFailed
+}
+
+// canFailover reports whether a call that failed with err on one host may be
+// sent to the next one. Idempotent calls (reads, and money-moving calls carrying
+// a request_id) always may; any call may when it was never sent because the
+// circuit was open.
+func canFailover(ctx context.Context, err error) bool {
+	if !isHostFailure(ctx, err) {
+		return false
+	}
+	return isIdempotent(ctx) || errors.Is(err, sdkerrors.ErrCircuitOpen)
+}
@@ -0,0 +1,93 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFailover(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		primary      int
+		wantFailover bool
+	}{
+		{
+			name:         "read",
+			ctx:          WithCallInfo(context.Background(), CallInfo{Idempotent: true, Group: GroupSync}),
+			primary:      http.StatusBadGateway,
+			wantFailover: true,
+		},
+		{
+			name:         "money-moving with request_id",
+			ctx:          WithIdempotencyKey(context.Background(), "req-1"),
+			primary:      http.StatusServiceUnavailable,
+			wantFailover: true,
+		},
+		{
+			name:    "money-moving without request_id",
+			ctx:     WithIdempotencyKey(context.Background(), ""),
+			primary: http.StatusServiceUnavailable,
+		},
+		{
+			name:    "client error",
+			ctx:     context.Background(),
+			primary: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var primaryHits, secondaryHits int32
+			primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&primaryHits, 1)
+				w.WriteHeader(tt.primary)
+			}))
+			defer primary.Close()
+			secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&secondaryHits, 1)
+				_, _ = w.Write([]byte(`{"code":"0"}`))
+			}))
+			defer secondary.Close()
+
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+			client := NewMpcHTTPClient(primary.URL, "app", "key", DefaultTimeout, false,
+				WithFailoverHosts(secondary.URL), WithLogger(logger))
+
+			_, err := client.PostContext(tt.ctx, "/test", nil)
+			if gotFailover := err == nil; gotFailover != tt.wantFailover {
+				t.Fatalf("PostContext() error = %v, want failover %v", err, tt.wantFailover)
+			}
+			if got := atomic.LoadInt32(&secondaryHits) == 1; got != tt.wantFailover {
+				t.Fatalf("secondary hits = %d, want failover %v", secondaryHits, tt.wantFailover)
+			}
+			if got := strings.Contains(logs.String(), "failover"); got != tt.wantFailover {
+				t.Fatalf("failover logged = %v, want %v:\n%s", got, tt.wantFailover, logs.String())
+			}
+			if !tt.wantFailover {
+				return
+			}
+
+			stats := client.HostStats()
+			if len(stats) != 2 || stats[0].Healthy || stats[0].Failovers != 1 || !stats[1].Healthy {
+				t.Fatalf("HostStats() = %+v", stats)
+			}
+
+			// The failed host is skipped until its cooldown expires.
+			if _, err := client.PostContext(tt.ctx, "/test", nil); err != nil {
+				t.Fatalf("PostContext() error = %v", err)
+			}
+			if got := atomic.LoadInt32(&primaryHits); got != 1 {
+				t.Fatalf("primary hits = %d, want 1", got)
+			}
+		})
+	}
+}
@@ -2,12 +2,18 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
 )
 
 // RequestOption defines a function type for configuring HTTP requests.
@@ -29,37 +35,179 @@ func WithHeaders(headers map[string]string) RequestOption {
 	}
 }
 
+// ClientOption defines a function type for configuring a BaseHTTPClient.
+type ClientOption func(*BaseHTTPClient)
+
+// WithRetryPolicy enables automatic retries using the given policy.
+// A nil policy disables retries.
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(b *BaseHTTPClient) {
+		b.retryPolicy = policy
+	}
+}
+
+// NewDefaultTransport returns a dedicated *http.Transport with connection
+// pooling sized for SDK workloads. It is cloned from http.DefaultTransport so
+// proxy settings from the environment are still honored.
+func NewDefaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = DefaultMaxIdleConns
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	return transport
+}
+
+var (
+	sharedTransportOnce sync.Once
+	sharedTransport     *http.Transport
+)
+
+// SharedTransport returns the transport created with NewDefaultTransport on
+// first use and shared by every client configured without a Transport or
+// HTTPClient, so their connections are pooled together.
+func SharedTransport() *http.Transport {
+	sharedTransportOnce.Do(func() { sharedTransport = NewDefaultTransport() })
+	return sharedTransport
+}
+
+// WithHTTPClient makes the client send requests through c instead of building
+// its own http.Client. The timeout passed to NewBaseHTTPClient is ignored, and
+// a per-call timeout cannot extend the Timeout of c.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(b *BaseHTTPClient) {
+		if c != nil {
+			b.client = c
+		}
+	}
+}
+
+// WithTransport sets the RoundTripper used by the client, e.g. to configure
+// proxies, custom TLS roots, client certificates or connection pool limits.
+// It has no effect when combined with WithHTTPClient.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(b *BaseHTTPClient) {
+		if rt != nil {
+			b.transport = rt
+		}
+	}
+}
+
+// WithMiddleware appends middleware to the client's chain.
+// BeforeSend hooks run in the order given and AfterReceive hooks in reverse order.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(b *BaseHTTPClient) {
+		for _, mw := range middlewares {
+			if mw != nil {
+				b.middlewares = append(b.middlewares, mw)
+			}
+		}
+	}
+}
+
+// WithLogger sets the structured logger. Without it, debug mode logs to stdout.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(b *BaseHTTPClient) {
+		b.logger = logger
+	}
+}
+
+// WithRedactor sets the policy used to mask secrets and PII in logs.
+func WithRedactor(redactor *Redactor) ClientOption {
+	return func(b *BaseHTTPClient) {
+		if redactor != nil {
+			b.redactor = redactor
+		}
+	}
+}
+
+// WithRateLimiter makes the client wait for limiter before every attempt.
+// Requests are limited per app_id and endpoint group.
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(b *BaseHTTPClient) {
+		b.rateLimiter = limiter
+	}
+}
+
+// WithFailoverHosts adds base URLs tried in order when the primary base URL is
+// unavailable. Reads fail over automatically; money-moving calls only when they
+// carry a request_id.
+func WithFailoverHosts(baseURLs ...string) ClientOption {
+	return func(b *BaseHTTPClient) {
+		b.failoverURLs = append(b.failoverURLs, baseURLs...)
+	}
+}
+
+// WithCircuitBreaker guards every attempt with breaker. Circuits are kept per
+// host and endpoint group.
+func WithCircuitBreaker(breaker *CircuitBreaker) ClientOption {
+	return func(b *BaseHTTPClient) {
+		b.breaker = breaker
+	}
+}
+
 // BaseHTTPClient provides common HTTP request functionality.
 type BaseHTTPClient struct {
-	client  *http.Client
-	baseURL string
-	debug   bool
+	client       *http.Client
+	transport    http.RoundTripper
+	baseURL      string
+	failoverURLs []string
+	hosts        *HostPool
+	timeout      time.Duration
+	logger       *slog.Logger
+	redactor     *Redactor
+	retryPolicy  *RetryPolicy
+	rateLimiter  *RateLimiter
+	breaker      *CircuitBreaker
+	middlewares  []Middleware
 }
 
 // NewBaseHTTPClient creates a new base HTTP client.
-func NewBaseHTTPClient(baseURL string, timeout int, debug bool) *BaseHTTPClient {
+func NewBaseHTTPClient(baseURL string, timeout int, debug bool, opts ...ClientOption) *BaseHTTPClient {
 	if timeout <= 0 {
 		timeout = DefaultTimeout
 	}
 
-	return &BaseHTTPClient{
-		client: &http.Client{
-			Timeout: time.Duration(timeout) * time.Second,
-		},
-		baseURL: baseURL,
-		debug:   debug,
+	b := &BaseHTTPClient{
+		baseURL:  baseURL,
+		redactor: NewRedactor(),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.hosts = NewHostPool(append([]string{baseURL}, b.failoverURLs...), 0)
+
+	b.logger = ResolveLogger(b.logger, debug)
+
+	// The timeout is applied per attempt through the request context rather
+	// than http.Client.Timeout, so that a per-call timeout can extend it.
+	if b.client == nil {
+		b.client = &http.Client{Transport: b.transport}
+		b.timeout = time.Duration(timeout) * time.Second
 	}
+
+	return b
+}
+
+// hostOf returns the host of rawURL, or rawURL itself when it cannot be parsed.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
 }
 
 // buildRequest creates an HTTP request based on method and data.
-func (b *BaseHTTPClient) buildRequest(method, fullURL string, data map[string]interface{}) (*http.Request, error) {
+// The request is bound to ctx so that cancellation and deadlines abort it.
+func (b *BaseHTTPClient) buildRequest(ctx context.Context, method, fullURL string, data map[string]interface{}) (*http.Request, error) {
 	switch method {
 	case HTTPMethodPost:
 		formData := url.Values{}
 		for key, value := range data {
 			formData.Set(key, fmt.Sprintf("%v", value))
 		}
-		req, err := http.NewRequest(method, fullURL, strings.NewReader(formData.Encode()))
+		req, err := http.NewRequestWithContext(ctx, method, fullURL, strings.NewReader(formData.Encode()))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -67,7 +215,7 @@ func (b *BaseHTTPClient) buildRequest(method, fullURL string, data map[string]in
 		return req, nil
 
 	case HTTPMethodGet:
-		req, err := http.NewRequest(method, fullURL, nil)
+		req, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -85,39 +233,190 @@ func (b *BaseHTTPClient) buildRequest(method, fullURL string, data map[string]in
 	}
 }
 
-// execute performs the HTTP request and returns the response body.
+// execute performs the HTTP request through the middleware chain and returns the response body.
 func (b *BaseHTTPClient) execute(req *http.Request, logPrefix string) (string, error) {
-	if b.debug {
-		fmt.Printf("[%s Request] %s %s\n", logPrefix, req.Method, req.URL.String())
+	ctx := req.Context()
+	logger := CallLogger(ctx, b.logger)
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, logPrefix+" request",
+			slog.String("method", req.Method),
+			slog.String("url", b.redactor.RedactURL(req.URL.String())),
+		)
 	}
+	start := time.Now()
 
+	for _, mw := range b.middlewares {
+		if err := mw.BeforeSend(req); err != nil {
+			return "", err
+		}
+	}
+
+	resp, body, err := b.roundTrip(req)
+
+	for i := len(b.middlewares) - 1; i >= 0; i-- {
+		if mwErr := b.middlewares[i].AfterReceive(req, resp, body, err); mwErr != nil {
+			err = mwErr
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, logPrefix+" response",
+			slog.Int("status", resp.StatusCode),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("body", b.redactor.RedactJSON(string(body))),
+		)
+	}
+
+	return string(body), nil
+}
+
+// roundTrip sends the request and reads the whole response body.
+// Non-200 responses are reported as *sdkerrors.HTTPStatusError along with the response.
+func (b *BaseHTTPClient) roundTrip(req *http.Request) (*http.Response, []byte, error) {
 	resp, err := b.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("HTTP request failed: %w", err)
+		return nil, nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return resp, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return resp, body, &sdkerrors.HTTPStatusError{StatusCode: resp.StatusCode, Header: resp.Header, Body: string(body)}
 	}
 
-	if b.debug {
-		fmt.Printf("[%s Response] %s\n", logPrefix, string(body))
-	}
-
-	return string(body), nil
+	return resp, body, nil
 }
 
 // Request executes an HTTP request with optional configurations.
 func (b *BaseHTTPClient) Request(method, path string, data map[string]interface{}, opts ...RequestOption) (string, error) {
-	fullURL := b.baseURL + path
+	return b.RequestContext(context.Background(), method, path, data, opts...)
+}
+
+// RequestContext executes an HTTP request bound to ctx.
+// The request is aborted when ctx is cancelled or its deadline expires.
+func (b *BaseHTTPClient) RequestContext(ctx context.Context, method, path string, data map[string]interface{}, opts ...RequestOption) (string, error) {
+	b.logData(ctx, "HTTP", data)
+
+	return b.do(ctx, method, path, data, opts, "HTTP")
+}
 
-	req, err := b.buildRequest(method, fullURL, data)
+// do sends the request, retrying it according to the retry policy when the
+// request is idempotent and the failure is transient. Each attempt goes to the
+// preferred host of the pool and fails over to the next host when the host is
+// unavailable and the call may safely be sent again. The CallOptions bound to
+// ctx set the timeout of each attempt and extra request headers.
+func (b *BaseHTTPClient) do(ctx context.Context, method, path string, data map[string]interface{}, opts []RequestOption, logPrefix string) (string, error) {
+	callOpts := CallOptionsFromContext(ctx)
+	info, _ := CallInfoFromContext(ctx)
+	appID := StringField(data, "app_id")
+	timeout := b.timeout
+	if callOpts.Timeout > 0 {
+		timeout = callOpts.Timeout
+	}
+
+	attempts := 1
+	if isIdempotent(ctx) {
+		attempts = b.retryPolicy.attempts()
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := b.retryPolicy.Backoff(attempt - 1)
+			var statusErr *sdkerrors.HTTPStatusError
+			if errors.As(lastErr, &statusErr) {
+				if after := retryAfter(statusErr.Header); after > delay {
+					delay = after
+				}
+			}
+			CallLogger(ctx, b.logger).WarnContext(ctx, logPrefix+" retry",
+				slog.Int("attempt", attempt),
+				slog.Int("max_attempts", attempts),
+				slog.Duration("delay", delay),
+				slog.String("error", logError(lastErr)),
+			)
+			if err := sleepContext(ctx, delay); err != nil {
+				return "", lastErr
+			}
+		}
+
+		hosts := b.hosts.order(time.Now())
+		for i, host := range hosts {
+			if err := b.rateLimiter.Wait(ctx, appID, info.Group); err != nil {
+				if lastErr != nil && !errors.Is(err, sdkerrors.ErrRateLimited) {
+					return "", lastErr
+				}
+				return "", err
+			}
+
+			body, err := b.send(ctx, host, timeout, method, path, data, opts, callOpts.Headers, logPrefix)
+			if err == nil {
+				b.hosts.markSuccess(host)
+				return body, nil
+			}
+			lastErr = err
+
+			var statusErr *sdkerrors.HTTPStatusError
+			if errors.As(err, &statusErr) && errors.Is(err, sdkerrors.ErrRateLimited) {
+				b.rateLimiter.Throttle(appID, info.Group, retryAfter(statusErr.Header))
+			}
+
+			if !isHostFailure(ctx, err) {
+				break
+			}
+			failover := i+1 < len(hosts) && canFailover(ctx, err)
+			if !errors.Is(err, sdkerrors.ErrCircuitOpen) {
+				b.hosts.markFailure(host, failover)
+			}
+			if !failover {
+				break
+			}
+			CallLogger(ctx, b.logger).WarnContext(ctx, logPrefix+" failover",
+				slog.String("from", host.host),
+				slog.String("to", hosts[i+1].host),
+				slog.String("endpoint", info.Endpoint),
+				slog.String("error", logError(err)),
+			)
+		}
+
+		if !isRetryableError(ctx, lastErr) {
+			break
+		}
+	}
+
+	return "", lastErr
+}
+
+// send sends one attempt of the request to host, guarded by the circuit breaker.
+func (b *BaseHTTPClient) send(ctx context.Context, host *hostState, timeout time.Duration, method, path string, data map[string]interface{}, opts []RequestOption, headers map[string]string, logPrefix string) (string, error) {
+	info, _ := CallInfoFromContext(ctx)
+	done, err := b.breaker.Allow(host.host, info.Group)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := b.attempt(ctx, timeout, method, host.baseURL+path, data, opts, headers, logPrefix)
+	done(circuitOutcome(ctx, err))
+	return body, err
+}
+
+// attempt sends the request once, bounded by timeout when it is positive.
+func (b *BaseHTTPClient) attempt(ctx context.Context, timeout time.Duration, method, fullURL string, data map[string]interface{}, opts []RequestOption, headers map[string]string, logPrefix string) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := b.buildRequest(ctx, method, fullURL, data)
 	if err != nil {
 		return "", err
 	}
@@ -127,11 +426,75 @@ func (b *BaseHTTPClient) Request(method, path string, data map[string]interface{
 		opt(req)
 	}
 
-	if b.debug {
-		fmt.Printf("[HTTP Data] %+v\n", data)
+	// Per-call headers never replace the ones set by the SDK.
+	for key, value := range headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	return b.execute(req, logPrefix)
+}
+
+// ReportRateLimited feeds a rate-limit response code of the call bound to ctx
+// back into the rate limiter, pausing the bucket of appID.
+func (b *BaseHTTPClient) ReportRateLimited(ctx context.Context, appID string) {
+	info, _ := CallInfoFromContext(ctx)
+	b.rateLimiter.Throttle(appID, info.Group, 0)
+}
+
+// RetryRateLimited runs call, one API call bound to ctx, and runs it again
+// according to the retry policy while it fails with a rate-limit response code,
+// i.e. an *sdkerrors.APIError matching sdkerrors.ErrRateLimited. ChainUp
+// reports rate limits this way in the body of an HTTP 200 response, which the
+// HTTP-level retries of the policy never see. Each rate-limited attempt pauses
+// the bucket of appID, so the next attempt also waits for the rate limiter.
+// Calls that are not idempotent run once.
+func (b *BaseHTTPClient) RetryRateLimited(ctx context.Context, appID string, call func() error) error {
+	attempts := 1
+	if isIdempotent(ctx) {
+		attempts = b.retryPolicy.attempts()
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = call(); !isRateLimitedResponse(err) {
+			return err
+		}
+		b.ReportRateLimited(ctx, appID)
+		if attempt >= attempts {
+			return err
+		}
+
+		delay := b.retryPolicy.Backoff(attempt)
+		CallLogger(ctx, b.logger).WarnContext(ctx, "API retry",
+			slog.Int("attempt", attempt+1),
+			slog.Int("max_attempts", attempts),
+			slog.Duration("delay", delay),
+			slog.String("error", logError(err)),
+		)
+		if sleepContext(ctx, delay) != nil {
+			return err
+		}
 	}
+}
 
-	return b.execute(req, "HTTP")
+// isRateLimitedResponse reports whether err is a rate-limit response code.
+func isRateLimitedResponse(err error) bool {
+	var apiErr *sdkerrors.APIError
+	return errors.As(err, &apiErr) && errors.Is(apiErr, sdkerrors.ErrRateLimited)
+}
+
+// HostStats returns the health of every host the client sends requests to.
+func (b *BaseHTTPClient) HostStats() []HostStats {
+	return b.hosts.Stats()
+}
+
+// logData logs the request form data at debug level with secrets masked.
+func (b *BaseHTTPClient) logData(ctx context.Context, logPrefix string, data map[string]interface{}) {
+	if logger := CallLogger(ctx, b.logger); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, logPrefix+" data", slog.Any("data", b.redactor.RedactMap(data)))
+	}
 }
 
 // Post executes a POST request.
@@ -144,6 +507,16 @@ func (b *BaseHTTPClient) Get(path string, data map[string]interface{}, opts ...R
 	return b.Request(HTTPMethodGet, path, data, opts...)
 }
 
+// PostContext executes a POST request bound to ctx.
+func (b *BaseHTTPClient) PostContext(ctx context.Context, path string, data map[string]interface{}, opts ...RequestOption) (string, error) {
+	return b.RequestContext(ctx, HTTPMethodPost, path, data, opts...)
+}
+
+// GetContext executes a GET request bound to ctx.
+func (b *BaseHTTPClient) GetContext(ctx context.Context, path string, data map[string]interface{}, opts ...RequestOption) (string, error) {
+	return b.RequestContext(ctx, HTTPMethodGet, path, data, opts...)
+}
+
 // HTTPClient provides HTTP request functionality for API communication.
 // It embeds BaseHTTPClient for common functionality.
 type HTTPClient struct {
@@ -151,19 +524,24 @@ type HTTPClient struct {
 }
 
 // NewHTTPClient creates a new HTTP client.
-func NewHTTPClient(baseURL string, timeout int, debug bool) *HTTPClient {
+func NewHTTPClient(baseURL string, timeout int, debug bool, opts ...ClientOption) *HTTPClient {
 	return &HTTPClient{
-		BaseHTTPClient: NewBaseHTTPClient(baseURL, timeout, debug),
+		BaseHTTPClient: NewBaseHTTPClient(baseURL, timeout, debug, opts...),
 	}
 }
 
 // Request executes an HTTP request with headers support.
 func (h *HTTPClient) Request(method, path string, data map[string]interface{}, headers map[string]string) (string, error) {
+	return h.RequestContext(context.Background(), method, path, data, headers)
+}
+
+// RequestContext executes an HTTP request with headers support bound to ctx.
+func (h *HTTPClient) RequestContext(ctx context.Context, method, path string, data map[string]interface{}, headers map[string]string) (string, error) {
 	var opts []RequestOption
 	if len(headers) > 0 {
 		opts = append(opts, WithHeaders(headers))
 	}
-	return h.BaseHTTPClient.Request(method, path, data, opts...)
+	return h.BaseHTTPClient.RequestContext(ctx, method, path, data, opts...)
 }
 
 // MpcHTTPClient provides HTTP request functionality for MPC API communication.
@@ -174,9 +552,9 @@ type MpcHTTPClient struct {
 }
 
 // NewMpcHTTPClient creates a new MPC HTTP client.
-func NewMpcHTTPClient(baseURL, appID, apiKey string, timeout int, debug bool) *MpcHTTPClient {
+func NewMpcHTTPClient(baseURL, appID, apiKey string, timeout int, debug bool, opts ...ClientOption) *MpcHTTPClient {
 	return &MpcHTTPClient{
-		BaseHTTPClient: NewBaseHTTPClient(baseURL, timeout, debug),
+		BaseHTTPClient: NewBaseHTTPClient(baseURL, timeout, debug, opts...),
 		appID:          appID,
 		apiKey:         apiKey,
 	}
@@ -184,6 +562,11 @@ func NewMpcHTTPClient(baseURL, appID, apiKey string, timeout int, debug bool) *M
 
 // Request executes an HTTP request for MPC API.
 func (m *MpcHTTPClient) Request(method, path string, data map[string]interface{}) (string, error) {
+	return m.RequestContext(context.Background(), method, path, data)
+}
+
+// RequestContext executes an HTTP request for MPC API bound to ctx.
+func (m *MpcHTTPClient) RequestContext(ctx context.Context, method, path string, data map[string]interface{}) (string, error) {
 	// Ensure data map exists and add app_id
 	if data == nil {
 		data = make(map[string]interface{})
@@ -196,23 +579,9 @@ func (m *MpcHTTPClient) Request(method, path string, data map[string]interface{}
 		opts = append(opts, WithHeader("API-KEY", m.apiKey))
 	}
 
-	fullURL := m.baseURL + path
-
-	req, err := m.buildRequest(method, fullURL, data)
-	if err != nil {
-		return "", err
-	}
-
-	// Apply options
-	for _, opt := range opts {
-		opt(req)
-	}
-
-	if m.debug {
-		fmt.Printf("[MPC HTTP Data] %+v\n", data)
-	}
+	m.logData(ctx, "MPC HTTP", data)
 
-	return m.execute(req, "MPC HTTP")
+	return m.do(ctx, method, path, data, opts, "MPC HTTP")
 }
 
 // Post executes a POST request.
@@ -224,3 +593,13 @@ func (m *MpcHTTPClient) Post(path string, data map[string]interface{}) (string,
 func (m *MpcHTTPClient) Get(path string, data map[string]interface{}) (string, error) {
 	return m.Request(HTTPMethodGet, path, data)
 }
+
+// PostContext executes a POST request bound to ctx.
+func (m *MpcHTTPClient) PostContext(ctx context.Context, path string, data map[string]interface{}) (string, error) {
+	return m.RequestContext(ctx, HTTPMethodPost, path, data)
+}
+
+// GetContext executes a GET request bound to ctx.
+func (m *MpcHTTPClient) GetContext(ctx context.Context, path string, data map[string]interface{}) (string, error) {
+	return m.RequestContext(ctx, HTTPMethodGet, path, data)
+}
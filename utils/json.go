@@ -0,0 +1,123 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DecodeJSON decodes data into v, keeping numbers that land in interface{}
+// values as json.Number instead of float64. Large IDs and high-precision
+// amounts therefore survive a later re-encode without losing digits.
+func DecodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after top-level JSON value")
+	}
+	return nil
+}
+
+// Remarshal converts src, typically a map produced by DecodeJSON, into the
+// typed value pointed to by dst. Numbers are copied digit for digit.
+func Remarshal(src interface{}, dst interface{}) error {
+	jsonBytes, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return DecodeJSON(jsonBytes, dst)
+}
+
+// ResponseStatus holds the code and msg fields shared by every decrypted
+// WaaS and MPC response. Decoding into it skips the data payload.
+type ResponseStatus struct {
+	Code interface{} `json:"code"`
+	Msg  interface{} `json:"msg"`
+}
+
+// CodeString returns the response code as a string, or "" when absent.
+func (s ResponseStatus) CodeString() string {
+	if s.Code == nil {
+		return ""
+	}
+	if code, ok := s.Code.(string); ok {
+		return code
+	}
+	return fmt.Sprint(s.Code)
+}
+
+// DecryptEnvelope returns the decrypted body of an encrypted response, whose
+// "data" field is a cipher string. Responses that carry no cipher (such as
+// plain error responses or invalid JSON) are returned unchanged. When
+// decryption fails, the original body is returned along with the error.
+func DecryptEnvelope(body []byte, crypto CryptoProvider) ([]byte, error) {
+	opened, _, err := OpenEnvelope(body, crypto)
+	return opened, err
+}
+
+// OpenEnvelope is DecryptEnvelope that also reports whether the body was
+// decrypted with the public key. Only a decrypted body is authentic.
+func OpenEnvelope(body []byte, crypto CryptoProvider) (opened []byte, decrypted bool, err error) {
+	if crypto == nil {
+		return body, false, nil
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return body, false, nil
+	}
+	if len(envelope.Data) == 0 || envelope.Data[0] != '"' {
+		return body, false, nil
+	}
+
+	var cipher string
+	if err := json.Unmarshal(envelope.Data, &cipher); err != nil || cipher == "" {
+		return body, false, nil
+	}
+
+	plain, err := crypto.DecryptWithPublicKey(cipher)
+	if err != nil {
+		return body, false, err
+	}
+	// Public key decryption tolerates any cipher, so a cipher that was not
+	// encrypted with the matching private key shows up as garbage.
+	if !json.Valid([]byte(plain)) {
+		return body, false, fmt.Errorf("decrypted data is not valid JSON")
+	}
+	return []byte(plain), true, nil
+}
+
+// DecodeResult decodes the response body of a typed call into result. Some
+// endpoints answer with a boolean data field (e.g. false on error); it is
+// treated as null.
+func DecodeResult(body []byte, result interface{}) error {
+	err := DecodeJSON(body, result)
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Value != "bool" {
+		return err
+	}
+
+	var envelope map[string]json.RawMessage
+	if json.Unmarshal(body, &envelope) != nil {
+		return err
+	}
+
+	if data := string(envelope["data"]); data != "true" && data != "false" {
+		return err
+	}
+	envelope["data"] = json.RawMessage("null")
+
+	patched, marshalErr := json.Marshal(envelope)
+	if marshalErr != nil {
+		return err
+	}
+	return DecodeJSON(patched, result)
+}
@@ -0,0 +1,119 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDecodeJSONKeepsNumbers(t *testing.T) {
+	raw := `{"code":0,"data":{"amount":1.123456789012345678,"id":9007199254740993,"list":[12345678901234567890]}}`
+
+	var parsed map[string]interface{}
+	if err := DecodeJSON([]byte(raw), &parsed); err != nil {
+		t.Fatalf("DecodeJSON() error = %v", err)
+	}
+
+	data := parsed["data"].(map[string]interface{})
+	if got := data["id"]; got != json.Number("9007199254740993") {
+		t.Fatalf("id = %#v, want json.Number 9007199254740993", got)
+	}
+	if got := data["list"].([]interface{})[0]; got != json.Number("12345678901234567890") {
+		t.Fatalf("list[0] = %#v, want json.Number 12345678901234567890", got)
+	}
+
+	out, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(out) != raw {
+		t.Fatalf("round trip = %s, want %s", out, raw)
+	}
+}
+
+func TestRemarshalIsLossless(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantID     int64
+		wantAmount string
+	}{
+		{
+			name:       "18 decimals as number",
+			raw:        `{"id":9007199254740993,"amount":123456789.123456789012345678}`,
+			wantID:     9007199254740993,
+			wantAmount: "123456789.123456789012345678",
+		},
+		{
+			name:       "18 decimals as string",
+			raw:        `{"id":"9223372036854775807","amount":"0.000000000000000001"}`,
+			wantID:     9223372036854775807,
+			wantAmount: "0.000000000000000001",
+		},
+	}
+
+	type record struct {
+		ID     FlexInt         `json:"id"`
+		Amount decimal.Decimal `json:"amount"`
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parsed map[string]interface{}
+			if err := DecodeJSON([]byte(tt.raw), &parsed); err != nil {
+				t.Fatalf("DecodeJSON() error = %v", err)
+			}
+
+			var got record
+			if err := Remarshal(parsed, &got); err != nil {
+				t.Fatalf("Remarshal() error = %v", err)
+			}
+			if got.ID.Int64() != tt.wantID {
+				t.Fatalf("ID = %d, want %d", got.ID.Int64(), tt.wantID)
+			}
+			if got.Amount.String() != tt.wantAmount {
+				t.Fatalf("Amount = %s, want %s", got.Amount.String(), tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	type result struct {
+		Code string `json:"code"`
+		Data *struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	tests := []struct {
+		name    string
+		body    string
+		wantID  int64
+		wantErr bool
+	}{
+		{name: "object data", body: `{"code":"0","data":{"id":7}}`, wantID: 7},
+		{name: "boolean data", body: `{"code":"1","data":false}`},
+		{name: "string data", body: `{"code":"0","data":"7"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got result
+			err := DecodeResult([]byte(tt.body), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var id int64
+			if got.Data != nil {
+				id = got.Data.ID
+			}
+			if id != tt.wantID {
+				t.Fatalf("data.id = %d, want %d", id, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+// redactedValue replaces secret values in log output.
+const redactedValue = "[REDACTED]"
+
+// SecretFields are always masked in log output. The encrypted "data" blob of
+// the request/response envelope is masked as well.
+var SecretFields = []string{
+	"sign", "signature", "api_key", "api-key", "private_key", "check_sum",
+}
+
+// DefaultPIIFields are masked in log output unless a Redactor is configured
+// with its own list.
+var DefaultPIIFields = []string{
+	"mobile", "email", "address", "address_to", "address_from", "to_address", "memo",
+}
+
+// Redactor masks secrets and personally identifiable information before
+// payloads are written to logs. Field names are matched case-insensitively at
+// any depth of a JSON document.
+type Redactor struct {
+	secrets map[string]struct{}
+	pii     map[string]struct{}
+}
+
+// NewRedactor creates a Redactor that masks SecretFields and the given PII
+// fields. When no PII fields are given, DefaultPIIFields are used.
+func NewRedactor(piiFields ...string) *Redactor {
+	if len(piiFields) == 0 {
+		piiFields = DefaultPIIFields
+	}
+
+	r := &Redactor{
+		secrets: make(map[string]struct{}, len(SecretFields)),
+		pii:     make(map[string]struct{}, len(piiFields)),
+	}
+	for _, field := range SecretFields {
+		r.secrets[strings.ToLower(field)] = struct{}{}
+	}
+	for _, field := range piiFields {
+		r.pii[strings.ToLower(field)] = struct{}{}
+	}
+	return r
+}
+
+// RedactValue masks value if key names a secret or PII field.
+func (r *Redactor) RedactValue(key string, value interface{}) interface{} {
+	key = strings.ToLower(key)
+	if _, ok := r.secrets[key]; ok {
+		return redactedValue
+	}
+	// The envelope "data" field is an encrypted blob when it is a string.
+	if s, ok := value.(string); ok && key == "data" && s != "" {
+		return redactedValue
+	}
+	if _, ok := r.pii[key]; ok {
+		return maskPII(value)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return r.RedactMap(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = r.RedactValue("", item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// RedactMap returns a copy of data with secret and PII values masked.
+func (r *Redactor) RedactMap(data map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		out[key] = r.RedactValue(key, value)
+	}
+	return out
+}
+
+// RedactJSON returns raw with secret and PII values masked.
+// Payloads that are not JSON objects or arrays are returned unchanged.
+func (r *Redactor) RedactJSON(raw string) string {
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return raw
+	}
+	switch parsed.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return raw
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.RedactValue("", parsed)); err != nil {
+		return redactedValue
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// RedactURL masks secret query parameters of rawURL, such as the encrypted data of GET requests.
+func (r *Redactor) RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+
+	query := u.Query()
+	for key, values := range query {
+		for i, value := range values {
+			if masked, ok := r.RedactValue(key, value).(string); ok {
+				values[i] = masked
+			}
+		}
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// maskPII keeps the first and last characters of long values so that log
+// lines stay correlatable without revealing the full value.
+func maskPII(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return redactedValue
+	}
+	runes := []rune(s)
+	if len(runes) < 10 {
+		return "***"
+	}
+	return string(runes[:4]) + "***" + string(runes[len(runes)-4:])
+}
+
+// discardHandler is a slog.Handler that drops every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// ResolveLogger returns logger if set. Otherwise it returns a text logger
+// writing debug output to stdout when debug is true, or a logger that
+// discards everything.
+func ResolveLogger(logger *slog.Logger, debug bool) *slog.Logger {
+	if logger != nil {
+		return logger
+	}
+	if debug {
+		return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return slog.New(discardHandler{})
+}
+
+// APICall holds the structured fields logged once per API call.
+type APICall struct {
+	// Service identifies the API family, e.g. "waas" or "mpc".
+	Service   string
+	Path      string
+	AppID     string
+	RequestID string
+	// Code is the response code returned by the server, if any.
+	Code    string
+	Latency time.Duration
+	Err     error
+}
+
+// LogAPICall writes a single summary line for an API call: at info level on
+// success and at warn level when the call failed.
+func LogAPICall(ctx context.Context, logger *slog.Logger, call APICall) {
+	level := slog.LevelInfo
+	if call.Err != nil {
+		level = slog.LevelWarn
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("path", call.Path),
+		slog.String("app_id", call.AppID),
+		slog.Duration("latency", call.Latency),
+	}
+	if call.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", call.RequestID))
+	}
+	if call.Code != "" {
+		attrs = append(attrs, slog.String("code", call.Code))
+	}
+	if call.Err != nil {
+		attrs = append(attrs, slog.String("error", logError(call.Err)))
+	}
+
+	logger.LogAttrs(ctx, level, call.Service+" api call", attrs...)
+}
+
+// logError returns the text of err for log output. The body of an
+// *sdkerrors.HTTPStatusError is left out: it is not redacted and may carry
+// anything the server or a proxy sent back.
+func logError(err error) string {
+	var statusErr *sdkerrors.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return fmt.Sprintf("HTTP %d", statusErr.StatusCode)
+	}
+	return err.Error()
+}
+
+// StringField returns data[key] formatted as a string, or "" when absent.
+func StringField(data map[string]interface{}, key string) string {
+	value, ok := data[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
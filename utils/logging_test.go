@@ -0,0 +1,131 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactJSON(t *testing.T) {
+	redactor := NewRedactor()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "secrets",
+			in:   `{"sign":"abc","api_key":"k","symbol":"ETH"}`,
+			want: `{"api_key":"[REDACTED]","sign":"[REDACTED]","symbol":"ETH"}`,
+		},
+		{
+			name: "encrypted envelope",
+			in:   `{"app_id":"app","data":"cipher"}`,
+			want: `{"app_id":"app","data":"[REDACTED]"}`,
+		},
+		{
+			name: "nested pii",
+			in:   `{"code":0,"data":{"list":[{"address":"0x1234567890abcdef","email":"a@b.c"}]}}`,
+			want: `{"code":0,"data":{"list":[{"address":"0x12***cdef","email":"***"}]}}`,
+		},
+		{
+			name: "multibyte pii",
+			in:   `{"email":"张三丰李四王五赵六钱七"}`,
+			want: `{"email":"张三丰李***赵六钱七"}`,
+		},
+		{
+			name: "large numbers kept",
+			in:   `{"amount":123456789.123456789012345678}`,
+			want: `{"amount":123456789.123456789012345678}`,
+		},
+		{
+			name: "not json",
+			in:   "plain text",
+			want: "plain text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.RedactJSON(tt.in); got != tt.want {
+				t.Fatalf("RedactJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorCustomPII(t *testing.T) {
+	redactor := NewRedactor("memo")
+
+	got := redactor.RedactJSON(`{"memo":"x","address":"0x1234567890abcdef"}`)
+	want := `{"address":"0x1234567890abcdef","memo":"***"}`
+	if got != want {
+		t.Fatalf("RedactJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestRequestLogsAreRedacted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":"0","data":"encrypted-response"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewMpcHTTPClient(server.URL, "app", "secret-api-key", DefaultTimeout, false, WithLogger(logger))
+
+	if _, err := client.PostContext(context.Background(), "/test", map[string]interface{}{"data": "encrypted-request"}); err != nil {
+		t.Fatalf("PostContext() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, secret := range []string{"encrypted-request", "encrypted-response", "secret-api-key"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("log output contains %q:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "status=200") {
+		t.Fatalf("log output is missing the response status:\n%s", out)
+	}
+}
+
+func TestErrorBodiesAreNotLogged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`{"email":"alice@example.com"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewBaseHTTPClient(server.URL, 5, false, WithLogger(logger), WithRetryPolicy(testRetryPolicy()))
+
+	_, err := client.GetContext(context.Background(), "/test", nil)
+	if err == nil {
+		t.Fatal("GetContext() error = nil, want HTTP 502")
+	}
+	LogAPICall(context.Background(), logger, APICall{Service: "waas", Path: "/test", Err: err})
+
+	out := buf.String()
+	if strings.Contains(out, "alice@example.com") {
+		t.Fatalf("log output contains the error body:\n%s", out)
+	}
+	if !strings.Contains(out, `msg="waas api call"`) || !strings.Contains(out, `error="HTTP 502"`) {
+		t.Fatalf("log output is missing the error status:\n%s", out)
+	}
+}
+
+func TestResolveLogger(t *testing.T) {
+	if ResolveLogger(nil, false).Enabled(context.Background(), slog.LevelError) {
+		t.Fatalf("default logger should discard output")
+	}
+	if !ResolveLogger(nil, true).Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatalf("debug logger should enable debug output")
+	}
+}
@@ -0,0 +1,423 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+// CallMetrics describes one finished API call.
+type CallMetrics struct {
+	// Service identifies the API family, e.g. "waas" or "mpc".
+	Service string
+
+	// Endpoint is the endpoint name, e.g. "WithdrawAPI.Withdraw", or the path
+	// for calls made outside the endpoint table.
+	Endpoint string
+
+	// Code is the response code returned by the server, if any.
+	Code    string
+	Latency time.Duration
+	Err     error
+}
+
+// Collector receives metrics from the shared request path of the WaaS and MPC
+// APIs. Implementations must be safe for concurrent use.
+type Collector interface {
+	// CallStarted is called when an API call is about to be sent.
+	CallStarted(service, endpoint string)
+
+	// CallFinished is called once the call returned, successfully or not.
+	CallFinished(call CallMetrics)
+}
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the call latency histogram.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics is a dependency-free Collector that also serves its metrics, plus
+// the state of the watched rate limiters, circuit breakers and hosts, in the
+// Prometheus text exposition format:
+//
+//	metrics := utils.NewMetrics()
+//	client, err := mpc.NewMpcClientBuilder().SetMetrics(metrics).Build()
+//	metrics.WatchHosts("mpc", client.HostStats)
+//	http.Handle("/metrics", metrics)
+type Metrics struct {
+	mu         sync.Mutex
+	buckets    []float64
+	calls      map[callKey]*callSeries
+	inFlight   map[inFlightKey]int64
+	unverified map[unverifiedKey]uint64
+	limiters   []*RateLimiter
+	breakers   []*CircuitBreaker
+	hostPools  map[string]func() []HostStats
+}
+
+// callKey identifies the series of calls with the same labels.
+type callKey struct {
+	service    string
+	endpoint   string
+	code       string
+	errorClass string
+}
+
+// inFlightKey identifies an in-flight gauge.
+type inFlightKey struct {
+	service  string
+	endpoint string
+}
+
+// unverifiedKey identifies the counter of unverified responses with the same labels.
+type unverifiedKey struct {
+	service  string
+	endpoint string
+	reason   string
+	action   string
+}
+
+// callSeries holds the counter and histogram of one callKey.
+type callSeries struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// NewMetrics creates an empty Metrics using DefaultLatencyBuckets.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets:    DefaultLatencyBuckets,
+		calls:      make(map[callKey]*callSeries),
+		inFlight:   make(map[inFlightKey]int64),
+		unverified: make(map[unverifiedKey]uint64),
+		hostPools:  make(map[string]func() []HostStats),
+	}
+}
+
+// CallStarted implements Collector.
+func (m *Metrics) CallStarted(service, endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[inFlightKey{service: service, endpoint: endpoint}]++
+}
+
+// CallFinished implements Collector.
+func (m *Metrics) CallFinished(call CallMetrics) {
+	key := callKey{
+		service:    call.Service,
+		endpoint:   call.Endpoint,
+		code:       call.Code,
+		errorClass: sdkerrors.Class(call.Err),
+	}
+	seconds := call.Latency.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[inFlightKey{service: call.Service, endpoint: call.Endpoint}]--
+
+	series, ok := m.calls[key]
+	if !ok {
+		series = &callSeries{buckets: make([]uint64, len(m.buckets))}
+		m.calls[key] = series
+	}
+	series.count++
+	series.sum += seconds
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			series.buckets[i]++
+		}
+	}
+}
+
+// UnverifiedResponse implements AuthenticityCollector.
+func (m *Metrics) UnverifiedResponse(service, endpoint, reason string, rejected bool) {
+	action := "accepted"
+	if rejected {
+		action = "rejected"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unverified[unverifiedKey{service: service, endpoint: endpoint, reason: reason, action: action}]++
+}
+
+// WatchRateLimiter exposes the buckets of limiter as gauges.
+func (m *Metrics) WatchRateLimiter(limiter *RateLimiter) *Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limiters = append(m.limiters, limiter)
+	return m
+}
+
+// WatchCircuitBreaker exposes the circuits of breaker as gauges.
+func (m *Metrics) WatchCircuitBreaker(breaker *CircuitBreaker) *Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.breakers = append(m.breakers, breaker)
+	return m
+}
+
+// WatchHosts exposes the health and failover counts reported by stats, such
+// as a client's HostStats method, under the given client label.
+func (m *Metrics) WatchHosts(client string, stats func() []HostStats) *Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hostPools[client] = stats
+	return m
+}
+
+// ServeHTTP renders the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Write renders the metrics in the Prometheus text exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	p := &promWriter{}
+
+	m.mu.Lock()
+	m.writeCalls(p)
+	m.writeUnverified(p)
+	limiters := append([]*RateLimiter(nil), m.limiters...)
+	breakers := append([]*CircuitBreaker(nil), m.breakers...)
+	hostPools := make(map[string]func() []HostStats, len(m.hostPools))
+	for client, stats := range m.hostPools {
+		hostPools[client] = stats
+	}
+	m.mu.Unlock()
+
+	writeLimiters(p, limiters)
+	writeBreakers(p, breakers)
+	writeHosts(p, hostPools)
+
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+// writeCalls renders the call counters, histograms and in-flight gauges. m.mu must be held.
+func (m *Metrics) writeCalls(p *promWriter) {
+	keys := make([]callKey, 0, len(m.calls))
+	for key := range m.calls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		return strings.Join([]string{a.service, a.endpoint, a.code, a.errorClass}, "\x00") <
+			strings.Join([]string{b.service, b.endpoint, b.code, b.errorClass}, "\x00")
+	})
+
+	p.header("chainup_sdk_calls_total", "counter", "API calls by endpoint, response code and error class.")
+	for _, key := range keys {
+		p.sample("chainup_sdk_calls_total", callLabels(key), float64(m.calls[key].count))
+	}
+
+	p.header("chainup_sdk_call_duration_seconds", "histogram", "API call latency by endpoint, response code and error class.")
+	for _, key := range keys {
+		series := m.calls[key]
+		labels := callLabels(key)
+		for i, bound := range m.buckets {
+			p.sample("chainup_sdk_call_duration_seconds_bucket", append(labels, "le", formatFloat(bound)), float64(series.buckets[i]))
+		}
+		p.sample("chainup_sdk_call_duration_seconds_bucket", append(labels, "le", "+Inf"), float64(series.count))
+		p.sample("chainup_sdk_call_duration_seconds_sum", labels, series.sum)
+		p.sample("chainup_sdk_call_duration_seconds_count", labels, float64(series.count))
+	}
+
+	inFlight := make([]inFlightKey, 0, len(m.inFlight))
+	for key := range m.inFlight {
+		inFlight = append(inFlight, key)
+	}
+	sort.Slice(inFlight, func(i, j int) bool {
+		if inFlight[i].service != inFlight[j].service {
+			return inFlight[i].service < inFlight[j].service
+		}
+		return inFlight[i].endpoint < inFlight[j].endpoint
+	})
+
+	p.header("chainup_sdk_calls_in_flight", "gauge", "API calls currently in flight.")
+	for _, key := range inFlight {
+		p.sample("chainup_sdk_calls_in_flight", []string{"service", key.service, "endpoint", key.endpoint}, float64(m.inFlight[key]))
+	}
+}
+
+// writeUnverified renders the unverified response counters. m.mu must be held.
+func (m *Metrics) writeUnverified(p *promWriter) {
+	keys := make([]unverifiedKey, 0, len(m.unverified))
+	for key := range m.unverified {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		return strings.Join([]string{a.service, a.endpoint, a.reason, a.action}, "\x00") <
+			strings.Join([]string{b.service, b.endpoint, b.reason, b.action}, "\x00")
+	})
+
+	p.header("chainup_sdk_unverified_responses_total", "counter", "Success responses not decrypted with the ChainUp public key, by reason and whether strict mode rejected them.")
+	for _, key := range keys {
+		labels := []string{"service", key.service, "endpoint", key.endpoint, "reason", key.reason, "action", key.action}
+		p.sample("chainup_sdk_unverified_responses_total", labels, float64(m.unverified[key]))
+	}
+}
+
+func callLabels(key callKey) []string {
+	return []string{"service", key.service, "endpoint", key.endpoint, "code", key.code, "error_class", key.errorClass}
+}
+
+func writeLimiters(p *promWriter, limiters []*RateLimiter) {
+	var states []RateLimitState
+	for _, limiter := range limiters {
+		states = append(states, limiter.Snapshot()...)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].AppID != states[j].AppID {
+			return states[i].AppID < states[j].AppID
+		}
+		return states[i].Group < states[j].Group
+	})
+
+	p.header("chainup_sdk_rate_limiter_tokens", "gauge", "Tokens available in each rate limiter bucket.")
+	for _, state := range states {
+		p.sample("chainup_sdk_rate_limiter_tokens", []string{"app_id", state.AppID, "group", string(state.Group)}, state.Tokens)
+	}
+	p.header("chainup_sdk_rate_limiter_blocked_seconds", "gauge", "Remaining pause of each rate limiter bucket after a rate-limit response.")
+	for _, state := range states {
+		p.sample("chainup_sdk_rate_limiter_blocked_seconds", []string{"app_id", state.AppID, "group", string(state.Group)}, state.BlockedFor.Seconds())
+	}
+}
+
+func writeBreakers(p *promWriter, breakers []*CircuitBreaker) {
+	var statuses []CircuitStatus
+	for _, breaker := range breakers {
+		statuses = append(statuses, breaker.Snapshot()...)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Host != statuses[j].Host {
+			return statuses[i].Host < statuses[j].Host
+		}
+		return statuses[i].Group < statuses[j].Group
+	})
+
+	p.header("chainup_sdk_circuit_state", "gauge", "Circuit breaker state: 0 closed, 1 open, 2 half-open.")
+	for _, status := range statuses {
+		p.sample("chainup_sdk_circuit_state", []string{"host", status.Host, "group", string(status.Group)}, float64(status.State))
+	}
+}
+
+func writeHosts(p *promWriter, hostPools map[string]func() []HostStats) {
+	clients := make([]string, 0, len(hostPools))
+	for client := range hostPools {
+		clients = append(clients, client)
+	}
+	sort.Strings(clients)
+
+	stats := make(map[string][]HostStats, len(clients))
+	for _, client := range clients {
+		stats[client] = hostPools[client]()
+	}
+
+	p.header("chainup_sdk_host_healthy", "gauge", "Whether each host is currently preferred (1) or cooling down (0).")
+	for _, client := range clients {
+		for _, host := range stats[client] {
+			healthy := 0.0
+			if host.Healthy {
+				healthy = 1
+			}
+			p.sample("chainup_sdk_host_healthy", []string{"client", client, "base_url", host.BaseURL}, healthy)
+		}
+	}
+	p.header("chainup_sdk_host_failovers_total", "counter", "Calls that failed over from each host to the next one.")
+	for _, client := range clients {
+		for _, host := range stats[client] {
+			p.sample("chainup_sdk_host_failovers_total", []string{"client", client, "base_url", host.BaseURL}, float64(host.Failovers))
+		}
+	}
+}
+
+// promWriter renders samples in the Prometheus text exposition format.
+type promWriter struct {
+	buf bytes.Buffer
+}
+
+func (p *promWriter) header(name, typ, help string) {
+	fmt.Fprintf(&p.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one sample; labels alternate between names and values.
+func (p *promWriter) sample(name string, labels []string, value float64) {
+	p.buf.WriteString(name)
+	if len(labels) > 0 {
+		p.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				p.buf.WriteByte(',')
+			}
+			p.buf.WriteString(labels[i])
+			p.buf.WriteString(`="`)
+			p.buf.WriteString(labelEscaper.Replace(labels[i+1]))
+			p.buf.WriteByte('"')
+		}
+		p.buf.WriteByte('}')
+	}
+	p.buf.WriteByte(' ')
+	p.buf.WriteString(formatFloat(value))
+	p.buf.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// TrackCall reports the start of an API call to collector and returns the
+// function reporting its end. The endpoint is taken from the CallInfo of ctx,
+// falling back to path. A nil collector records nothing.
+func TrackCall(ctx context.Context, collector Collector, service, path string) func(code string, err error) {
+	if collector == nil {
+		return func(string, error) {}
+	}
+	endpoint := callEndpoint(ctx, path)
+	start := time.Now()
+	collector.CallStarted(service, endpoint)
+	return func(code string, err error) {
+		collector.CallFinished(CallMetrics{
+			Service:  service,
+			Endpoint: endpoint,
+			Code:     code,
+			Latency:  time.Since(start),
+			Err:      err,
+		})
+	}
+}
+
+// callEndpoint returns the endpoint name from the CallInfo of ctx, falling back to path.
+func callEndpoint(ctx context.Context, path string) string {
+	if info, ok := CallInfoFromContext(ctx); ok && info.Endpoint != "" {
+		return info.Endpoint
+	}
+	return path
+}
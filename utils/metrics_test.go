@@ -0,0 +1,74 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+
+	ctx := WithCallInfo(context.Background(), CallInfo{Endpoint: "WithdrawAPI.Withdraw"})
+	metrics.CallStarted("mpc", "WithdrawAPI.Withdraw")
+	metrics.CallFinished(CallMetrics{Service: "mpc", Endpoint: "WithdrawAPI.Withdraw", Code: "0", Latency: 200 * time.Millisecond})
+	TrackCall(ctx, metrics, "mpc", "/api/mpc/billing/withdraw")("110", sdkerrors.FromCode("110", "insufficient balance"))
+	TrackCall(context.Background(), metrics, "waas", `/a"b`)("", errors.New("boom"))
+	metrics.CallStarted("waas", "/pending")
+
+	limiter := NewRateLimiter(RateLimit{Rate: 1, Burst: 2})
+	limiter.Throttle("app", GroupMoneyMoving, time.Minute)
+	metrics.WatchRateLimiter(limiter)
+
+	breaker := NewCircuitBreaker(CircuitSettings{FailureThreshold: 1})
+	if done, err := breaker.Allow("api.example.com", GroupSync); err == nil {
+		done(CallFailed)
+	}
+	metrics.WatchCircuitBreaker(breaker)
+
+	metrics.WatchHosts("mpc", func() []HostStats {
+		return []HostStats{{BaseURL: "https://a.example.com", Healthy: true}, {BaseURL: "https://b.example.com", Failovers: 3}}
+	})
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	body := rec.Body.String()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"success counter", `chainup_sdk_calls_total{service="mpc",endpoint="WithdrawAPI.Withdraw",code="0",error_class="none"} 1`},
+		{"api error counter", `chainup_sdk_calls_total{service="mpc",endpoint="WithdrawAPI.Withdraw",code="110",error_class="insufficient_balance"} 1`},
+		{"escaped path", `chainup_sdk_calls_total{service="waas",endpoint="/a\"b",code="",error_class="other"} 1`},
+		{"histogram bucket", `chainup_sdk_call_duration_seconds_bucket{service="mpc",endpoint="WithdrawAPI.Withdraw",code="0",error_class="none",le="0.1"} 0`},
+		{"histogram cumulative", `chainup_sdk_call_duration_seconds_bucket{service="mpc",endpoint="WithdrawAPI.Withdraw",code="0",error_class="none",le="0.25"} 1`},
+		{"histogram inf", `chainup_sdk_call_duration_seconds_bucket{service="mpc",endpoint="WithdrawAPI.Withdraw",code="0",error_class="none",le="+Inf"} 1`},
+		{"histogram sum", `chainup_sdk_call_duration_seconds_sum{service="mpc",endpoint="WithdrawAPI.Withdraw",code="0",error_class="none"} 0.2`},
+		{"in flight done", `chainup_sdk_calls_in_flight{service="mpc",endpoint="WithdrawAPI.Withdraw"} 0`},
+		{"in flight pending", `chainup_sdk_calls_in_flight{service="waas",endpoint="/pending"} 1`},
+		{"limiter tokens", `chainup_sdk_rate_limiter_tokens{app_id="app",group="money_moving"} `},
+		{"limiter blocked", `chainup_sdk_rate_limiter_blocked_seconds{app_id="app",group="money_moving"} `},
+		{"circuit open", `chainup_sdk_circuit_state{host="api.example.com",group="sync"} 1`},
+		{"host healthy", `chainup_sdk_host_healthy{client="mpc",base_url="https://a.example.com"} 1`},
+		{"host failovers", `chainup_sdk_host_failovers_total{client="mpc",base_url="https://b.example.com"} 3`},
+		{"type", "# TYPE chainup_sdk_call_duration_seconds histogram"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(body, tt.want) {
+				t.Fatalf("metrics output missing %q:\n%s", tt.want, body)
+			}
+		})
+	}
+}
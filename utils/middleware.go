@@ -0,0 +1,41 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import "net/http"
+
+// Middleware hooks into every HTTP round trip made by BaseHTTPClient.
+// Both the WaaS and MPC clients run their middleware chain around each
+// attempt, so a retried request passes through the chain once per attempt.
+type Middleware interface {
+	// BeforeSend is called before the request is sent.
+	// Returning an error aborts the request with that error.
+	BeforeSend(req *http.Request) error
+
+	// AfterReceive is called once the response body has been read, or once the
+	// request failed. resp and body are nil when no response was received.
+	// Returning a non-nil error replaces the outcome of the request.
+	AfterReceive(req *http.Request, resp *http.Response, body []byte, err error) error
+}
+
+// MiddlewareFuncs adapts plain functions to the Middleware interface.
+// Nil functions are skipped.
+type MiddlewareFuncs struct {
+	Before func(req *http.Request) error
+	After  func(req *http.Request, resp *http.Response, body []byte, err error) error
+}
+
+// BeforeSend implements Middleware.
+func (m MiddlewareFuncs) BeforeSend(req *http.Request) error {
+	if m.Before == nil {
+		return nil
+	}
+	return m.Before(req)
+}
+
+// AfterReceive implements Middleware.
+func (m MiddlewareFuncs) AfterReceive(req *http.Request, resp *http.Response, body []byte, err error) error {
+	if m.After == nil {
+		return nil
+	}
+	return m.After(req, resp, body, err)
+}
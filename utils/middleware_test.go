@@ -0,0 +1,114 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// countingTransport counts round trips before delegating to the default transport.
+type countingTransport struct {
+	calls int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Trace")))
+	}))
+	defer server.Close()
+
+	var order []string
+	record := func(name string) Middleware {
+		return MiddlewareFuncs{
+			Before: func(req *http.Request) error {
+				order = append(order, "before "+name)
+				req.Header.Set("X-Trace", req.Header.Get("X-Trace")+name)
+				return nil
+			},
+			After: func(req *http.Request, resp *http.Response, body []byte, err error) error {
+				order = append(order, "after "+name)
+				return nil
+			},
+		}
+	}
+
+	transport := &countingTransport{}
+	client := NewBaseHTTPClient(server.URL, 5, false,
+		WithTransport(transport),
+		WithMiddleware(record("a"), record("b")),
+	)
+
+	body, err := client.Get("/", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if body != "ab" {
+		t.Errorf("expected headers set by both middlewares, got %q", body)
+	}
+	if transport.calls != 1 {
+		t.Errorf("expected custom transport to be used once, got %d", transport.calls)
+	}
+
+	expected := []string{"before a", "before b", "after b", "after a"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	errAbort := errors.New("abort")
+	aborting := NewBaseHTTPClient(server.URL, 5, false, WithMiddleware(MiddlewareFuncs{
+		Before: func(req *http.Request) error { return errAbort },
+	}))
+	if _, err := aborting.Get("/", nil); !errors.Is(err, errAbort) {
+		t.Fatalf("expected BeforeSend error, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("request should not have been sent, got %d calls", calls)
+	}
+
+	var seenStatus int
+	observing := NewBaseHTTPClient(server.URL, 5, false, WithMiddleware(MiddlewareFuncs{
+		After: func(req *http.Request, resp *http.Response, body []byte, err error) error {
+			if resp != nil {
+				seenStatus = resp.StatusCode
+			}
+			return nil
+		},
+	}))
+	if _, err := observing.Get("/", nil); err == nil {
+		t.Fatal("expected status error")
+	}
+	if seenStatus != http.StatusInternalServerError {
+		t.Errorf("expected AfterReceive to see status 500, got %d", seenStatus)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	client := NewBaseHTTPClient(server.URL, 5, false, WithHTTPClient(&http.Client{Transport: transport}))
+	if _, err := client.Post("/", map[string]interface{}{"k": "v"}); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if transport.calls != 1 {
+		t.Errorf("expected injected client to be used, got %d calls", transport.calls)
+	}
+}
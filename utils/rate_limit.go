@@ -0,0 +1,250 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+// DefaultRateLimitBackoff is how long a bucket is paused after the server
+// throttled a call without telling the client when to retry.
+const DefaultRateLimitBackoff = time.Second
+
+// RateLimit configures one token bucket.
+type RateLimit struct {
+	// Rate is the sustained number of calls per second. Zero means unlimited.
+	Rate float64
+
+	// Burst is the number of calls that may be made at once (default: 1).
+	Burst int
+}
+
+// burst returns the bucket capacity of the limit.
+func (l RateLimit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+	return float64(l.Burst)
+}
+
+// RateLimiter is a client-side token-bucket limiter. Every app ID and endpoint
+// group gets its own bucket, so clients sharing one RateLimiter and the same
+// credentials also share their budget. The limiter is safe for concurrent use.
+//
+// Calls wait for a token unless the context does not allow it: when the wait
+// would outlast the context deadline, or the call was made with
+// WithCallNoWait, a *sdkerrors.RateLimitError is returned immediately.
+// Rate-limit responses from the server pause the bucket of the call.
+type RateLimiter struct {
+	mu           sync.Mutex
+	defaultLimit RateLimit
+	groupLimits  map[EndpointGroup]RateLimit
+	appLimits    map[string]map[EndpointGroup]RateLimit
+	buckets      map[rateLimitKey]*tokenBucket
+}
+
+// rateLimitKey identifies a bucket.
+type rateLimitKey struct {
+	appID string
+	group EndpointGroup
+}
+
+// tokenBucket holds the state of one bucket.
+type tokenBucket struct {
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// NewRateLimiter creates a RateLimiter applying defaultLimit to every endpoint
+// group that has no limit of its own.
+func NewRateLimiter(defaultLimit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		defaultLimit: defaultLimit,
+		groupLimits:  make(map[EndpointGroup]RateLimit),
+		appLimits:    make(map[string]map[EndpointGroup]RateLimit),
+		buckets:      make(map[rateLimitKey]*tokenBucket),
+	}
+}
+
+// SetGroupLimit sets the limit of an endpoint group for every app ID.
+func (l *RateLimiter) SetGroupLimit(group EndpointGroup, limit RateLimit) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.groupLimits[group] = limit
+	return l
+}
+
+// SetAppLimit sets the limit of an endpoint group for one app ID, taking
+// precedence over the group limit.
+func (l *RateLimiter) SetAppLimit(appID string, group EndpointGroup, limit RateLimit) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.appLimits[appID] == nil {
+		l.appLimits[appID] = make(map[EndpointGroup]RateLimit)
+	}
+	l.appLimits[appID][group] = limit
+	return l
+}
+
+// Wait blocks until a call of appID in group may be sent. It fails fast with a
+// *sdkerrors.RateLimitError when ctx does not allow waiting, and returns the
+// context error when ctx is done while waiting. A nil RateLimiter never waits.
+func (l *RateLimiter) Wait(ctx context.Context, appID string, group EndpointGroup) error {
+	if l == nil {
+		return nil
+	}
+
+	key := rateLimitKey{appID: appID, group: group}
+	wait, reserved := l.reserve(key, time.Now())
+	if wait <= 0 {
+		return nil
+	}
+
+	if !canWait(ctx, wait) {
+		l.release(key, reserved)
+		return &sdkerrors.RateLimitError{AppID: appID, Group: string(group), Wait: wait}
+	}
+
+	if err := sleepContext(ctx, wait); err != nil {
+		l.release(key, reserved)
+		return err
+	}
+	return nil
+}
+
+// Throttle pauses the bucket of appID and group for d, e.g. after the server
+// answered with a rate-limit response. A d of zero uses DefaultRateLimitBackoff.
+func (l *RateLimiter) Throttle(appID string, group EndpointGroup, d time.Duration) {
+	if l == nil {
+		return
+	}
+	if d <= 0 {
+		d = DefaultRateLimitBackoff
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket := l.bucket(rateLimitKey{appID: appID, group: group})
+	if until := time.Now().Add(d); until.After(bucket.blockedUntil) {
+		bucket.blockedUntil = until
+	}
+	bucket.tokens = math.Min(bucket.tokens, 0)
+}
+
+// RateLimitState is a snapshot of one bucket of a RateLimiter.
+type RateLimitState struct {
+	AppID string
+	Group EndpointGroup
+
+	// Tokens is the number of calls that may be sent right away. It is negative
+	// when calls are queued waiting for tokens.
+	Tokens float64
+
+	// BlockedFor is how long the bucket stays paused after a rate-limit response.
+	BlockedFor time.Duration
+}
+
+// Snapshot returns the state of every bucket in use.
+func (l *RateLimiter) Snapshot() []RateLimitState {
+	if l == nil {
+		return nil
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	states := make([]RateLimitState, 0, len(l.buckets))
+	for key, bucket := range l.buckets {
+		limit := l.limit(key)
+		tokens := bucket.tokens
+		if limit.Rate > 0 {
+			tokens = math.Min(limit.burst(), tokens+now.Sub(bucket.last).Seconds()*limit.Rate)
+		} else {
+			tokens = math.Inf(1)
+		}
+		state := RateLimitState{AppID: key.appID, Group: key.group, Tokens: tokens}
+		if blocked := bucket.blockedUntil.Sub(now); blocked > 0 {
+			state.BlockedFor = blocked
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// reserve takes a token from the bucket and returns how long the caller must
+// wait before using it, and whether a token was taken.
+func (l *RateLimiter) reserve(key rateLimitKey, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.limit(key)
+	bucket := l.bucket(key)
+
+	var wait time.Duration
+	if limit.Rate > 0 {
+		bucket.refill(now, limit)
+		bucket.tokens--
+		if bucket.tokens < 0 {
+			wait = time.Duration(-bucket.tokens / limit.Rate * float64(time.Second))
+		}
+	}
+	if until := bucket.blockedUntil.Sub(now); until > wait {
+		wait = until
+	}
+	return wait, limit.Rate > 0
+}
+
+// release returns a token taken by reserve that was not used.
+func (l *RateLimiter) release(key rateLimitKey, reserved bool) {
+	if !reserved {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bucket(key).tokens++
+}
+
+// limit returns the limit of a bucket. l.mu must be held.
+func (l *RateLimiter) limit(key rateLimitKey) RateLimit {
+	if limit, ok := l.appLimits[key.appID][key.group]; ok {
+		return limit
+	}
+	if limit, ok := l.groupLimits[key.group]; ok {
+		return limit
+	}
+	return l.defaultLimit
+}
+
+// bucket returns the bucket of key, creating it full. l.mu must be held.
+func (l *RateLimiter) bucket(key rateLimitKey) *tokenBucket {
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.limit(key).burst(), last: time.Now()}
+		l.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// refill adds the tokens earned since the last call, up to the burst size.
+func (b *tokenBucket) refill(now time.Time, limit RateLimit) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(limit.burst(), b.tokens+elapsed.Seconds()*limit.Rate)
+		b.last = now
+	}
+}
+
+// canWait reports whether a call bound to ctx may wait d for a token.
+func canWait(ctx context.Context, d time.Duration) bool {
+	if CallOptionsFromContext(ctx).NoWait {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	return true
+}
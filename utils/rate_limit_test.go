@@ -0,0 +1,101 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{}).
+		SetGroupLimit(GroupMoneyMoving, RateLimit{Rate: 20, Burst: 2}).
+		SetAppLimit("vip", GroupMoneyMoving, RateLimit{Rate: 1000, Burst: 100})
+	noWait := WithCallOptions(context.Background(), WithCallNoWait())
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(noWait, "app", GroupMoneyMoving); err != nil {
+			t.Fatalf("Wait() within burst error = %v", err)
+		}
+	}
+
+	err := limiter.Wait(noWait, "app", GroupMoneyMoving)
+	var limitErr *sdkerrors.RateLimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, sdkerrors.ErrRateLimited) {
+		t.Fatalf("Wait() error = %v, want *sdkerrors.RateLimitError", err)
+	}
+	if limitErr.AppID != "app" || limitErr.Group != string(GroupMoneyMoving) || limitErr.Wait <= 0 {
+		t.Fatalf("RateLimitError = %+v", limitErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, "app", GroupMoneyMoving); !errors.As(err, &limitErr) {
+		t.Fatalf("Wait() past deadline error = %v, want fail fast", err)
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background(), "app", GroupMoneyMoving); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("Wait() returned after %s, want it to wait for a token", elapsed)
+	}
+
+	// Other app IDs and groups have their own buckets.
+	if err := limiter.Wait(noWait, "vip", GroupMoneyMoving); err != nil {
+		t.Fatalf("Wait() for another app error = %v", err)
+	}
+	if err := limiter.Wait(noWait, "app", GroupSync); err != nil {
+		t.Fatalf("Wait() for an unlimited group error = %v", err)
+	}
+}
+
+func TestRateLimiterThrottle(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{})
+	noWait := WithCallOptions(context.Background(), WithCallNoWait())
+
+	limiter.Throttle("app", GroupSync, 50*time.Millisecond)
+	if err := limiter.Wait(noWait, "app", GroupSync); !errors.Is(err, sdkerrors.ErrRateLimited) {
+		t.Fatalf("Wait() after Throttle error = %v, want ErrRateLimited", err)
+	}
+	if err := limiter.Wait(context.Background(), "app", GroupSync); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if err := limiter.Wait(noWait, "app", GroupSync); err != nil {
+		t.Fatalf("Wait() after the pause error = %v", err)
+	}
+
+	var nilLimiter *RateLimiter
+	if err := nilLimiter.Wait(noWait, "app", GroupSync); err != nil {
+		t.Fatalf("nil limiter Wait() error = %v", err)
+	}
+}
+
+func TestRateLimitResponseFeedback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimit{})
+	client := NewMpcHTTPClient(server.URL, "app", "key", DefaultTimeout, false, WithRateLimiter(limiter))
+	ctx := WithCallInfo(context.Background(), CallInfo{Idempotent: true, Group: GroupSync})
+
+	var statusErr *sdkerrors.HTTPStatusError
+	if _, err := client.PostContext(ctx, "/test", nil); !errors.As(err, &statusErr) {
+		t.Fatalf("PostContext() error = %v, want *sdkerrors.HTTPStatusError", err)
+	}
+
+	var limitErr *sdkerrors.RateLimitError
+	_, err := client.PostContext(WithCallOptions(ctx, WithCallNoWait()), "/test", nil)
+	if !errors.As(err, &limitErr) || limitErr.Wait < 500*time.Millisecond {
+		t.Fatalf("PostContext() after 429 error = %v, want the bucket paused for Retry-After", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RawResponse is the decrypted response of a raw API call: the full
+// {code, msg, data} document returned by the server.
+type RawResponse struct {
+	// Body is the decrypted JSON document.
+	Body json.RawMessage
+}
+
+// Decode decodes the whole response document into v.
+// Numbers are decoded losslessly, as in DecodeJSON.
+func (r *RawResponse) Decode(v interface{}) error {
+	return DecodeJSON(r.Body, v)
+}
+
+// DecodeData decodes only the "data" field of the response into v.
+func (r *RawResponse) DecodeData(v interface{}) error {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(r.Body, &envelope); err != nil {
+		return err
+	}
+	if len(envelope.Data) == 0 {
+		return fmt.Errorf("response has no data field")
+	}
+	return DecodeJSON(envelope.Data, v)
+}
+
+// DecodeRaw decodes the whole response document of a raw call into a new T.
+//
+//	resp, err := client.RawCall(ctx, utils.HTTPMethodPost, "/billing/newEndpoint", params)
+//	result, err := utils.DecodeRaw[MyResult](resp)
+func DecodeRaw[T any](r *RawResponse) (*T, error) {
+	var result T
+	if err := r.Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
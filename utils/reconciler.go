@@ -0,0 +1,463 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sources of a ReconcileEvent.
+const (
+	// SourceWebhook marks a status first seen in a webhook notification.
+	SourceWebhook = "webhook"
+
+	// SourcePoll marks a status first seen by polling, i.e. one whose webhook
+	// was lost or has not arrived yet.
+	SourcePoll = "poll"
+)
+
+// DefaultReconcileRetention is how long a Reconciler remembers records that
+// reached a final status.
+const DefaultReconcileRetention = 24 * time.Hour
+
+// Observation is one sighting of the status of a record, from a webhook or
+// from polling.
+type Observation struct {
+	// Kind is the record type, e.g. "deposit" or "withdraw".
+	Kind      string
+	ID        int64
+	RequestID string
+	Status    int64
+	// UpdatedAt orders the sightings of a record; older ones are dropped.
+	UpdatedAt time.Time
+	// Record is the notification or record as received, e.g. *types.NotifyData
+	// or *types.DepositRecord of package mpc/types.
+	Record interface{}
+}
+
+// ReconcileEvent is a status change emitted by a Reconciler.
+type ReconcileEvent struct {
+	Observation
+
+	// Seq numbers the events of a Reconciler from 1.
+	Seq uint64
+
+	// Source is SourceWebhook or SourcePoll.
+	Source string
+
+	// PreviousStatus is the status of the previous event of the record, and
+	// New reports that there was none.
+	PreviousStatus int64
+	New            bool
+}
+
+// ReconcileSource polls one kind of record for a Reconciler. Create sources
+// with NewReconcileSource or the New*ReconcileSource helpers of the
+// custody/api and mpc/api packages.
+type ReconcileSource interface {
+	// Kind is the record type the source polls, matching Observation.Kind.
+	Kind() string
+
+	// Sync returns the page of records after maxID.
+	Sync(ctx context.Context, maxID int64) ([]Observation, error)
+
+	// Lookup returns the current state of records that are not final yet.
+	Lookup(ctx context.Context, open []Observation) ([]Observation, error)
+
+	// Final reports whether status is final, so the record needs no more lookups.
+	Final(status int64) bool
+}
+
+// NewReconcileSource creates a ReconcileSource of kind that syncs new records
+// with fetch, looks up open records with lookup and converts records with
+// observe. Records with one of the final statuses are no longer looked up.
+func NewReconcileSource[T any](kind string, fetch PageFunc[T], lookup func(ctx context.Context, open []Observation) ([]T, error), observe func(T) Observation, final ...int64) ReconcileSource {
+	finals := make(map[int64]bool, len(final))
+	for _, status := range final {
+		finals[status] = true
+	}
+	return &reconcileSource[T]{kind: kind, fetch: fetch, lookup: lookup, observe: observe, final: finals}
+}
+
+type reconcileSource[T any] struct {
+	kind    string
+	fetch   PageFunc[T]
+	lookup  func(ctx context.Context, open []Observation) ([]T, error)
+	observe func(T) Observation
+	final   map[int64]bool
+}
+
+func (s *reconcileSource[T]) Kind() string {
+	return s.kind
+}
+
+func (s *reconcileSource[T]) Sync(ctx context.Context, maxID int64) ([]Observation, error) {
+	records, err := s.fetch(ctx, maxID)
+	if err != nil {
+		return nil, err
+	}
+	return s.observeAll(records), nil
+}
+
+func (s *reconcileSource[T]) Lookup(ctx context.Context, open []Observation) ([]Observation, error) {
+	records, err := s.lookup(ctx, open)
+	if err != nil {
+		return nil, err
+	}
+	return s.observeAll(records), nil
+}
+
+func (s *reconcileSource[T]) Final(status int64) bool {
+	return s.final[status]
+}
+
+func (s *reconcileSource[T]) observeAll(records []T) []Observation {
+	observations := make([]Observation, len(records))
+	for i, record := range records {
+		observations[i] = s.observe(record)
+	}
+	return observations
+}
+
+// ReconcileSettings configures a Reconciler.
+type ReconcileSettings struct {
+	// Interval is the pause between two polls (default: DefaultSyncInterval).
+	Interval time.Duration
+
+	// Jitter is the fraction of Interval that is randomized, between 0 and 1.
+	Jitter float64
+
+	// Buffer is the capacity of the event channel (default: 100).
+	Buffer int
+
+	// Retention is how long records are remembered after they reached a final
+	// status (default: DefaultReconcileRetention). Records of a kind without a
+	// source never reach one and are forgotten Retention after their last
+	// event. A webhook arriving later for a forgotten record is emitted again.
+	Retention time.Duration
+
+	// Store saves the sync position of each source under the name
+	// "reconcile.<kind>", so a restarted Reconciler resumes polling where it
+	// stopped instead of emitting the history again (optional; positions are
+	// kept in memory without it). The saved position never passes a record
+	// that is not final yet: a restarted Reconciler syncs again from the
+	// lowest open record, so it keeps looking that record up, and emits the
+	// records after it again. Positions are saved in batches like the
+	// checkpoints of a Syncer.
+	Store CheckpointStore
+}
+
+// Reconciler merges webhook notifications with polling into one ordered
+// stream of status changes. Webhooks are fed in with Observe; polling syncs
+// new records from each source and looks up every record that is not final
+// yet, so a lost notification still shows up as an event with Source
+// SourcePoll. Each status of a record is emitted once, whichever path saw it
+// first, and sightings older than the last emitted one are dropped:
+//
+//	store := utils.NewFileCheckpointStore("reconcile.json")
+//	reconciler := utils.NewReconciler(utils.ReconcileSettings{Interval: time.Minute, Store: store}).
+//		AddSource(api.NewDepositReconcileSource(client.GetDepositAPI()))
+//	go reconciler.Run(ctx)
+//	// in the webhook handler
+//	err = reconciler.Observe(r.Context(), api.NotifyObservation(data))
+//	// in the consumer
+//	for event := range reconciler.Events() {
+//		// ...
+//	}
+//
+// Events are sent while holding the Reconciler's lock, so a slow consumer
+// holds up webhooks and polling alike.
+type Reconciler struct {
+	settings ReconcileSettings
+	sources  []ReconcileSource
+	byKind   map[string]ReconcileSource
+	onError  func(kind string, err error)
+	events   chan ReconcileEvent
+
+	mu          sync.Mutex
+	seq         uint64
+	cursors     map[string]int64
+	checkpoints map[string]*checkpointer
+	records     map[reconcileKey]*reconcileState
+}
+
+// reconcileKey identifies a record.
+type reconcileKey struct {
+	kind string
+	id   int64
+}
+
+// reconcileState is the last emitted state of a record.
+type reconcileState struct {
+	last      Observation
+	emittedAt time.Time
+	final     bool
+	finalAt   time.Time
+}
+
+// NewReconciler creates a Reconciler without sources.
+func NewReconciler(settings ReconcileSettings) *Reconciler {
+	if settings.Interval <= 0 {
+		settings.Interval = DefaultSyncInterval
+	}
+	if settings.Buffer <= 0 {
+		settings.Buffer = 100
+	}
+	if settings.Retention <= 0 {
+		settings.Retention = DefaultReconcileRetention
+	}
+	return &Reconciler{
+		settings:    settings,
+		byKind:      make(map[string]ReconcileSource),
+		events:      make(chan ReconcileEvent, settings.Buffer),
+		cursors:     make(map[string]int64),
+		checkpoints: make(map[string]*checkpointer),
+		records:     make(map[reconcileKey]*reconcileState),
+	}
+}
+
+// AddSource adds sources to poll, one per kind.
+func (r *Reconciler) AddSource(sources ...ReconcileSource) *Reconciler {
+	for _, source := range sources {
+		r.sources = append(r.sources, source)
+		r.byKind[source.Kind()] = source
+	}
+	return r
+}
+
+// SetStartID makes polling of kind start after maxID instead of at the first
+// record, e.g. to skip history that was already processed. A position saved
+// in the Store takes precedence.
+func (r *Reconciler) SetStartID(kind string, maxID int64) *Reconciler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cursors[kind] = maxID
+	return r
+}
+
+// OnError sets the callback receiving the poll errors of Run.
+func (r *Reconciler) OnError(fn func(kind string, err error)) *Reconciler {
+	r.onError = fn
+	return r
+}
+
+// Events returns the channel the status changes are emitted on. It is never closed.
+func (r *Reconciler) Events() <-chan ReconcileEvent {
+	return r.events
+}
+
+// Observe feeds in a webhook notification. It returns once the notification
+// was emitted or found to be a duplicate, or with ctx.Err() when the event
+// could not be queued in time.
+func (r *Reconciler) Observe(ctx context.Context, obs Observation) error {
+	return r.observe(ctx, obs, SourceWebhook)
+}
+
+// Poll syncs the new records of every source and looks up the records that
+// are not final yet. It returns the joined errors of the sources.
+func (r *Reconciler) Poll(ctx context.Context) error {
+	var errs []error
+	r.poll(ctx, func(kind string, err error) {
+		errs = append(errs, fmt.Errorf("reconcile %s: %w", kind, err))
+	})
+	return errors.Join(errs...)
+}
+
+// Run polls every interval until ctx is canceled and returns ctx.Err(). Poll
+// errors are passed to the OnError callback and retried on the next poll.
+func (r *Reconciler) Run(ctx context.Context) error {
+	for {
+		r.poll(ctx, func(kind string, err error) {
+			if ctx.Err() == nil && r.onError != nil {
+				r.onError(kind, err)
+			}
+		})
+
+		timer := time.NewTimer(jitterInterval(r.settings.Interval, r.settings.Jitter))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// poll polls every source, passing their errors to report.
+func (r *Reconciler) poll(ctx context.Context, report func(kind string, err error)) {
+	for _, source := range r.sources {
+		if err := r.pollSource(ctx, source); err != nil {
+			report(source.Kind(), err)
+		}
+	}
+	r.prune()
+}
+
+func (r *Reconciler) pollSource(ctx context.Context, source ReconcileSource) error {
+	kind := source.Kind()
+	if open := r.open(kind); len(open) > 0 {
+		current, err := source.Lookup(ctx, open)
+		if err != nil {
+			return fmt.Errorf("lookup: %w", err)
+		}
+		for _, obs := range current {
+			if err := r.observe(ctx, obs, SourcePoll); err != nil {
+				return err
+			}
+		}
+	}
+
+	start, checkpoint, err := r.start(ctx, kind)
+	if err != nil {
+		return err
+	}
+	cursor := NewCursor(ctx, source.Sync, func(obs Observation) int64 { return obs.ID }, WithStartID(start))
+	for cursor.Next() {
+		if err = r.observe(ctx, cursor.Item(), SourcePoll); err != nil {
+			break
+		}
+		r.mu.Lock()
+		r.cursors[kind] = cursor.MaxID()
+		r.mu.Unlock()
+		if checkpoint != nil {
+			if err = checkpoint.commit(ctx, r.resumeID(kind)); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		if err = cursor.Err(); err != nil {
+			err = fmt.Errorf("sync: %w", err)
+		}
+	}
+	if checkpoint != nil {
+		// Records closed by the lookup move the position without a new record.
+		if resume := r.resumeID(kind); err == nil && resume != checkpoint.maxID {
+			err = checkpoint.commit(ctx, resume)
+		}
+		// Save the records emitted since the last save, even when ctx was canceled.
+		if flushErr := checkpoint.flush(context.WithoutCancel(ctx)); flushErr != nil {
+			return errors.Join(err, flushErr)
+		}
+	}
+	return err
+}
+
+// start returns the position polling of kind resumes from and the
+// checkpointer saving it, loading the position from the Store on the first
+// poll. The checkpointer is nil without a Store.
+func (r *Reconciler) start(ctx context.Context, kind string) (int64, *checkpointer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.settings.Store == nil {
+		return r.cursors[kind], nil, nil
+	}
+	checkpoint, ok := r.checkpoints[kind]
+	if !ok {
+		saved, err := r.settings.Store.Load(ctx, reconcileFeed(kind))
+		if err != nil {
+			return 0, nil, fmt.Errorf("load checkpoint: %w", err)
+		}
+		if saved > 0 {
+			r.cursors[kind] = saved
+		}
+		checkpoint = newCheckpointer(r.settings.Store, reconcileFeed(kind), DefaultCheckpointEvery, DefaultCheckpointInterval)
+		checkpoint.maxID = r.cursors[kind]
+		r.checkpoints[kind] = checkpoint
+	}
+	return r.cursors[kind], checkpoint, nil
+}
+
+// resumeID returns the position a restarted Reconciler resumes polling kind
+// from: the sync position, or the one before the lowest record of kind that
+// is not final yet, so that record is synced and looked up again.
+func (r *Reconciler) resumeID(kind string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resume := r.cursors[kind]
+	for key, state := range r.records {
+		if key.kind == kind && !state.final && key.id <= resume {
+			resume = key.id - 1
+		}
+	}
+	return resume
+}
+
+// reconcileFeed is the Store name of the sync position of kind.
+func reconcileFeed(kind string) string {
+	return "reconcile." + kind
+}
+
+// open returns the last observations of the records of kind that are not final.
+func (r *Reconciler) open(kind string) []Observation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var open []Observation
+	for key, state := range r.records {
+		if key.kind == kind && !state.final {
+			open = append(open, state.last)
+		}
+	}
+	return open
+}
+
+// observe emits obs unless its record already had this status or a newer sighting.
+func (r *Reconciler) observe(ctx context.Context, obs Observation, source string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := reconcileKey{kind: obs.Kind, id: obs.ID}
+	state, known := r.records[key]
+	event := ReconcileEvent{Observation: obs, Source: source, New: !known}
+	if known {
+		if obs.Status == state.last.Status || obs.UpdatedAt.Before(state.last.UpdatedAt) {
+			return nil
+		}
+		event.PreviousStatus = state.last.Status
+	}
+
+	event.Seq = r.seq + 1
+	select {
+	case r.events <- event:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	r.seq++
+
+	if !known {
+		state = &reconcileState{}
+		r.records[key] = state
+	}
+	if obs.RequestID == "" {
+		// Webhooks of some kinds carry no request_id; keep the one polled.
+		obs.RequestID = state.last.RequestID
+	}
+	state.last = obs
+	state.emittedAt = time.Now()
+	kindSource, ok := r.byKind[obs.Kind]
+	state.final = ok && kindSource.Final(obs.Status)
+	if state.final {
+		state.finalAt = time.Now()
+	}
+	return nil
+}
+
+// prune forgets the records that have been final for longer than Retention,
+// and the records of kinds without a source, which are never looked up,
+// Retention after their last event.
+func (r *Reconciler) prune() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, state := range r.records {
+		_, polled := r.byKind[key.kind]
+		switch {
+		case state.final && time.Since(state.finalAt) > r.settings.Retention:
+			delete(r.records, key)
+		case !polled && time.Since(state.emittedAt) > r.settings.Retention:
+			delete(r.records, key)
+		}
+	}
+}
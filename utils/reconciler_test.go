@@ -0,0 +1,282 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fakeLedger is a record store the reconcile tests poll, keyed by ID.
+type fakeLedger struct {
+	records map[int64]Observation
+	lookups [][]int64
+	fail    bool
+}
+
+func (l *fakeLedger) source() ReconcileSource {
+	fetch := func(ctx context.Context, maxID int64) ([]Observation, error) {
+		if l.fail {
+			return nil, errors.New("ledger unavailable")
+		}
+		var page []Observation
+		for id := maxID + 1; id <= maxID+2; id++ {
+			if obs, ok := l.records[id]; ok {
+				page = append(page, obs)
+			}
+		}
+		return page, nil
+	}
+	lookup := func(ctx context.Context, open []Observation) ([]Observation, error) {
+		var ids []int64
+		var found []Observation
+		for _, obs := range open {
+			ids = append(ids, obs.ID)
+			found = append(found, l.records[obs.ID])
+		}
+		l.lookups = append(l.lookups, ids)
+		return found, nil
+	}
+	identity := func(obs Observation) Observation { return obs }
+	return NewReconcileSource("deposit", fetch, lookup, identity, 2000, 2400)
+}
+
+func (l *fakeLedger) set(id, status int64, minute int) {
+	l.records[id] = deposit(id, status, minute)
+}
+
+func deposit(id, status int64, minute int) Observation {
+	return Observation{Kind: "deposit", ID: id, Status: status, UpdatedAt: time.Unix(int64(minute)*60, 0)}
+}
+
+// drain returns the events emitted so far as "seq:id:status:source" strings.
+func drain(r *Reconciler) []string {
+	var events []string
+	for {
+		select {
+		case e := <-r.Events():
+			events = append(events, fmt.Sprintf("%d:%d:%d:%s", e.Seq, e.ID, e.Status, e.Source))
+		default:
+			return events
+		}
+	}
+}
+
+func TestReconciler(t *testing.T) {
+	ctx := context.Background()
+	ledger := &fakeLedger{records: map[int64]Observation{}}
+	reconciler := NewReconciler(ReconcileSettings{}).AddSource(ledger.source())
+
+	tests := []struct {
+		name        string
+		step        func() error
+		wantEvents  []string
+		wantLookups [][]int64
+	}{
+		{
+			name: "webhook before poll",
+			step: func() error {
+				ledger.set(1, 1000, 1)
+				return reconciler.Observe(ctx, deposit(1, 1000, 1))
+			},
+			wantEvents: []string{"1:1:1000:webhook"},
+		},
+		{
+			name: "poll finds known and new records",
+			step: func() error {
+				ledger.set(2, 1000, 2)
+				return reconciler.Poll(ctx)
+			},
+			wantEvents:  []string{"2:2:1000:poll"},
+			wantLookups: [][]int64{{1}},
+		},
+		{
+			name: "lost webhook detected by lookup",
+			step: func() error {
+				ledger.set(1, 2000, 3)
+				return reconciler.Poll(ctx)
+			},
+			wantEvents:  []string{"3:1:2000:poll"},
+			wantLookups: [][]int64{{1}, {1, 2}},
+		},
+		{
+			name: "late webhook of emitted status is a duplicate",
+			step: func() error {
+				return reconciler.Observe(ctx, deposit(1, 2000, 3))
+			},
+			wantLookups: [][]int64{{1}, {1, 2}},
+		},
+		{
+			name: "stale webhook is dropped",
+			step: func() error {
+				return reconciler.Observe(ctx, deposit(2, 1900, 1))
+			},
+			wantLookups: [][]int64{{1}, {1, 2}},
+		},
+		{
+			name: "final records are not looked up",
+			step: func() error {
+				ledger.set(2, 2400, 4)
+				ledger.set(3, 1000, 4)
+				if err := reconciler.Observe(ctx, deposit(2, 2400, 4)); err != nil {
+					return err
+				}
+				return reconciler.Poll(ctx)
+			},
+			wantEvents:  []string{"4:2:2400:webhook", "5:3:1000:poll"},
+			wantLookups: [][]int64{{1}, {1, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.step(); err != nil {
+				t.Fatalf("step error = %v", err)
+			}
+			events := drain(reconciler)
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Fatalf("events = %v, want %v", events, tt.wantEvents)
+			}
+			for _, ids := range ledger.lookups {
+				sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			}
+			if !reflect.DeepEqual(ledger.lookups, tt.wantLookups) {
+				t.Fatalf("lookups = %v, want %v", ledger.lookups, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestReconcilerPollError(t *testing.T) {
+	ctx := context.Background()
+	ledger := &fakeLedger{records: map[int64]Observation{}, fail: true}
+	ledger.set(1, 1000, 1)
+	reconciler := NewReconciler(ReconcileSettings{}).AddSource(ledger.source()).SetStartID("deposit", 0)
+
+	if err := reconciler.Poll(ctx); err == nil {
+		t.Fatalf("Poll() error = nil, want error")
+	}
+	ledger.fail = false
+	if err := reconciler.Poll(ctx); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if events := drain(reconciler); !reflect.DeepEqual(events, []string{"1:1:1000:poll"}) {
+		t.Fatalf("events = %v, want the record missed by the failed poll", events)
+	}
+}
+
+func TestReconcilerObserveCanceled(t *testing.T) {
+	reconciler := NewReconciler(ReconcileSettings{Buffer: 1})
+	if err := reconciler.Observe(context.Background(), deposit(1, 1000, 1)); err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := reconciler.Observe(ctx, deposit(2, 1000, 1)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Observe() on a full buffer error = %v, want context.Canceled", err)
+	}
+	// The rejected webhook is not recorded, so its redelivery is emitted.
+	<-reconciler.Events()
+	if err := reconciler.Observe(context.Background(), deposit(2, 1000, 1)); err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	if events := drain(reconciler); !reflect.DeepEqual(events, []string{"2:2:1000:webhook"}) {
+		t.Fatalf("events = %v", events)
+	}
+}
+
+func TestReconcilerStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+	ledger := &fakeLedger{records: map[int64]Observation{}}
+	ledger.set(1, 2000, 1)
+	ledger.set(2, 2000, 1)
+	ledger.set(3, 2000, 1)
+
+	first := NewReconciler(ReconcileSettings{Store: store}).AddSource(ledger.source())
+	if err := first.Poll(ctx); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if events := drain(first); len(events) != 3 {
+		t.Fatalf("events = %v, want the 3 records", events)
+	}
+	if maxID, _ := store.Load(ctx, "reconcile.deposit"); maxID != 3 {
+		t.Fatalf("saved position = %d, want 3", maxID)
+	}
+
+	// A restarted reconciler resumes from the saved position, ahead of SetStartID.
+	ledger.set(4, 1000, 2)
+	restarted := NewReconciler(ReconcileSettings{Store: store}).AddSource(ledger.source()).SetStartID("deposit", 1)
+	if err := restarted.Poll(ctx); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if events := drain(restarted); !reflect.DeepEqual(events, []string{"1:4:1000:poll"}) {
+		t.Fatalf("events after restart = %v, want only the new record", events)
+	}
+}
+
+func TestReconcilerStoreKeepsOpenRecords(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+	ledger := &fakeLedger{records: map[int64]Observation{}}
+	ledger.set(1, 2000, 1)
+	ledger.set(2, 1000, 1)
+	ledger.set(3, 2000, 1)
+
+	poll := func(r *Reconciler, wantEvents []string, wantSaved int64) {
+		t.Helper()
+		if err := r.Poll(ctx); err != nil {
+			t.Fatalf("Poll() error = %v", err)
+		}
+		if events := drain(r); !reflect.DeepEqual(events, wantEvents) {
+			t.Fatalf("events = %v, want %v", events, wantEvents)
+		}
+		if saved, _ := store.Load(ctx, "reconcile.deposit"); saved != wantSaved {
+			t.Fatalf("saved position = %d, want %d", saved, wantSaved)
+		}
+	}
+
+	// The pending record 2 holds the saved position back.
+	first := NewReconciler(ReconcileSettings{Store: store}).AddSource(ledger.source())
+	poll(first, []string{"1:1:2000:poll", "2:2:1000:poll", "3:3:2000:poll"}, 1)
+
+	// After a restart, record 2 is synced again and its change is emitted.
+	ledger.set(2, 2000, 2)
+	restarted := NewReconciler(ReconcileSettings{Store: store}).AddSource(ledger.source())
+	poll(restarted, []string{"1:2:2000:poll", "2:3:2000:poll"}, 3)
+
+	// A lookup closing the last open record moves the position on.
+	ledger.set(4, 1000, 3)
+	poll(restarted, []string{"3:4:1000:poll"}, 3)
+	ledger.set(4, 2000, 4)
+	poll(restarted, []string{"4:4:2000:poll"}, 4)
+}
+
+func TestReconcilerPrunesKindsWithoutSource(t *testing.T) {
+	ctx := context.Background()
+	ledger := &fakeLedger{records: map[int64]Observation{}}
+	reconciler := NewReconciler(ReconcileSettings{Retention: time.Millisecond}).AddSource(ledger.source())
+	collect := Observation{Kind: "collect", ID: 1, Status: 1000, UpdatedAt: time.Unix(60, 0)}
+	if err := reconciler.Observe(ctx, collect); err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	if err := reconciler.Observe(ctx, deposit(1, 1000, 1)); err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	ledger.set(1, 1000, 1)
+
+	time.Sleep(5 * time.Millisecond)
+	if err := reconciler.Poll(ctx); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if _, ok := reconciler.records[reconcileKey{kind: "collect", id: 1}]; ok {
+		t.Fatalf("open record of a kind without source was not pruned")
+	}
+	if _, ok := reconciler.records[reconcileKey{kind: "deposit", id: 1}]; !ok {
+		t.Fatalf("open record of a polled kind was pruned")
+	}
+}
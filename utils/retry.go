@@ -0,0 +1,142 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+// Default retry settings used by DefaultRetryPolicy.
+const (
+	// DefaultRetryMaxAttempts is the default number of attempts, including the first one.
+	DefaultRetryMaxAttempts = 3
+
+	// DefaultRetryInitialBackoff is the default delay before the first retry.
+	DefaultRetryInitialBackoff = 200 * time.Millisecond
+
+	// DefaultRetryMaxBackoff is the default upper bound for a single retry delay.
+	DefaultRetryMaxBackoff = 5 * time.Second
+)
+
+// RetryPolicy controls automatic retries of failed HTTP requests.
+// Transport errors, 5xx responses and HTTP 429 responses are retried with
+// exponential backoff and jitter, and so are calls answered with a rate-limit
+// response code, see BaseHTTPClient.RetryRateLimited. Requests marked as
+// non-idempotent through WithCallInfo are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor applied to the delay after each attempt (default: 2).
+	Multiplier float64
+
+	// Jitter is the fraction of the delay that is randomized, between 0 and 1.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns a retry policy with sensible defaults:
+// 3 attempts, 200ms initial backoff doubling up to 5s, with 20% jitter.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    DefaultRetryMaxAttempts,
+		InitialBackoff: DefaultRetryInitialBackoff,
+		MaxBackoff:     DefaultRetryMaxBackoff,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// attempts returns the number of attempts allowed by the policy.
+func (p *RetryPolicy) attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// Backoff returns the delay before the given retry (1 for the first retry).
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	if p == nil || retry < 1 {
+		return 0
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay = delay * (1 - jitter + 2*jitter*rand.Float64())
+	}
+
+	return time.Duration(delay)
+}
+
+// isRetryableStatus reports whether an HTTP status code is worth retrying.
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// isRetryableError reports whether a failed attempt may be retried.
+// Context cancellation and open circuits are never retried.
+func isRetryableError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, sdkerrors.ErrCircuitOpen) {
+		return false
+	}
+
+	var statusErr *sdkerrors.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return isRetryableStatus(statusErr.StatusCode)
+	}
+
+	// Anything else failed before a response was received.
+	return true
+}
+
+// retryAfter parses a Retry-After header expressed in seconds.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
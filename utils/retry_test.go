@@ -0,0 +1,209 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chainup.com/go-sdk/utils/sdkerrors"
+)
+
+// newFlakyServer returns a server that fails the first `failures` requests with status.
+func newFlakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte("unavailable"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":"0"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestRetry(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		failures  int32
+		ctx       func() context.Context
+		wantCalls int32
+		wantErr   bool
+	}{
+		{
+			name:      "Retries 5xx until success",
+			status:    http.StatusBadGateway,
+			failures:  2,
+			ctx:       context.Background,
+			wantCalls: 3,
+		},
+		{
+			name:      "Retries rate limit",
+			status:    http.StatusTooManyRequests,
+			failures:  1,
+			ctx:       context.Background,
+			wantCalls: 2,
+		},
+		{
+			name:      "Gives up after max attempts",
+			status:    http.StatusServiceUnavailable,
+			failures:  5,
+			ctx:       context.Background,
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "Does not retry client errors",
+			status:    http.StatusBadRequest,
+			failures:  1,
+			ctx:       context.Background,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:     "Does not retry money-moving call without request ID",
+			status:   http.StatusBadGateway,
+			failures: 1,
+			ctx: func() context.Context {
+				return WithIdempotencyKey(context.Background(), "")
+			},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:     "Retries money-moving call with request ID",
+			status:   http.StatusBadGateway,
+			failures: 1,
+			ctx: func() context.Context {
+				return WithIdempotencyKey(context.Background(), "req-1")
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, calls := newFlakyServer(t, tc.failures, tc.status)
+			client := NewBaseHTTPClient(server.URL, 5, false, WithRetryPolicy(testRetryPolicy()))
+
+			_, err := client.PostContext(tc.ctx(), "/test", map[string]interface{}{"a": 1})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+			if got := atomic.LoadInt32(calls); got != tc.wantCalls {
+				t.Errorf("expected %d calls, got %d", tc.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnContextCancel(t *testing.T) {
+	server, calls := newFlakyServer(t, 10, http.StatusBadGateway)
+	policy := &RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour}
+	client := NewBaseHTTPClient(server.URL, 5, false, WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.GetContext(ctx, "/test", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("expected 1 call before cancellation, got %d", got)
+	}
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ctx       context.Context
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "Retries rate-limit response code",
+			ctx:       context.Background(),
+			errs:      []error{sdkerrors.NewAPIError(sdkerrors.CodeRateLimited, "slow down"), nil},
+			wantCalls: 2,
+		},
+		{
+			name: "Gives up after max attempts",
+			ctx:  context.Background(),
+			errs: []error{
+				sdkerrors.NewAPIError(sdkerrors.CodeRateLimited, "slow down"),
+				sdkerrors.NewAPIError(sdkerrors.CodeRateLimited, "slow down"),
+				sdkerrors.NewAPIError(sdkerrors.CodeRateLimited, "slow down"),
+			},
+			wantCalls: 3,
+			wantErr:   true,
+		},
+		{
+			name:      "Does not retry other response codes",
+			ctx:       context.Background(),
+			errs:      []error{sdkerrors.NewAPIError("1001", "too many ids")},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "Does not retry client-side rate limits",
+			ctx:       context.Background(),
+			errs:      []error{&sdkerrors.RateLimitError{AppID: "app"}},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "Does not retry money-moving call without request ID",
+			ctx:       WithIdempotencyKey(context.Background(), ""),
+			errs:      []error{sdkerrors.NewAPIError(sdkerrors.CodeRateLimited, "slow down"), nil},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewBaseHTTPClient("http://127.0.0.1", 5, false, WithRetryPolicy(testRetryPolicy()))
+			calls := 0
+			err := client.RetryRateLimited(tc.ctx, "app", func() error {
+				calls++
+				return tc.errs[calls-1]
+			})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("expected %d calls, got %d", tc.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Errorf("retry %d: expected %s, got %s", i+1, want, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("jittered backoff out of range: %s", got)
+		}
+	}
+}
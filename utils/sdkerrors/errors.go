@@ -0,0 +1,393 @@
+// Package sdkerrors provides the typed errors returned by the WaaS and MPC clients.
+//
+// Transport failures with a non-200 status are reported as *HTTPStatusError and
+// business failures (a non-zero response code) as *APIError. Both support
+// errors.Is against the sentinel categories below, so callers can branch on
+// the kind of failure without parsing error strings:
+//
+//	if errors.Is(err, sdkerrors.ErrInsufficientBalance) {
+//		// top up and retry later
+//	}
+package sdkerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sentinel error categories matched with errors.Is.
+var (
+	// ErrInsufficientBalance reports that the wallet cannot cover the amount and fee.
+	ErrInsufficientBalance = errors.New("insufficient balance")
+
+	// ErrDuplicateRequestID reports that the request_id was already used.
+	ErrDuplicateRequestID = errors.New("duplicate request id")
+
+	// ErrInvalidAddress reports a malformed or unsupported address.
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrRateLimited reports that the server throttled the request.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrCircuitOpen reports that the circuit breaker rejected the request
+	// because the host recently kept failing.
+	ErrCircuitOpen = errors.New("circuit open")
+
+	// ErrUnauthenticResponse reports a success response that was not
+	// encrypted with the ChainUp private key, e.g. one injected by a proxy.
+	ErrUnauthenticResponse = errors.New("unauthentic response")
+)
+
+// HTTPStatusError is returned when the server answers with a non-200 status.
+type HTTPStatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// Error implements the error interface.
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// Is reports whether the status maps to target, e.g. HTTP 429 to ErrRateLimited.
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrRateLimited && e.StatusCode == http.StatusTooManyRequests
+}
+
+// RateLimitError is returned when the client-side rate limiter rejects a call
+// because the context does not allow waiting for a token.
+type RateLimitError struct {
+	AppID string
+	Group string
+	// Wait is how long the call would have had to wait for a token.
+	Wait time.Duration
+}
+
+// Error implements the error interface.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited: %s calls of app %s need to wait %s", e.Group, e.AppID, e.Wait)
+}
+
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// CircuitOpenError is returned without sending the request when the circuit of
+// the host and endpoint group is open.
+type CircuitOpenError struct {
+	Host  string
+	Group string
+	// RetryAfter is how long the circuit stays open, or zero when it is
+	// half-open and already probing.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	msg := fmt.Sprintf("circuit open: %s calls to %s", e.Group, e.Host)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" rejected for %s", e.RetryAfter)
+	}
+	return msg
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// Reasons reported by AuthenticityError.
+const (
+	// ReasonDecryptFailed means the data field could not be decrypted with
+	// the ChainUp public key.
+	ReasonDecryptFailed = "decrypt_failed"
+
+	// ReasonNotEncrypted means the data field is missing or not a cipher string.
+	ReasonNotEncrypted = "not_encrypted"
+)
+
+// AuthenticityError is returned in strict mode when a success response was
+// not decrypted with the ChainUp public key, so its content cannot be trusted.
+type AuthenticityError struct {
+	Endpoint string
+	// Reason is ReasonDecryptFailed or ReasonNotEncrypted.
+	Reason string
+	// Err is the decryption error, if any.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *AuthenticityError) Error() string {
+	msg := fmt.Sprintf("unauthentic response: %s", e.Reason)
+	if e.Endpoint != "" {
+		msg += " (endpoint " + e.Endpoint + ")"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the decryption error.
+func (e *AuthenticityError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrUnauthenticResponse.
+func (e *AuthenticityError) Is(target error) bool {
+	return target == ErrUnauthenticResponse
+}
+
+// APIError is returned when the server answers with a non-zero response code.
+// WaaS returns numeric codes and MPC returns string codes; RawCode always holds
+// the code as sent, and Code holds its numeric value or -1 when it is not numeric.
+type APIError struct {
+	Code      int
+	RawCode   string
+	Message   string
+	Endpoint  string
+	RequestID string
+}
+
+// NewAPIError creates an APIError from a response code of any JSON type.
+func NewAPIError(code interface{}, message string) *APIError {
+	codeInt, raw := ParseCode(code)
+	return &APIError{
+		Code:    codeInt,
+		RawCode: raw,
+		Message: message,
+	}
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	code := e.RawCode
+	if code == "" {
+		code = strconv.Itoa(e.Code)
+	}
+
+	msg := fmt.Sprintf("API Error [%s]: %s", code, e.Message)
+	if e.Endpoint != "" {
+		msg += " (endpoint " + e.Endpoint
+		if e.RequestID != "" {
+			msg += ", request_id " + e.RequestID
+		}
+		msg += ")"
+	}
+	return msg
+}
+
+// Category returns the sentinel category of the error, or nil when unknown.
+func (e *APIError) Category() error {
+	return Classify(e.RawCode, e.Message)
+}
+
+// Is reports whether target is the category of the error.
+func (e *APIError) Is(target error) bool {
+	category := e.Category()
+	return category != nil && category == target
+}
+
+// FromResponse returns an *APIError when response carries a non-zero code,
+// or nil when the call succeeded. Responses without a code are successful.
+func FromResponse(response map[string]interface{}) *APIError {
+	code, ok := response["code"]
+	if !ok {
+		return nil
+	}
+	return FromCode(code, response["msg"])
+}
+
+// FromCode returns an *APIError when code is non-zero, or nil otherwise.
+// A nil code means the response carried none and is treated as success.
+func FromCode(code, msg interface{}) *APIError {
+	if code == nil {
+		return nil
+	}
+
+	codeInt, raw := ParseCode(code)
+	if codeInt == 0 {
+		return nil
+	}
+
+	message := "Unknown error"
+	if msg != nil {
+		message = fmt.Sprintf("%v", msg)
+	}
+	return &APIError{Code: codeInt, RawCode: raw, Message: message}
+}
+
+// ParseCode returns the numeric value of a response code (-1 when it is not
+// numeric) along with its string form.
+func ParseCode(code interface{}) (int, string) {
+	switch v := code.(type) {
+	case int:
+		return v, strconv.Itoa(v)
+	case int64:
+		return int(v), strconv.FormatInt(v, 10)
+	case float64:
+		return int(v), strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return parseCodeString(v.String())
+	case string:
+		return parseCodeString(v)
+	case nil:
+		return -1, ""
+	default:
+		return -1, fmt.Sprintf("%v", v)
+	}
+}
+
+func parseCodeString(s string) (int, string) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return -1, s
+	}
+	return n, s
+}
+
+// Response codes of the ChainUp API that map to a sentinel category. They are
+// registered by default, so errors carrying them match errors.Is without any
+// setup; RegisterCode adds further codes.
+const (
+	CodeRateLimited         = "110023"
+	CodeInsufficientBalance = "110055"
+	CodeInvalidAddress      = "110065"
+	CodeDuplicateRequestID  = "110078"
+)
+
+var (
+	codeCategoriesMu sync.RWMutex
+	codeCategories   = map[string]error{
+		CodeRateLimited:         ErrRateLimited,
+		CodeInsufficientBalance: ErrInsufficientBalance,
+		CodeInvalidAddress:      ErrInvalidAddress,
+		CodeDuplicateRequestID:  ErrDuplicateRequestID,
+	}
+)
+
+// RegisterCode maps a server response code to a sentinel category, replacing
+// the category of a default code. Codes take precedence over the message
+// keywords used by Classify, and are the only way an APIError classifies as
+// ErrRateLimited.
+func RegisterCode(code string, category error) {
+	codeCategoriesMu.Lock()
+	defer codeCategoriesMu.Unlock()
+	codeCategories[code] = category
+}
+
+// categoryKeywords maps lower-cased message fragments to categories for codes
+// that are not registered. Every fragment of an entry must be present for it
+// to match, so loose words such as "insufficient" or "error" are never enough
+// on their own. ErrRateLimited has no keywords: it throttles every call of the
+// app, so a message like "too many ids" must not trigger it.
+var categoryKeywords = []struct {
+	category  error
+	fragments [][]string
+}{
+	{ErrInsufficientBalance, [][]string{{"insufficient", "balance"}, {"balance", "not enough"}, {"余额不足"}}},
+	{ErrDuplicateRequestID, [][]string{{"request_id", "exist"}, {"request_id", "duplicate"}, {"request_id", "repeat"}, {"duplicate request"}, {"请求", "重复"}}},
+	{ErrInvalidAddress, [][]string{{"address", "invalid"}, {"address", "illegal"}, {"地址", "非法"}}},
+}
+
+// Classify returns the sentinel category for a response code and message,
+// or nil when it is not recognized. Registered codes are matched first; the
+// message keywords only apply to the other codes.
+func Classify(code, message string) error {
+	codeCategoriesMu.RLock()
+	category, ok := codeCategories[code]
+	codeCategoriesMu.RUnlock()
+	if ok {
+		return category
+	}
+
+	msg := strings.ToLower(message)
+	for _, entry := range categoryKeywords {
+		for _, fragments := range entry.fragments {
+			if containsAll(msg, fragments) {
+				return entry.category
+			}
+		}
+	}
+	return nil
+}
+
+// Error classes returned by Class.
+const (
+	ClassNone                = "none"
+	ClassCanceled            = "canceled"
+	ClassTimeout             = "timeout"
+	ClassCircuitOpen         = "circuit_open"
+	ClassUnauthentic         = "unauthentic_response"
+	ClassClientRateLimited   = "client_rate_limited"
+	ClassRateLimited         = "rate_limited"
+	ClassInsufficientBalance = "insufficient_balance"
+	ClassDuplicateRequestID  = "duplicate_request_id"
+	ClassInvalidAddress      = "invalid_address"
+	ClassAPI                 = "api"
+	ClassHTTP4xx             = "http_4xx"
+	ClassHTTP5xx             = "http_5xx"
+	ClassOther               = "other"
+)
+
+// Class returns a short, low-cardinality name for the kind of err, suitable as
+// a metric label, e.g. "timeout", "http_5xx" or "insufficient_balance".
+func Class(err error) string {
+	var (
+		apiErr    *APIError
+		statusErr *HTTPStatusError
+		limitErr  *RateLimitError
+	)
+	switch {
+	case err == nil:
+		return ClassNone
+	case errors.Is(err, context.Canceled):
+		return ClassCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ClassTimeout
+	case errors.Is(err, ErrCircuitOpen):
+		return ClassCircuitOpen
+	case errors.Is(err, ErrUnauthenticResponse):
+		return ClassUnauthentic
+	case errors.As(err, &limitErr):
+		return ClassClientRateLimited
+	case errors.Is(err, ErrRateLimited):
+		return ClassRateLimited
+	case errors.As(err, &apiErr):
+		switch apiErr.Category() {
+		case ErrInsufficientBalance:
+			return ClassInsufficientBalance
+		case ErrDuplicateRequestID:
+			return ClassDuplicateRequestID
+		case ErrInvalidAddress:
+			return ClassInvalidAddress
+		default:
+			return ClassAPI
+		}
+	case errors.As(err, &statusErr):
+		if statusErr.StatusCode >= http.StatusInternalServerError {
+			return ClassHTTP5xx
+		}
+		return ClassHTTP4xx
+	default:
+		return ClassOther
+	}
+}
+
+func containsAll(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if !strings.Contains(s, fragment) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+// Package sdkerrors provides the typed errors returned by the WaaS and MPC clients.
+package sdkerrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFromResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response map[string]interface{}
+		wantCode int
+		wantRaw  string
+		wantNil  bool
+	}{
+		{name: "no code", response: map[string]interface{}{"data": "x"}, wantNil: true},
+		{name: "numeric success", response: map[string]interface{}{"code": float64(0)}, wantNil: true},
+		{name: "string success", response: map[string]interface{}{"code": "0"}, wantNil: true},
+		{name: "json number success", response: map[string]interface{}{"code": json.Number("0")}, wantNil: true},
+		{name: "numeric code", response: map[string]interface{}{"code": float64(100002), "msg": "m"}, wantCode: 100002, wantRaw: "100002"},
+		{name: "string code", response: map[string]interface{}{"code": "3040006", "msg": "m"}, wantCode: 3040006, wantRaw: "3040006"},
+		{name: "non-numeric code", response: map[string]interface{}{"code": "E_FAIL", "msg": "m"}, wantCode: -1, wantRaw: "E_FAIL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := FromResponse(tt.response)
+			if tt.wantNil {
+				if apiErr != nil {
+					t.Fatalf("FromResponse() = %v, want nil", apiErr)
+				}
+				return
+			}
+			if apiErr == nil {
+				t.Fatalf("FromResponse() = nil, want error")
+			}
+			if apiErr.Code != tt.wantCode || apiErr.RawCode != tt.wantRaw {
+				t.Fatalf("FromResponse() code = %d/%q, want %d/%q", apiErr.Code, apiErr.RawCode, tt.wantCode, tt.wantRaw)
+			}
+		})
+	}
+}
+
+func TestAPIErrorCategories(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want error
+	}{
+		{"Insufficient balance", ErrInsufficientBalance},
+		{"余额不足", ErrInsufficientBalance},
+		{"request_id already exists", ErrDuplicateRequestID},
+		{"address is invalid", ErrInvalidAddress},
+		{"system error", nil},
+		{"insufficient permission", nil},
+		{"get address error", nil},
+		{"Too many requests", nil},
+		{"too many ids", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			err := fmt.Errorf("withdraw: %w", NewAPIError("1", tt.msg))
+			if got := Classify("1", tt.msg); got != tt.want {
+				t.Fatalf("Classify() = %v, want %v", got, tt.want)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.Message != tt.msg {
+				t.Fatalf("errors.As() did not return the APIError")
+			}
+		})
+	}
+}
+
+func TestDefaultCodes(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{CodeRateLimited, ErrRateLimited},
+		{CodeInsufficientBalance, ErrInsufficientBalance},
+		{CodeInvalidAddress, ErrInvalidAddress},
+		{CodeDuplicateRequestID, ErrDuplicateRequestID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if err := NewAPIError(tt.code, "system busy"); !errors.Is(err, tt.want) {
+				t.Fatalf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterCode(t *testing.T) {
+	RegisterCode("999001", ErrDuplicateRequestID)
+	defer func() {
+		codeCategoriesMu.Lock()
+		delete(codeCategories, "999001")
+		codeCategoriesMu.Unlock()
+	}()
+
+	if err := NewAPIError("999001", "whatever"); !errors.Is(err, ErrDuplicateRequestID) {
+		t.Fatalf("registered code was not classified")
+	}
+
+	RegisterCode("999002", ErrRateLimited)
+	defer func() {
+		codeCategoriesMu.Lock()
+		delete(codeCategories, "999002")
+		codeCategoriesMu.Unlock()
+	}()
+	if err := NewAPIError("999002", "insufficient balance"); !errors.Is(err, ErrRateLimited) || errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("registered code should take precedence over the message, got %v", Classify("999002", "insufficient balance"))
+	}
+}
+
+func TestHTTPStatusError(t *testing.T) {
+	var err error = &HTTPStatusError{StatusCode: http.StatusTooManyRequests, Body: "slow down"}
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("HTTP 429 should match ErrRateLimited")
+	}
+	if err.Error() != "HTTP 429: slow down" {
+		t.Fatalf("Error() = %q", err.Error())
+	}
+
+	err = &HTTPStatusError{StatusCode: http.StatusBadGateway}
+	if errors.Is(err, ErrRateLimited) {
+		t.Fatalf("HTTP 502 should not match ErrRateLimited")
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	err := &APIError{Code: 100002, RawCode: "100002", Message: "bad", Endpoint: "/billing/withdraw", RequestID: "r1"}
+	want := "API Error [100002]: bad (endpoint /billing/withdraw, request_id r1)"
+	if err.Error() != want {
+		t.Fatalf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ClassNone},
+		{fmt.Errorf("HTTP request failed: %w", context.DeadlineExceeded), ClassTimeout},
+		{context.Canceled, ClassCanceled},
+		{&CircuitOpenError{Host: "h"}, ClassCircuitOpen},
+		{&AuthenticityError{Reason: ReasonNotEncrypted}, ClassUnauthentic},
+		{&RateLimitError{AppID: "a"}, ClassClientRateLimited},
+		{&HTTPStatusError{StatusCode: http.StatusTooManyRequests}, ClassRateLimited},
+		{&HTTPStatusError{StatusCode: http.StatusBadGateway}, ClassHTTP5xx},
+		{&HTTPStatusError{StatusCode: http.StatusNotFound}, ClassHTTP4xx},
+		{NewAPIError("1", "Insufficient balance"), ClassInsufficientBalance},
+		{NewAPIError("1", "system error"), ClassAPI},
+		{errors.New("boom"), ClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Class(tt.err); got != tt.want {
+				t.Fatalf("Class(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultSyncInterval is the pause between two polls of a feed.
+const DefaultSyncInterval = 10 * time.Second
+
+// Defaults of how often a Syncer saves checkpoints while a poll is running.
+const (
+	// DefaultCheckpointEvery is the number of handled records after which the
+	// checkpoint is saved, one full page of the sync endpoints.
+	DefaultCheckpointEvery = 100
+
+	// DefaultCheckpointInterval is the longest time a handled record stays
+	// unsaved while a poll is running.
+	DefaultCheckpointInterval = 5 * time.Second
+)
+
+// ErrSyncerClosed is returned by Syncer.Run after Shutdown.
+var ErrSyncerClosed = errors.New("syncer closed")
+
+// Feed is one max_id sync endpoint polled by a Syncer, such as the deposits
+// of a WaaS app. Create feeds with NewFeed or the New*Feed helpers of the
+// custody/api and mpc/api packages.
+type Feed interface {
+	// Name identifies the feed and its checkpoint, e.g. "waas.deposits".
+	Name() string
+
+	// Poll hands every record after maxID to the feed's handler, in ID order,
+	// and calls commit with the record's ID once the handler succeeded. It
+	// stops at the first handler, fetch or commit error.
+	Poll(ctx context.Context, maxID int64, commit func(maxID int64) error) error
+}
+
+// NewFeed creates a Feed named name that fetches pages with fetch, reads the
+// ID of each record with id and handles records with handle. opts tune the
+// underlying Cursor, e.g. WithPageSizeHint.
+func NewFeed[T any](name string, fetch PageFunc[T], id func(T) int64, handle func(context.Context, T) error, opts ...CursorOption) Feed {
+	return &feed[T]{name: name, fetch: fetch, id: id, handle: handle, opts: opts}
+}
+
+type feed[T any] struct {
+	name   string
+	fetch  PageFunc[T]
+	id     func(T) int64
+	handle func(context.Context, T) error
+	opts   []CursorOption
+}
+
+func (f *feed[T]) Name() string {
+	return f.name
+}
+
+func (f *feed[T]) Poll(ctx context.Context, maxID int64, commit func(maxID int64) error) error {
+	opts := append(append(make([]CursorOption, 0, len(f.opts)+1), f.opts...), WithStartID(maxID))
+	cursor := NewCursor(ctx, f.fetch, f.id, opts...)
+	for cursor.Next() {
+		if err := f.handle(ctx, cursor.Item()); err != nil {
+			return fmt.Errorf("handle record %d: %w", cursor.MaxID(), err)
+		}
+		if err := commit(cursor.MaxID()); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// SyncSettings configures a Syncer.
+type SyncSettings struct {
+	// Interval is the pause between two polls of a feed once it caught up or
+	// failed (default: DefaultSyncInterval).
+	Interval time.Duration
+
+	// Jitter is the fraction of Interval that is randomized, between 0 and 1,
+	// so that feeds and processes do not poll in lockstep (default: 0).
+	Jitter float64
+
+	// CheckpointEvery is the number of handled records after which the
+	// checkpoint is saved (default: DefaultCheckpointEvery).
+	CheckpointEvery int
+
+	// CheckpointInterval is the longest time a handled record stays unsaved
+	// while a poll is running (default: DefaultCheckpointInterval).
+	CheckpointInterval time.Duration
+}
+
+// Syncer polls a set of feeds in the background, one goroutine per feed. A
+// record's ID is saved to the CheckpointStore only after its handler
+// succeeded, so a restarted Syncer resumes after the last handled record and
+// a failed record is retried on the next poll. Handlers therefore see every
+// record at least once and must tolerate repeats after a crash.
+//
+// Checkpoints are saved in batches, every CheckpointEvery records or
+// CheckpointInterval and when a poll ends, so a crash repeats at most one
+// batch of records.
+//
+//	syncer := utils.NewSyncer(utils.NewFileCheckpointStore("checkpoints.json"), utils.SyncSettings{Interval: 5 * time.Second, Jitter: 0.2}).
+//		AddFeed(api.NewDepositFeed(client.GetBillingAPI(), handleDeposit)).
+//		OnError(func(feed string, err error) { log.Printf("%s: %v", feed, err) })
+//	go syncer.Run(ctx)
+//	// ...
+//	err := syncer.Shutdown(shutdownCtx)
+type Syncer struct {
+	store    CheckpointStore
+	settings SyncSettings
+	feeds    []Feed
+	onError  func(feed string, err error)
+
+	mu       sync.Mutex
+	stopping chan struct{}
+	running  sync.WaitGroup
+	cancel   context.CancelFunc
+	closed   bool
+}
+
+// NewSyncer creates a Syncer saving checkpoints to store.
+func NewSyncer(store CheckpointStore, settings SyncSettings) *Syncer {
+	if settings.Interval <= 0 {
+		settings.Interval = DefaultSyncInterval
+	}
+	if settings.CheckpointEvery <= 0 {
+		settings.CheckpointEvery = DefaultCheckpointEvery
+	}
+	if settings.CheckpointInterval <= 0 {
+		settings.CheckpointInterval = DefaultCheckpointInterval
+	}
+	return &Syncer{store: store, settings: settings, stopping: make(chan struct{})}
+}
+
+// AddFeed adds feeds to poll. Feed names must be unique within a store.
+func (s *Syncer) AddFeed(feeds ...Feed) *Syncer {
+	s.feeds = append(s.feeds, feeds...)
+	return s
+}
+
+// OnError sets the callback receiving every poll and checkpoint error. The
+// failed feed retries after the next interval either way.
+func (s *Syncer) OnError(fn func(feed string, err error)) *Syncer {
+	s.onError = fn
+	return s
+}
+
+// Sync polls every feed once, until each one caught up or failed, and
+// returns the joined errors.
+func (s *Syncer) Sync(ctx context.Context) error {
+	var errs []error
+	for _, f := range s.feeds {
+		if err := s.poll(ctx, f); err != nil && !errors.Is(err, ErrSyncerClosed) {
+			errs = append(errs, fmt.Errorf("feed %s: %w", f.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run polls every feed until ctx is canceled or Shutdown is called, and
+// returns ctx.Err() or ErrSyncerClosed respectively. Canceling ctx aborts the
+// records being handled; Shutdown lets them finish.
+func (s *Syncer) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrSyncerClosed
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.running.Add(len(s.feeds))
+	s.mu.Unlock()
+	defer cancel()
+
+	for _, f := range s.feeds {
+		go func(f Feed) {
+			defer s.running.Done()
+			s.loop(ctx, f)
+		}(f)
+	}
+	s.running.Wait()
+
+	if err := ctx.Err(); err != nil && !s.isClosed() {
+		return err
+	}
+	return ErrSyncerClosed
+}
+
+// Shutdown stops polling and waits until the records being handled are done
+// and checkpointed. When ctx expires first, the handlers' context is canceled
+// and ctx.Err() is returned.
+func (s *Syncer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stopping)
+	}
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if cancel != nil {
+			cancel()
+		}
+		return ctx.Err()
+	}
+}
+
+func (s *Syncer) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// loop polls f until ctx is canceled or the syncer is shut down.
+func (s *Syncer) loop(ctx context.Context, f Feed) {
+	for {
+		if err := s.poll(ctx, f); err != nil && !errors.Is(err, ErrSyncerClosed) && ctx.Err() == nil {
+			s.reportError(f.Name(), err)
+		}
+
+		timer := time.NewTimer(jitterInterval(s.settings.Interval, s.settings.Jitter))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.stopping:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// poll runs one poll of f from its checkpoint. It returns ErrSyncerClosed
+// when the syncer was shut down after a record was committed.
+func (s *Syncer) poll(ctx context.Context, f Feed) error {
+	maxID, err := s.store.Load(ctx, f.Name())
+	if err != nil {
+		return fmt.Errorf("load checkpoint: %w", err)
+	}
+	checkpoint := newCheckpointer(s.store, f.Name(), s.settings.CheckpointEvery, s.settings.CheckpointInterval)
+	err = f.Poll(ctx, maxID, func(maxID int64) error {
+		if err := checkpoint.commit(ctx, maxID); err != nil {
+			return err
+		}
+		select {
+		case <-s.stopping:
+			return ErrSyncerClosed
+		default:
+			return nil
+		}
+	})
+	// Save the records handled since the last save, even when ctx was canceled.
+	if flushErr := checkpoint.flush(context.WithoutCancel(ctx)); flushErr != nil {
+		return errors.Join(err, flushErr)
+	}
+	return err
+}
+
+// checkpointer saves the checkpoint of one feed in batches.
+type checkpointer struct {
+	store    CheckpointStore
+	feed     string
+	every    int
+	interval time.Duration
+
+	maxID   int64
+	pending int
+	saved   time.Time
+}
+
+func newCheckpointer(store CheckpointStore, feed string, every int, interval time.Duration) *checkpointer {
+	return &checkpointer{store: store, feed: feed, every: every, interval: interval, saved: time.Now()}
+}
+
+// commit records that every record up to maxID was handled and saves the
+// checkpoint when a batch is complete.
+func (c *checkpointer) commit(ctx context.Context, maxID int64) error {
+	c.maxID = maxID
+	c.pending++
+	if c.pending < c.every && time.Since(c.saved) < c.interval {
+		return nil
+	}
+	return c.flush(ctx)
+}
+
+// flush saves the last committed ID unless it was saved already.
+func (c *checkpointer) flush(ctx context.Context) error {
+	if c.pending == 0 {
+		return nil
+	}
+	if err := c.store.Save(ctx, c.feed, c.maxID); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	c.pending = 0
+	c.saved = time.Now()
+	return nil
+}
+
+func (s *Syncer) reportError(feed string, err error) {
+	if s.onError != nil {
+		s.onError(feed, err)
+	}
+}
+
+// jitterInterval returns interval randomized by the fraction jitter.
+func jitterInterval(interval time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	jitter = math.Min(jitter, 1)
+	return time.Duration(float64(interval) * (1 - jitter + 2*jitter*rand.Float64()))
+}
@@ -0,0 +1,186 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// idFeed creates a feed over ids that records the handled IDs and fails the
+// records listed in failing.
+func idFeed(name string, ids []int64, handled *[]int64, failing map[int64]bool) Feed {
+	fetch := func(ctx context.Context, maxID int64) ([]int64, error) {
+		var page []int64
+		for _, id := range ids {
+			if id > maxID && len(page) < 2 {
+				page = append(page, id)
+			}
+		}
+		return page, nil
+	}
+	handle := func(ctx context.Context, id int64) error {
+		if failing[id] {
+			return errors.New("handler failed")
+		}
+		*handled = append(*handled, id)
+		return nil
+	}
+	return NewFeed(name, fetch, func(id int64) int64 { return id }, handle)
+}
+
+func TestSyncerSync(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+	failing := map[int64]bool{4: true}
+	var handled []int64
+	syncer := NewSyncer(store, SyncSettings{}).AddFeed(idFeed("ids", []int64{1, 2, 4, 7}, &handled, failing))
+
+	tests := []struct {
+		name        string
+		fix         bool
+		wantErr     bool
+		wantHandled []int64
+		wantMaxID   int64
+	}{
+		{name: "handler fails", wantErr: true, wantHandled: []int64{1, 2}, wantMaxID: 2},
+		{name: "retried from checkpoint", fix: true, wantHandled: []int64{1, 2, 4, 7}, wantMaxID: 7},
+		{name: "caught up", wantHandled: []int64{1, 2, 4, 7}, wantMaxID: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fix {
+				delete(failing, 4)
+			}
+			if err := syncer.Sync(ctx); (err != nil) != tt.wantErr {
+				t.Fatalf("Sync() error = %v, want error %v", err, tt.wantErr)
+			}
+			maxID, _ := store.Load(ctx, "ids")
+			if !reflect.DeepEqual(handled, tt.wantHandled) || maxID != tt.wantMaxID {
+				t.Fatalf("handled = %v, checkpoint = %d, want %v, %d", handled, maxID, tt.wantHandled, tt.wantMaxID)
+			}
+		})
+	}
+}
+
+func TestSyncerShutdown(t *testing.T) {
+	store := NewMemoryCheckpointStore()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	fetch := func(ctx context.Context, maxID int64) ([]int64, error) {
+		return []int64{maxID + 1}, nil
+	}
+	handle := func(ctx context.Context, id int64) error {
+		once.Do(func() { close(started) })
+		<-release
+		return ctx.Err()
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	syncer := NewSyncer(store, SyncSettings{Interval: time.Millisecond, Jitter: 0.5}).
+		AddFeed(NewFeed("endless", fetch, func(id int64) int64 { return id }, handle)).
+		OnError(func(feed string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		})
+
+	done := make(chan error, 1)
+	go func() { done <- syncer.Run(context.Background()) }()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- syncer.Shutdown(context.Background()) }()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-done; !errors.Is(err, ErrSyncerClosed) {
+		t.Fatalf("Run() error = %v, want ErrSyncerClosed", err)
+	}
+	if maxID, _ := store.Load(context.Background(), "endless"); maxID != 1 {
+		t.Fatalf("checkpoint = %d, want the in-flight record 1 committed", maxID)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("errors = %v", errs)
+	}
+	if err := syncer.Run(context.Background()); !errors.Is(err, ErrSyncerClosed) {
+		t.Fatalf("Run() after Shutdown = %v", err)
+	}
+}
+
+func TestSyncerRunCanceled(t *testing.T) {
+	var handled []int64
+	syncer := NewSyncer(NewMemoryCheckpointStore(), SyncSettings{Interval: time.Hour}).
+		AddFeed(idFeed("ids", []int64{1}, &handled, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- syncer.Run(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if len(handled) != 1 {
+		t.Fatalf("handled = %v", handled)
+	}
+}
+
+// countingStore records every saved checkpoint.
+type countingStore struct {
+	*MemoryCheckpointStore
+	saves []int64
+}
+
+func (s *countingStore) Save(ctx context.Context, feed string, maxID int64) error {
+	s.saves = append(s.saves, maxID)
+	return s.MemoryCheckpointStore.Save(ctx, feed, maxID)
+}
+
+func TestSyncerCheckpointBatches(t *testing.T) {
+	ids := []int64{1, 2, 3, 4, 5, 6, 7}
+	tests := []struct {
+		name      string
+		settings  SyncSettings
+		failing   map[int64]bool
+		wantSaves []int64
+	}{
+		{name: "every 3 records", settings: SyncSettings{CheckpointEvery: 3}, wantSaves: []int64{3, 6, 7}},
+		{name: "handler fails", settings: SyncSettings{CheckpointEvery: 3}, failing: map[int64]bool{5: true}, wantSaves: []int64{3, 4}},
+		{name: "interval elapsed", settings: SyncSettings{CheckpointEvery: 100, CheckpointInterval: time.Nanosecond}, wantSaves: ids},
+		{name: "defaults", wantSaves: []int64{7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &countingStore{MemoryCheckpointStore: NewMemoryCheckpointStore()}
+			var handled []int64
+			_ = NewSyncer(store, tt.settings).AddFeed(idFeed("ids", ids, &handled, tt.failing)).Sync(context.Background())
+			if !reflect.DeepEqual(store.saves, tt.wantSaves) {
+				t.Fatalf("saves = %v, want %v", store.saves, tt.wantSaves)
+			}
+		})
+	}
+}
+
+func TestFeedKeepsCallerOptions(t *testing.T) {
+	opts := make([]CursorOption, 1, 2)
+	opts[0] = WithPageSizeHint(2)
+	fetch := func(ctx context.Context, maxID int64) ([]int64, error) { return nil, nil }
+	feed := NewFeed("ids", fetch, func(id int64) int64 { return id }, func(ctx context.Context, id int64) error { return nil }, opts...)
+
+	if err := feed.Poll(context.Background(), 5, func(int64) error { return nil }); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if opts[:2][1] != nil {
+		t.Fatalf("Poll() wrote into the backing array of the caller's options")
+	}
+}
@@ -0,0 +1,213 @@
+// Package utils provides utility functions and constants for ChainUp Custody SDK.
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Span names of the child spans opened inside every API call span.
+const (
+	SpanEncrypt = "encrypt"
+	SpanHTTP    = "http"
+	SpanDecrypt = "decrypt"
+	SpanDecode  = "decode"
+)
+
+// Tracer opens spans around API calls. It mirrors the shape of an
+// OpenTelemetry tracer so that adapters stay thin: Start must return a context
+// carrying the new span, so that spans started from it become its children.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one timed operation of a trace.
+type Span interface {
+	// SetAttribute attaches a key/value pair to the span.
+	SetAttribute(key string, value interface{})
+
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+
+	// End finishes the span.
+	End()
+}
+
+// spanKey is the context key for the current SDK span.
+type spanKey struct{}
+
+// noopSpan discards everything.
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+// StartSpan starts a span named name with tracer as a child of the span in ctx.
+// A nil tracer returns ctx unchanged and a span that does nothing.
+func StartSpan(ctx context.Context, tracer Tracer, name string) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := tracer.Start(ctx, name)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the span started by StartSpan that ctx carries, or a
+// span that does nothing.
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// SpanAttributeKeys are the request params copied onto API call spans when present.
+var SpanAttributeKeys = []string{"request_id", "sub_wallet_id", "symbol"}
+
+// StartCallSpan starts the span of one logical API call, e.g.
+// "mpc.WithdrawAPI.Withdraw", with the path, app_id and SpanAttributeKeys
+// params as attributes.
+func StartCallSpan(ctx context.Context, tracer Tracer, name, path, appID string, params map[string]interface{}) (context.Context, Span) {
+	ctx, span := StartSpan(ctx, tracer, name)
+	if tracer == nil {
+		return ctx, span
+	}
+	span.SetAttribute("path", path)
+	span.SetAttribute("app_id", appID)
+	for _, key := range SpanAttributeKeys {
+		if value := StringField(params, key); value != "" {
+			span.SetAttribute(key, value)
+		}
+	}
+	return ctx, span
+}
+
+// EndSpan records err on span, if any, and ends it.
+func EndSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// RecordedSpan is a span captured by a TraceRecorder.
+type RecordedSpan struct {
+	ID         int
+	ParentID   int
+	Name       string
+	Attributes map[string]interface{}
+	Err        error
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// Duration returns how long the span took.
+func (s RecordedSpan) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// TraceRecorder is an in-memory Tracer for tests. It keeps every span with its
+// parent, attributes and timings. It is safe for concurrent use.
+type TraceRecorder struct {
+	mu     sync.Mutex
+	spans  []*recordedSpan
+	nextID int
+}
+
+// recordedSpan is the Span handed out by TraceRecorder.
+type recordedSpan struct {
+	recorder *TraceRecorder
+	data     RecordedSpan
+	ended    bool
+}
+
+// recorderSpanKey is the context key for the current TraceRecorder span.
+type recorderSpanKey struct{}
+
+// NewTraceRecorder creates an empty TraceRecorder.
+func NewTraceRecorder() *TraceRecorder {
+	return &TraceRecorder{}
+}
+
+// Start implements Tracer.
+func (r *TraceRecorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	span := &recordedSpan{
+		recorder: r,
+		data: RecordedSpan{
+			ID:         r.nextID,
+			Name:       name,
+			Attributes: make(map[string]interface{}),
+			StartTime:  time.Now(),
+		},
+	}
+	if parent, ok := ctx.Value(recorderSpanKey{}).(*recordedSpan); ok && parent.recorder == r {
+		span.data.ParentID = parent.data.ID
+	}
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, recorderSpanKey{}, span), span
+}
+
+// Spans returns the ended spans in the order they were started.
+func (r *TraceRecorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(r.spans))
+	for _, span := range r.spans {
+		if !span.ended {
+			continue
+		}
+		data := span.data
+		data.Attributes = make(map[string]interface{}, len(span.data.Attributes))
+		for key, value := range span.data.Attributes {
+			data.Attributes[key] = value
+		}
+		spans = append(spans, data)
+	}
+	return spans
+}
+
+// Children returns the ended spans whose parent is the span with the given ID.
+func (r *TraceRecorder) Children(parentID int) []RecordedSpan {
+	var children []RecordedSpan
+	for _, span := range r.Spans() {
+		if span.ParentID == parentID {
+			children = append(children, span)
+		}
+	}
+	return children
+}
+
+// Reset discards every recorded span.
+func (r *TraceRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.data.Err = err
+}
+
+func (s *recordedSpan) End() {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	if !s.ended {
+		s.ended = true
+		s.data.EndTime = time.Now()
+	}
+}